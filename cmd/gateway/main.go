@@ -53,6 +53,16 @@ func main() {
 		Namespace:      cfg.Namespace,
 		WorkerImage:    cfg.WorkerImage,
 		WorkerImageTag: cfg.WorkerImageTag,
+		WorkerEvidence: k8s.WorkerEvidenceConfig{
+			Sink:                 cfg.EvidenceSink,
+			BaseURL:              cfg.EvidenceBaseURL,
+			S3Endpoint:           cfg.EvidenceS3Endpoint,
+			S3Bucket:             cfg.EvidenceS3Bucket,
+			S3Region:             cfg.EvidenceS3Region,
+			AudioClip:            cfg.EvidenceAudioClip,
+			S3AccessKeyIDKey:     cfg.GatewayEvidenceS3AccessKeyIDSecretKey,
+			S3SecretAccessKeyKey: cfg.GatewayEvidenceS3SecretAccessKeySecretKey,
+		},
 	})
 	if err != nil {
 		log.Fatal("failed to create k8s client", zap.Error(err))
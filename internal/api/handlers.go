@@ -6,25 +6,24 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/xpadev-net/youtube-stream-tracker/internal/ffmpeg"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/httpapi"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ids"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/k8s"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/k8s/store"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/log"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/manifest"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/validation"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtubeurl"
 )
 
-var youtubeWatchURLRegex = regexp.MustCompile(`^https?://(www\.)?youtube\.com/watch\?v=[a-zA-Z0-9_-]{11}`)
-
 var validMonitorStatuses = map[model.MonitorStatus]bool{
 	model.StatusInitializing: true,
 	model.StatusWaiting:      true,
@@ -84,17 +83,50 @@ type CreateMonitorRequest struct {
 
 // MonitorConfigRequest represents the config part of the create request.
 type MonitorConfigRequest struct {
-	CheckIntervalSec       *int       `json:"check_interval_sec,omitempty"`
-	BlackoutThresholdSec   *int       `json:"blackout_threshold_sec,omitempty"`
-	SilenceThresholdSec    *int       `json:"silence_threshold_sec,omitempty"`
-	SilenceDBThreshold     *float64   `json:"silence_db_threshold,omitempty"`
-	ScheduledStartTime     *time.Time `json:"scheduled_start_time,omitempty"`
-	StartDelayToleranceSec *int       `json:"start_delay_tolerance_sec,omitempty"`
+	CheckIntervalSec        *int                    `json:"check_interval_sec,omitempty"`
+	BlackoutThresholdSec    *int                    `json:"blackout_threshold_sec,omitempty"`
+	SilenceThresholdSec     *int                    `json:"silence_threshold_sec,omitempty"`
+	SilenceDBThreshold      *float64                `json:"silence_db_threshold,omitempty"`
+	SilenceMinDurationSec   *float64                `json:"silence_min_duration_sec,omitempty"`
+	SilenceRatioThreshold   *float64                `json:"silence_ratio_threshold,omitempty"`
+	BlackMinDurationSec     *float64                `json:"black_min_duration_sec,omitempty"`
+	BlackPixelThreshold     *float64                `json:"black_pixel_threshold,omitempty"`
+	BlackRatioThreshold     *float64                `json:"black_ratio_threshold,omitempty"`
+	FreezeThresholdSec      *int                    `json:"freeze_threshold_sec,omitempty"`
+	FreezeNoiseDB           *float64                `json:"freeze_noise_db,omitempty"`
+	FreezeMinDurationSec    *float64                `json:"freeze_min_duration_sec,omitempty"`
+	FreezeRatioThreshold    *float64                `json:"freeze_ratio_threshold,omitempty"`
+	LoudnessMinLUFS         *float64                `json:"loudness_min_lufs,omitempty"`
+	LoudnessMaxLUFS         *float64                `json:"loudness_max_lufs,omitempty"`
+	LoudnessThresholdSec    *int                    `json:"loudness_threshold_sec,omitempty"`
+	MinVideoHeight          *int                    `json:"min_video_height,omitempty"`
+	MinBitrateKbps          *int                    `json:"min_bitrate_kbps,omitempty"`
+	QualityDegradedSegments *int                    `json:"quality_degraded_segments,omitempty"`
+	CatchupMaxSegments      *int                    `json:"catchup_max_segments,omitempty"`
+	AVDesyncMaxOffsetMs     *int                    `json:"av_desync_max_offset_ms,omitempty"`
+	AVDesyncThresholdSec    *int                    `json:"av_desync_threshold_sec,omitempty"`
+	SlateReferences         *[]model.SlateReference `json:"slate_references,omitempty"`
+	SlateMatchThreshold     *float64                `json:"slate_match_threshold,omitempty"`
+	SlateMaxMotion          *float64                `json:"slate_max_motion,omitempty"`
+	SlateThresholdSec       *int                    `json:"slate_threshold_sec,omitempty"`
+	ToneFrequencyHz         *int                    `json:"tone_frequency_hz,omitempty"`
+	CaptionsRequired        *bool                   `json:"captions_required,omitempty"`
+	CaptionsThresholdSec    *int                    `json:"captions_threshold_sec,omitempty"`
+	LatencyMaxSec           *int                    `json:"latency_max_sec,omitempty"`
+	LatencyThresholdSec     *int                    `json:"latency_threshold_sec,omitempty"`
+	LowLatency              *bool                   `json:"low_latency,omitempty"`
+	CookiesSecret           *string                 `json:"cookies_secret,omitempty"`
+	VariantSelection        *string                 `json:"variant_selection,omitempty"`
+	Variants                *[]string               `json:"variants,omitempty"`
+	ScheduledStartTime      *time.Time              `json:"scheduled_start_time,omitempty"`
+	StartDelayToleranceSec  *int                    `json:"start_delay_tolerance_sec,omitempty"`
 }
 
 // CreateMonitorResponse represents the response for creating a monitor.
 type CreateMonitorResponse struct {
 	MonitorID string `json:"monitor_id"`
+	StreamURL string `json:"stream_url"`
+	VideoID   string `json:"video_id"`
 	Status    string `json:"status"`
 	CreatedAt string `json:"created_at"`
 }
@@ -107,10 +139,11 @@ func (h *Handler) CreateMonitor(c *gin.Context) {
 		return
 	}
 
-	// Validate stream URL
-	if !isValidYouTubeWatchURL(req.StreamURL) {
+	// Validate and normalize stream URL
+	streamURL, videoID, err := youtubeurl.Normalize(req.StreamURL)
+	if err != nil {
 		httpapi.RespondError(c, http.StatusBadRequest, httpapi.ErrCodeInvalidURL,
-			"The provided stream URL is not a valid YouTube watch URL")
+			"The provided stream URL is not a valid YouTube video URL")
 		return
 	}
 
@@ -139,7 +172,7 @@ func (h *Handler) CreateMonitor(c *gin.Context) {
 
 	// Build config
 	config := applyConfigOverrides(model.DefaultMonitorConfig(), req.Config)
-	if err := config.Validate(); err != nil {
+	if err := validateMonitorConfig(config); err != nil {
 		httpapi.RespondError(c, http.StatusBadRequest, httpapi.ErrCodeInvalidConfig, err.Error())
 		return
 	}
@@ -154,7 +187,8 @@ func (h *Handler) CreateMonitor(c *gin.Context) {
 	monitorID := ids.NewMonitorID()
 	monitor, err := h.repo.Create(c.Request.Context(), store.CreateMonitorParams{
 		ID:           monitorID,
-		StreamURL:    req.StreamURL,
+		StreamURL:    streamURL,
+		VideoID:      videoID,
 		CallbackURL:  req.CallbackURL,
 		Config:       config,
 		Metadata:     metadata,
@@ -192,6 +226,8 @@ func (h *Handler) CreateMonitor(c *gin.Context) {
 
 	httpapi.RespondCreated(c, CreateMonitorResponse{
 		MonitorID: monitor.ID,
+		StreamURL: monitor.StreamURL,
+		VideoID:   monitor.VideoID,
 		Status:    string(monitor.Status),
 		CreatedAt: monitor.CreatedAt.Format(time.RFC3339),
 	})
@@ -201,6 +237,7 @@ func (h *Handler) CreateMonitor(c *gin.Context) {
 type GetMonitorResponse struct {
 	MonitorID    string          `json:"monitor_id"`
 	StreamURL    string          `json:"stream_url"`
+	VideoID      string          `json:"video_id,omitempty"`
 	Status       string          `json:"status"`
 	StreamStatus string          `json:"stream_status,omitempty"`
 	Health       *HealthResponse `json:"health,omitempty"`
@@ -217,9 +254,26 @@ type HealthResponse struct {
 
 // StatsResponse represents statistics in the response.
 type StatsResponse struct {
-	TotalSegmentsAnalyzed int `json:"total_segments_analyzed"`
-	BlackoutEvents        int `json:"blackout_events"`
-	SilenceEvents         int `json:"silence_events"`
+	TotalSegmentsAnalyzed int     `json:"total_segments_analyzed"`
+	TotalSegmentsSkipped  int     `json:"total_segments_skipped"`
+	BlackoutEvents        int     `json:"blackout_events"`
+	SilenceEvents         int     `json:"silence_events"`
+	ChannelSilenceEvents  int     `json:"channel_silence_events"`
+	QualityDegradedEvents int     `json:"quality_degraded_events"`
+	FreezeEvents          int     `json:"freeze_events"`
+	LoudnessEvents        int     `json:"loudness_events"`
+	AVDesyncEvents        int     `json:"av_desync_events"`
+	SlateEvents           int     `json:"slate_events"`
+	ToneEvents            int     `json:"tone_events"`
+	CaptionsEvents        int     `json:"captions_events"`
+	LatencyEvents         int     `json:"latency_events"`
+	PlaylistAnomalyEvents int     `json:"playlist_anomaly_events"`
+	VideoWidth            int     `json:"video_width,omitempty"`
+	VideoHeight           int     `json:"video_height,omitempty"`
+	FrameRate             float64 `json:"frame_rate,omitempty"`
+	BitrateKbps           float64 `json:"bitrate_kbps,omitempty"`
+	AVOffsetMs            float64 `json:"av_offset_ms,omitempty"`
+	LatencySec            float64 `json:"latency_sec,omitempty"`
 }
 
 // GetMonitor handles GET /api/v1/monitors/:monitor_id
@@ -244,6 +298,7 @@ func (h *Handler) GetMonitor(c *gin.Context) {
 	resp := GetMonitorResponse{
 		MonitorID: monitorWithStats.ID,
 		StreamURL: monitorWithStats.StreamURL,
+		VideoID:   monitorWithStats.VideoID,
 		Status:    string(monitorWithStats.Status),
 		CreatedAt: monitorWithStats.CreatedAt.Format(time.RFC3339),
 	}
@@ -259,8 +314,25 @@ func (h *Handler) GetMonitor(c *gin.Context) {
 		}
 		resp.Statistics = &StatsResponse{
 			TotalSegmentsAnalyzed: monitorWithStats.Stats.TotalSegments,
+			TotalSegmentsSkipped:  monitorWithStats.Stats.SkippedSegments,
 			BlackoutEvents:        monitorWithStats.Stats.BlackoutEvents,
 			SilenceEvents:         monitorWithStats.Stats.SilenceEvents,
+			ChannelSilenceEvents:  monitorWithStats.Stats.ChannelSilenceEvents,
+			QualityDegradedEvents: monitorWithStats.Stats.QualityDegradedEvents,
+			FreezeEvents:          monitorWithStats.Stats.FreezeEvents,
+			LoudnessEvents:        monitorWithStats.Stats.LoudnessEvents,
+			AVDesyncEvents:        monitorWithStats.Stats.AVDesyncEvents,
+			SlateEvents:           monitorWithStats.Stats.SlateEvents,
+			ToneEvents:            monitorWithStats.Stats.ToneEvents,
+			CaptionsEvents:        monitorWithStats.Stats.CaptionsEvents,
+			LatencyEvents:         monitorWithStats.Stats.LatencyEvents,
+			PlaylistAnomalyEvents: monitorWithStats.Stats.PlaylistAnomalyEvents,
+			VideoWidth:            monitorWithStats.Stats.VideoWidth,
+			VideoHeight:           monitorWithStats.Stats.VideoHeight,
+			FrameRate:             monitorWithStats.Stats.FrameRate,
+			BitrateKbps:           monitorWithStats.Stats.BitrateKbps,
+			AVOffsetMs:            monitorWithStats.Stats.AVOffsetMs,
+			LatencySec:            monitorWithStats.Stats.LatencySec,
 		}
 	}
 
@@ -330,6 +402,7 @@ type ListMonitorsResponse struct {
 type MonitorSummary struct {
 	MonitorID string `json:"monitor_id"`
 	StreamURL string `json:"stream_url"`
+	VideoID   string `json:"video_id,omitempty"`
 	Status    string `json:"status"`
 	CreatedAt string `json:"created_at"`
 }
@@ -382,6 +455,7 @@ func (h *Handler) ListMonitors(c *gin.Context) {
 		summaries[i] = MonitorSummary{
 			MonitorID: m.ID,
 			StreamURL: m.StreamURL,
+			VideoID:   m.VideoID,
 			Status:    string(m.Status),
 			CreatedAt: m.CreatedAt.Format(time.RFC3339),
 		}
@@ -411,9 +485,26 @@ type UpdateStatusRequest struct {
 		Audio string `json:"audio"`
 	} `json:"health,omitempty"`
 	Statistics *struct {
-		TotalSegmentsAnalyzed *int `json:"total_segments_analyzed,omitempty"`
-		BlackoutEvents        *int `json:"blackout_events,omitempty"`
-		SilenceEvents         *int `json:"silence_events,omitempty"`
+		TotalSegmentsAnalyzed *int     `json:"total_segments_analyzed,omitempty"`
+		TotalSegmentsSkipped  *int     `json:"total_segments_skipped,omitempty"`
+		BlackoutEvents        *int     `json:"blackout_events,omitempty"`
+		SilenceEvents         *int     `json:"silence_events,omitempty"`
+		ChannelSilenceEvents  *int     `json:"channel_silence_events,omitempty"`
+		QualityDegradedEvents *int     `json:"quality_degraded_events,omitempty"`
+		FreezeEvents          *int     `json:"freeze_events,omitempty"`
+		LoudnessEvents        *int     `json:"loudness_events,omitempty"`
+		AVDesyncEvents        *int     `json:"av_desync_events,omitempty"`
+		SlateEvents           *int     `json:"slate_events,omitempty"`
+		ToneEvents            *int     `json:"tone_events,omitempty"`
+		CaptionsEvents        *int     `json:"captions_events,omitempty"`
+		LatencyEvents         *int     `json:"latency_events,omitempty"`
+		PlaylistAnomalyEvents *int     `json:"playlist_anomaly_events,omitempty"`
+		VideoWidth            *int     `json:"video_width,omitempty"`
+		VideoHeight           *int     `json:"video_height,omitempty"`
+		FrameRate             *float64 `json:"frame_rate,omitempty"`
+		BitrateKbps           *float64 `json:"bitrate_kbps,omitempty"`
+		AVOffsetMs            *float64 `json:"av_offset_ms,omitempty"`
+		LatencySec            *float64 `json:"latency_sec,omitempty"`
 	} `json:"statistics,omitempty"`
 }
 
@@ -504,12 +595,63 @@ func (h *Handler) UpdateMonitorStatus(c *gin.Context) {
 				if req.Statistics.TotalSegmentsAnalyzed != nil {
 					stats.TotalSegments = *req.Statistics.TotalSegmentsAnalyzed
 				}
+				if req.Statistics.TotalSegmentsSkipped != nil {
+					stats.SkippedSegments = *req.Statistics.TotalSegmentsSkipped
+				}
 				if req.Statistics.BlackoutEvents != nil {
 					stats.BlackoutEvents = *req.Statistics.BlackoutEvents
 				}
 				if req.Statistics.SilenceEvents != nil {
 					stats.SilenceEvents = *req.Statistics.SilenceEvents
 				}
+				if req.Statistics.ChannelSilenceEvents != nil {
+					stats.ChannelSilenceEvents = *req.Statistics.ChannelSilenceEvents
+				}
+				if req.Statistics.QualityDegradedEvents != nil {
+					stats.QualityDegradedEvents = *req.Statistics.QualityDegradedEvents
+				}
+				if req.Statistics.FreezeEvents != nil {
+					stats.FreezeEvents = *req.Statistics.FreezeEvents
+				}
+				if req.Statistics.LoudnessEvents != nil {
+					stats.LoudnessEvents = *req.Statistics.LoudnessEvents
+				}
+				if req.Statistics.AVDesyncEvents != nil {
+					stats.AVDesyncEvents = *req.Statistics.AVDesyncEvents
+				}
+				if req.Statistics.SlateEvents != nil {
+					stats.SlateEvents = *req.Statistics.SlateEvents
+				}
+				if req.Statistics.ToneEvents != nil {
+					stats.ToneEvents = *req.Statistics.ToneEvents
+				}
+				if req.Statistics.CaptionsEvents != nil {
+					stats.CaptionsEvents = *req.Statistics.CaptionsEvents
+				}
+				if req.Statistics.LatencyEvents != nil {
+					stats.LatencyEvents = *req.Statistics.LatencyEvents
+				}
+				if req.Statistics.PlaylistAnomalyEvents != nil {
+					stats.PlaylistAnomalyEvents = *req.Statistics.PlaylistAnomalyEvents
+				}
+				if req.Statistics.VideoWidth != nil {
+					stats.VideoWidth = *req.Statistics.VideoWidth
+				}
+				if req.Statistics.VideoHeight != nil {
+					stats.VideoHeight = *req.Statistics.VideoHeight
+				}
+				if req.Statistics.FrameRate != nil {
+					stats.FrameRate = *req.Statistics.FrameRate
+				}
+				if req.Statistics.BitrateKbps != nil {
+					stats.BitrateKbps = *req.Statistics.BitrateKbps
+				}
+				if req.Statistics.AVOffsetMs != nil {
+					stats.AVOffsetMs = *req.Statistics.AVOffsetMs
+				}
+				if req.Statistics.LatencySec != nil {
+					stats.LatencySec = *req.Statistics.LatencySec
+				}
 			}
 
 			if err := h.repo.UpdateStats(c.Request.Context(), stats); err != nil {
@@ -644,7 +786,7 @@ func (h *Handler) PatchMonitor(c *gin.Context) {
 			return
 		}
 		mergedConfig := applyConfigOverrides(existing.Config, req.Config)
-		if err := mergedConfig.Validate(); err != nil {
+		if err := validateMonitorConfig(mergedConfig); err != nil {
 			httpapi.RespondError(c, http.StatusBadRequest, httpapi.ErrCodeInvalidConfig, err.Error())
 			return
 		}
@@ -674,11 +816,42 @@ func (h *Handler) PatchMonitor(c *gin.Context) {
 	httpapi.RespondOK(c, GetMonitorResponse{
 		MonitorID: updated.ID,
 		StreamURL: updated.StreamURL,
+		VideoID:   updated.VideoID,
 		Status:    string(updated.Status),
 		CreatedAt: updated.CreatedAt.Format(time.RFC3339),
 	})
 }
 
+// validateMonitorConfig runs MonitorConfig.Validate and then checks the
+// settings that only the worker's packages can interpret: the variant
+// selections and the slate reference images.
+func validateMonitorConfig(cfg model.MonitorConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if _, err := manifest.ParseVariantSelection(cfg.VariantSelection); err != nil {
+		return fmt.Errorf("variant_selection: %w", err)
+	}
+	for _, v := range cfg.Variants {
+		if _, err := manifest.ParseVariantSelection(v); err != nil {
+			return fmt.Errorf("variants: %w", err)
+		}
+	}
+	for _, ref := range cfg.SlateReferences {
+		data, err := ref.ImageData()
+		if err != nil {
+			return fmt.Errorf("slate_references: %w", err)
+		}
+		if _, err := ffmpeg.DecodeSlateReference(ref.Name, data); err != nil {
+			return fmt.Errorf("slate_references: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyConfigOverrides merges the fields set in overrides into base. A
+// detection tunable set to 0 falls back to its default, so it is stored as
+// the value the worker will actually use.
 func applyConfigOverrides(base model.MonitorConfig, overrides *MonitorConfigRequest) model.MonitorConfig {
 	if overrides == nil {
 		return base
@@ -695,37 +868,105 @@ func applyConfigOverrides(base model.MonitorConfig, overrides *MonitorConfigRequ
 	if overrides.SilenceDBThreshold != nil {
 		base.SilenceDBThreshold = *overrides.SilenceDBThreshold
 	}
+	if overrides.SilenceMinDurationSec != nil {
+		base.SilenceMinDurationSec = *overrides.SilenceMinDurationSec
+	}
+	if overrides.SilenceRatioThreshold != nil {
+		base.SilenceRatioThreshold = *overrides.SilenceRatioThreshold
+	}
+	if overrides.BlackMinDurationSec != nil {
+		base.BlackMinDurationSec = *overrides.BlackMinDurationSec
+	}
+	if overrides.BlackPixelThreshold != nil {
+		base.BlackPixelThreshold = *overrides.BlackPixelThreshold
+	}
+	if overrides.BlackRatioThreshold != nil {
+		base.BlackRatioThreshold = *overrides.BlackRatioThreshold
+	}
+	if overrides.FreezeThresholdSec != nil {
+		base.FreezeThresholdSec = *overrides.FreezeThresholdSec
+	}
+	if overrides.FreezeNoiseDB != nil {
+		base.FreezeNoiseDB = *overrides.FreezeNoiseDB
+	}
+	if overrides.FreezeMinDurationSec != nil {
+		base.FreezeMinDurationSec = *overrides.FreezeMinDurationSec
+	}
+	if overrides.FreezeRatioThreshold != nil {
+		base.FreezeRatioThreshold = *overrides.FreezeRatioThreshold
+	}
+	if overrides.LoudnessMinLUFS != nil {
+		base.LoudnessMinLUFS = *overrides.LoudnessMinLUFS
+	}
+	if overrides.LoudnessMaxLUFS != nil {
+		base.LoudnessMaxLUFS = *overrides.LoudnessMaxLUFS
+	}
+	if overrides.LoudnessThresholdSec != nil {
+		base.LoudnessThresholdSec = *overrides.LoudnessThresholdSec
+	}
+	if overrides.MinVideoHeight != nil {
+		base.MinVideoHeight = *overrides.MinVideoHeight
+	}
+	if overrides.MinBitrateKbps != nil {
+		base.MinBitrateKbps = *overrides.MinBitrateKbps
+	}
+	if overrides.QualityDegradedSegments != nil {
+		base.QualityDegradedSegments = *overrides.QualityDegradedSegments
+	}
+	if overrides.CatchupMaxSegments != nil {
+		base.CatchupMaxSegments = *overrides.CatchupMaxSegments
+	}
+	if overrides.AVDesyncMaxOffsetMs != nil {
+		base.AVDesyncMaxOffsetMs = *overrides.AVDesyncMaxOffsetMs
+	}
+	if overrides.AVDesyncThresholdSec != nil {
+		base.AVDesyncThresholdSec = *overrides.AVDesyncThresholdSec
+	}
+	if overrides.SlateReferences != nil {
+		base.SlateReferences = *overrides.SlateReferences
+	}
+	if overrides.SlateMatchThreshold != nil {
+		base.SlateMatchThreshold = *overrides.SlateMatchThreshold
+	}
+	if overrides.SlateMaxMotion != nil {
+		base.SlateMaxMotion = *overrides.SlateMaxMotion
+	}
+	if overrides.SlateThresholdSec != nil {
+		base.SlateThresholdSec = *overrides.SlateThresholdSec
+	}
+	if overrides.ToneFrequencyHz != nil {
+		base.ToneFrequencyHz = *overrides.ToneFrequencyHz
+	}
+	if overrides.CaptionsRequired != nil {
+		base.CaptionsRequired = *overrides.CaptionsRequired
+	}
+	if overrides.CaptionsThresholdSec != nil {
+		base.CaptionsThresholdSec = *overrides.CaptionsThresholdSec
+	}
+	if overrides.LatencyMaxSec != nil {
+		base.LatencyMaxSec = *overrides.LatencyMaxSec
+	}
+	if overrides.LatencyThresholdSec != nil {
+		base.LatencyThresholdSec = *overrides.LatencyThresholdSec
+	}
+	if overrides.LowLatency != nil {
+		base.LowLatency = *overrides.LowLatency
+	}
+	if overrides.CookiesSecret != nil {
+		base.CookiesSecret = *overrides.CookiesSecret
+	}
+	if overrides.VariantSelection != nil {
+		base.VariantSelection = *overrides.VariantSelection
+	}
+	if overrides.Variants != nil {
+		base.Variants = *overrides.Variants
+	}
 	if overrides.ScheduledStartTime != nil {
 		base.ScheduledStartTime = overrides.ScheduledStartTime
 	}
 	if overrides.StartDelayToleranceSec != nil {
 		base.StartDelayToleranceSec = *overrides.StartDelayToleranceSec
 	}
+	base.DefaultUnsetTunables()
 	return base
 }
-
-func isValidYouTubeWatchURL(urlStr string) bool {
-	if !youtubeWatchURLRegex.MatchString(urlStr) {
-		return false
-	}
-
-	// Parse URL to ensure it's well-formed
-	parsed, err := url.Parse(urlStr)
-	if err != nil {
-		return false
-	}
-
-	// Check host
-	host := strings.ToLower(parsed.Host)
-	if host != "youtube.com" && host != "www.youtube.com" {
-		return false
-	}
-
-	// Check for v parameter
-	videoID := parsed.Query().Get("v")
-	if len(videoID) != 11 {
-		return false
-	}
-
-	return true
-}
@@ -10,55 +10,30 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ids"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/k8s/store"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
 )
 
-func TestIsValidYouTubeWatchURL(t *testing.T) {
-	tests := []struct {
-		name string
-		url  string
-		want bool
-	}{
-		{
-			name: "valid YouTube watch URL",
-			url:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
-			want: true,
-		},
-		{
-			name: "valid YouTube watch URL without www",
-			url:  "https://youtube.com/watch?v=dQw4w9WgXcQ",
-			want: true,
-		},
-		{
-			name: "valid YouTube watch URL with http",
-			url:  "http://www.youtube.com/watch?v=dQw4w9WgXcQ",
-			want: true,
-		},
-		{
-			name: "invalid URL - not YouTube",
-			url:  "https://example.com/watch?v=dQw4w9WgXcQ",
-			want: false,
-		},
-		{
-			name: "invalid URL - wrong domain",
-			url:  "https://youtu.be/dQw4w9WgXcQ",
-			want: false,
-		},
-		{
-			name: "invalid URL - missing video ID",
-			url:  "https://www.youtube.com/watch",
-			want: false,
-		},
-		{
-			name: "invalid URL - short video ID",
-			url:  "https://www.youtube.com/watch?v=short",
-			want: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := isValidYouTubeWatchURL(tt.url); got != tt.want {
-				t.Errorf("isValidYouTubeWatchURL(%v) = %v, want %v", tt.url, got, tt.want)
+// TestCreateMonitorInvalidStreamURL tests that CreateMonitor rejects URLs
+// that are not a YouTube video before touching the store.
+func TestCreateMonitorInvalidStreamURL(t *testing.T) {
+	handler := NewHandler(&store.Store{}, 50, nil, "key", "sign", "secrets", "ak", "sk")
+	router := setupTestRouter()
+	router.POST("/api/v1/monitors", handler.CreateMonitor)
+
+	for _, streamURL := range []string{
+		"https://example.com/watch?v=dQw4w9WgXcQ",
+		"https://www.youtube.com/watch",
+		"https://www.youtube.com/watch?v=short",
+		"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw",
+	} {
+		t.Run(streamURL, func(t *testing.T) {
+			body := `{"stream_url": "` + streamURL + `", "callback_url": "https://example.com/cb"}`
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/v1/monitors", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_URL") {
+				t.Errorf("POST %s: got status %d body %s, want 400 INVALID_URL", streamURL, w.Code, w.Body.String())
 			}
 		})
 	}
@@ -112,6 +87,83 @@ func TestCreateMonitorRequest(t *testing.T) {
 	}
 }
 
+func TestApplyConfigOverridesDetectionSensitivity(t *testing.T) {
+	var overrides MonitorConfigRequest
+	body := `{"black_pixel_threshold":0.05,"black_ratio_threshold":0.98,"silence_min_duration_sec":2}`
+	if err := json.Unmarshal([]byte(body), &overrides); err != nil {
+		t.Fatalf("Failed to unmarshal MonitorConfigRequest: %v", err)
+	}
+
+	merged := applyConfigOverrides(model.DefaultMonitorConfig(), &overrides)
+	if err := merged.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if merged.BlackPixelThreshold != 0.05 {
+		t.Errorf("BlackPixelThreshold = %v, want 0.05", merged.BlackPixelThreshold)
+	}
+	if merged.BlackRatioThreshold != 0.98 {
+		t.Errorf("BlackRatioThreshold = %v, want 0.98", merged.BlackRatioThreshold)
+	}
+	if merged.SilenceMinDurationSec != 2 {
+		t.Errorf("SilenceMinDurationSec = %v, want 2", merged.SilenceMinDurationSec)
+	}
+	// Fields not in the request keep their defaults.
+	if merged.SilenceRatioThreshold != 0.9 {
+		t.Errorf("SilenceRatioThreshold = %v, want 0.9", merged.SilenceRatioThreshold)
+	}
+
+	ratio := 1.5
+	invalid := applyConfigOverrides(model.DefaultMonitorConfig(), &MonitorConfigRequest{SilenceRatioThreshold: &ratio})
+	if err := invalid.Validate(); err == nil {
+		t.Errorf("expected Validate() to reject silence_ratio_threshold > 1")
+	}
+
+	// An explicit 0 means "use the default", on create and on PATCH.
+	var zeroOverrides MonitorConfigRequest
+	if err := json.Unmarshal([]byte(`{"silence_db_threshold":0,"black_pixel_threshold":0}`), &zeroOverrides); err != nil {
+		t.Fatalf("Failed to unmarshal MonitorConfigRequest: %v", err)
+	}
+	existing := model.DefaultMonitorConfig()
+	existing.BlackPixelThreshold = 0.2
+	existing.SilenceDBThreshold = -40
+	zero := applyConfigOverrides(existing, &zeroOverrides)
+	if err := zero.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want zero tunables defaulted", err)
+	}
+	defaults := model.DefaultMonitorConfig()
+	if zero.BlackPixelThreshold != defaults.BlackPixelThreshold || zero.SilenceDBThreshold != defaults.SilenceDBThreshold {
+		t.Errorf("zero overrides = %v, %v, want defaults %v, %v", zero.BlackPixelThreshold, zero.SilenceDBThreshold, defaults.BlackPixelThreshold, defaults.SilenceDBThreshold)
+	}
+}
+
+func TestValidateMonitorConfig(t *testing.T) {
+	if err := validateMonitorConfig(model.DefaultMonitorConfig()); err != nil {
+		t.Fatalf("validateMonitorConfig(default) error = %v", err)
+	}
+
+	badSelection := model.DefaultMonitorConfig()
+	badSelection.VariantSelection = "closest_to_height:tall"
+	if err := validateMonitorConfig(badSelection); err == nil {
+		t.Errorf("expected an unparsable variant_selection to be rejected")
+	}
+
+	badVariant := model.DefaultMonitorConfig()
+	badVariant.Variants = []string{"highest", "fastest"}
+	if err := validateMonitorConfig(badVariant); err == nil {
+		t.Errorf("expected an unparsable variants entry to be rejected")
+	}
+
+	// Valid base64 that is not an image passes Validate but not decoding.
+	badImage := model.DefaultMonitorConfig()
+	badImage.SlateReferences = []model.SlateReference{{Name: "bars", Image: "bm90IGFuIGltYWdl"}}
+	if err := badImage.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := validateMonitorConfig(badImage); err == nil {
+		t.Errorf("expected a slate reference that is not an image to be rejected")
+	}
+}
+
 func TestUpdateMonitorStatusValidation(t *testing.T) {
 	// Setup a handler with a fake repo that returns stats
 	repo := &store.Store{}
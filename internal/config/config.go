@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/xpadev-net/youtube-stream-tracker/internal/ffmpeg"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/manifest"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
 )
 
@@ -41,6 +43,17 @@ type GatewayConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+
+	// Alert evidence settings passed through to worker Pods. S3
+	// credentials are read by the Pods from the gateway secrets Secret.
+	EvidenceSink                              string
+	EvidenceBaseURL                           string
+	EvidenceS3Endpoint                        string
+	EvidenceS3Bucket                          string
+	EvidenceS3Region                          string
+	EvidenceAudioClip                         string
+	GatewayEvidenceS3AccessKeyIDSecretKey     string
+	GatewayEvidenceS3SecretAccessKeySecretKey string
 }
 
 // WorkerConfig holds configuration for the Worker.
@@ -65,6 +78,36 @@ type WorkerConfig struct {
 	BlackoutThreshold          time.Duration
 	SilenceThreshold           time.Duration
 	SilenceDBThreshold         float64
+	SilenceMinDuration         float64
+	SilenceRatioThreshold      float64
+	BlackMinDuration           float64
+	BlackPixelThreshold        float64
+	BlackRatioThreshold        float64
+	FreezeThreshold            time.Duration
+	FreezeNoiseDB              float64
+	FreezeMinDuration          float64
+	FreezeRatioThreshold       float64
+	LoudnessMinLUFS            float64
+	LoudnessMaxLUFS            float64
+	LoudnessThreshold          time.Duration
+	MinVideoHeight             int
+	MinBitrateKbps             int
+	QualityDegradedSegments    int
+	CatchupMaxSegments         int
+	AVDesyncMaxOffsetMs        int
+	AVDesyncThreshold          time.Duration
+	SlateReferences            []ffmpeg.SlateReference
+	SlateMatchThreshold        float64
+	SlateMaxMotion             float64
+	SlateThreshold             time.Duration
+	ToneFrequencyHz            int
+	CaptionsRequired           bool
+	CaptionsThreshold          time.Duration
+	LatencyMax                 time.Duration
+	LatencyThreshold           time.Duration
+	LowLatency                 bool
+	VariantSelection           manifest.VariantSelection
+	Variants                   []manifest.VariantSelection
 	DelayThreshold             time.Duration
 	Metadata                   json.RawMessage
 
@@ -83,36 +126,63 @@ type WorkerConfig struct {
 	// yt-dlp
 	YtDlpPath string
 
+	// YouTube player endpoint, used instead of yt-dlp when enabled.
+	YouTubeNativeClient  bool
+	YouTubeBaseURL       string
+	YouTubeClientVersion string
+
+	// Netscape cookie file mounted from the monitor's cookies Secret.
+	CookiesFile string
+
 	// streamlink
 	StreamlinkPath string
+
+	// Alert evidence
+	EvidenceSink              string
+	EvidenceBaseURL           string
+	EvidenceLocalDir          string
+	EvidenceS3Endpoint        string
+	EvidenceS3Bucket          string
+	EvidenceS3Region          string
+	EvidenceS3AccessKeyID     string
+	EvidenceS3SecretAccessKey string
+	EvidenceAudioClip         time.Duration
 }
 
 // LoadGatewayConfig loads the gateway configuration from environment variables.
 func LoadGatewayConfig() (*GatewayConfig, error) {
 	reconcileTimeout := getEnvDurationWithFallback("GATEWAY_RECONCILE_TIMEOUT", "RECONCILE_TIMEOUT", 30*time.Second)
 	cfg := &GatewayConfig{
-		Port:                              getEnvInt("PORT", 8080),
-		Environment:                       getEnv("ENVIRONMENT", "development"),
-		APIKey:                            getEnv("API_KEY", ""),
-		InternalAPIKey:                    getEnv("INTERNAL_API_KEY", ""),
-		WebhookSigningKey:                 getEnv("WEBHOOK_SIGNING_KEY", ""),
-		ReconcileWebhookURL:               getEnv("RECONCILIATION_WEBHOOK_URL", ""),
-		GatewaySecretsName:                getEnv("GATEWAY_SECRETS_NAME", "stream-monitor-secrets"),
-		GatewayInternalAPIKeySecretKey:    getEnv("GATEWAY_INTERNAL_API_KEY_SECRET_KEY", "internal-api-key"),
-		GatewayWebhookSigningKeySecretKey: getEnv("GATEWAY_WEBHOOK_SIGNING_KEY_SECRET_KEY", "webhook-signing-key"),
-		PodName:                           getEnv("POD_NAME", ""),
-		Namespace:                         getEnv("NAMESPACE", "default"),
-		WorkerImage:                       getEnv("WORKER_IMAGE", "stream-monitor-worker"),
-		WorkerImageTag:                    getEnv("WORKER_IMAGE_TAG", "latest"),
-		InCluster:                         getEnvBool("IN_CLUSTER", false),
-		KubeConfigPath:                    getEnv("KUBECONFIG", ""),
-		MaxMonitors:                       getEnvInt("MAX_MONITORS", 50),
-		ReconcileOnBoot:                   getEnvBool("RECONCILE_ON_BOOT", true),
-		ReconcileTimeout:                  reconcileTimeout,
-		ReconcileInterval:                 getEnvDuration("RECONCILE_INTERVAL", 5*time.Minute),
-		ReadTimeout:                       getEnvDuration("READ_TIMEOUT", 30*time.Second),
-		WriteTimeout:                      getEnvDuration("WRITE_TIMEOUT", 30*time.Second),
-		ShutdownTimeout:                   getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		Port:                                  getEnvInt("PORT", 8080),
+		Environment:                           getEnv("ENVIRONMENT", "development"),
+		APIKey:                                getEnv("API_KEY", ""),
+		InternalAPIKey:                        getEnv("INTERNAL_API_KEY", ""),
+		WebhookSigningKey:                     getEnv("WEBHOOK_SIGNING_KEY", ""),
+		ReconcileWebhookURL:                   getEnv("RECONCILIATION_WEBHOOK_URL", ""),
+		GatewaySecretsName:                    getEnv("GATEWAY_SECRETS_NAME", "stream-monitor-secrets"),
+		GatewayInternalAPIKeySecretKey:        getEnv("GATEWAY_INTERNAL_API_KEY_SECRET_KEY", "internal-api-key"),
+		GatewayWebhookSigningKeySecretKey:     getEnv("GATEWAY_WEBHOOK_SIGNING_KEY_SECRET_KEY", "webhook-signing-key"),
+		PodName:                               getEnv("POD_NAME", ""),
+		Namespace:                             getEnv("NAMESPACE", "default"),
+		WorkerImage:                           getEnv("WORKER_IMAGE", "stream-monitor-worker"),
+		WorkerImageTag:                        getEnv("WORKER_IMAGE_TAG", "latest"),
+		InCluster:                             getEnvBool("IN_CLUSTER", false),
+		KubeConfigPath:                        getEnv("KUBECONFIG", ""),
+		MaxMonitors:                           getEnvInt("MAX_MONITORS", 50),
+		ReconcileOnBoot:                       getEnvBool("RECONCILE_ON_BOOT", true),
+		ReconcileTimeout:                      reconcileTimeout,
+		ReconcileInterval:                     getEnvDuration("RECONCILE_INTERVAL", 5*time.Minute),
+		ReadTimeout:                           getEnvDuration("READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:                          getEnvDuration("WRITE_TIMEOUT", 30*time.Second),
+		ShutdownTimeout:                       getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+		EvidenceSink:                          getEnv("EVIDENCE_SINK", ""),
+		EvidenceBaseURL:                       getEnv("EVIDENCE_BASE_URL", ""),
+		EvidenceS3Endpoint:                    getEnv("EVIDENCE_S3_ENDPOINT", ""),
+		EvidenceS3Bucket:                      getEnv("EVIDENCE_S3_BUCKET", ""),
+		EvidenceS3Region:                      getEnv("EVIDENCE_S3_REGION", ""),
+		EvidenceAudioClip:                     getEnv("EVIDENCE_AUDIO_CLIP", ""),
+		GatewayEvidenceS3AccessKeyIDSecretKey: getEnv("GATEWAY_EVIDENCE_S3_ACCESS_KEY_ID_SECRET_KEY", "evidence-s3-access-key-id"),
+		GatewayEvidenceS3SecretAccessKeySecretKey: getEnv("GATEWAY_EVIDENCE_S3_SECRET_ACCESS_KEY_SECRET_KEY", "evidence-s3-secret-access-key"),
 	}
 
 	if cfg.APIKey == "" {
@@ -143,7 +213,20 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 		FFmpegPath:                 getEnv("FFMPEG_PATH", "ffmpeg"),
 		FFprobePath:                getEnv("FFPROBE_PATH", "ffprobe"),
 		YtDlpPath:                  getEnv("YTDLP_PATH", "yt-dlp"),
+		YouTubeNativeClient:        getEnvBool("YOUTUBE_NATIVE_CLIENT", false),
+		YouTubeBaseURL:             getEnv("YOUTUBE_BASE_URL", "https://www.youtube.com"),
+		YouTubeClientVersion:       getEnv("YOUTUBE_CLIENT_VERSION", ""),
+		CookiesFile:                getEnv("COOKIES_FILE", ""),
 		StreamlinkPath:             getEnv("STREAMLINK_PATH", "streamlink"),
+		EvidenceSink:               getEnv("EVIDENCE_SINK", ""),
+		EvidenceBaseURL:            getEnv("EVIDENCE_BASE_URL", ""),
+		EvidenceLocalDir:           getEnv("EVIDENCE_LOCAL_DIR", "/tmp/worker/evidence"),
+		EvidenceS3Endpoint:         getEnv("EVIDENCE_S3_ENDPOINT", ""),
+		EvidenceS3Bucket:           getEnv("EVIDENCE_S3_BUCKET", ""),
+		EvidenceS3Region:           getEnv("EVIDENCE_S3_REGION", ""),
+		EvidenceS3AccessKeyID:      getEnv("EVIDENCE_S3_ACCESS_KEY_ID", ""),
+		EvidenceS3SecretAccessKey:  getEnv("EVIDENCE_S3_SECRET_ACCESS_KEY", ""),
+		EvidenceAudioClip:          getEnvDuration("EVIDENCE_AUDIO_CLIP", 0),
 		WaitingModeInitialInterval: getEnvDuration("WAITING_MODE_INITIAL_INTERVAL", 30*time.Second),
 		WaitingModeDelayedInterval: getEnvDuration("WAITING_MODE_DELAYED_INTERVAL", 10*time.Second),
 		ManifestFetchTimeout:       getEnvDuration("MANIFEST_FETCH_TIMEOUT", 10*time.Second),
@@ -154,6 +237,25 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 		BlackoutThreshold:          getEnvDuration("BLACKOUT_THRESHOLD", 5*time.Second),
 		SilenceThreshold:           getEnvDuration("SILENCE_THRESHOLD", 5*time.Second),
 		SilenceDBThreshold:         model.DefaultMonitorConfig().SilenceDBThreshold,
+		SilenceMinDuration:         model.DefaultMonitorConfig().SilenceMinDurationSec,
+		SilenceRatioThreshold:      model.DefaultMonitorConfig().SilenceRatioThreshold,
+		BlackMinDuration:           model.DefaultMonitorConfig().BlackMinDurationSec,
+		BlackPixelThreshold:        model.DefaultMonitorConfig().BlackPixelThreshold,
+		BlackRatioThreshold:        model.DefaultMonitorConfig().BlackRatioThreshold,
+		FreezeThreshold:            getEnvDuration("FREEZE_THRESHOLD", 5*time.Second),
+		FreezeNoiseDB:              model.DefaultMonitorConfig().FreezeNoiseDB,
+		FreezeMinDuration:          model.DefaultMonitorConfig().FreezeMinDurationSec,
+		FreezeRatioThreshold:       model.DefaultMonitorConfig().FreezeRatioThreshold,
+		LoudnessMinLUFS:            model.DefaultMonitorConfig().LoudnessMinLUFS,
+		LoudnessMaxLUFS:            model.DefaultMonitorConfig().LoudnessMaxLUFS,
+		LoudnessThreshold:          getEnvDuration("LOUDNESS_THRESHOLD", 60*time.Second),
+		QualityDegradedSegments:    model.DefaultMonitorConfig().QualityDegradedSegments,
+		CatchupMaxSegments:         model.DefaultMonitorConfig().CatchupMaxSegments,
+		AVDesyncThreshold:          time.Duration(model.DefaultMonitorConfig().AVDesyncThresholdSec) * time.Second,
+		SlateMatchThreshold:        model.DefaultMonitorConfig().SlateMatchThreshold,
+		SlateThreshold:             time.Duration(model.DefaultMonitorConfig().SlateThresholdSec) * time.Second,
+		CaptionsThreshold:          time.Duration(model.DefaultMonitorConfig().CaptionsThresholdSec) * time.Second,
+		LatencyThreshold:           time.Duration(model.DefaultMonitorConfig().LatencyThresholdSec) * time.Second,
 		DelayThreshold:             getEnvDuration("DELAY_THRESHOLD", 300*time.Second),
 	}
 
@@ -175,12 +277,112 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 		if monitorConfig.SilenceThresholdSec > 0 {
 			cfg.SilenceThreshold = time.Duration(monitorConfig.SilenceThresholdSec) * time.Second
 		}
+		if monitorConfig.FreezeThresholdSec > 0 {
+			cfg.FreezeThreshold = time.Duration(monitorConfig.FreezeThresholdSec) * time.Second
+		}
+		if monitorConfig.LoudnessMinLUFS != 0 {
+			cfg.LoudnessMinLUFS = monitorConfig.LoudnessMinLUFS
+		}
+		if monitorConfig.LoudnessMaxLUFS != 0 {
+			cfg.LoudnessMaxLUFS = monitorConfig.LoudnessMaxLUFS
+		}
+		if monitorConfig.LoudnessThresholdSec > 0 {
+			cfg.LoudnessThreshold = time.Duration(monitorConfig.LoudnessThresholdSec) * time.Second
+		}
 		if monitorConfig.StartDelayToleranceSec > 0 {
 			cfg.DelayThreshold = time.Duration(monitorConfig.StartDelayToleranceSec) * time.Second
 		}
 		if monitorConfig.SilenceDBThreshold != 0 {
 			cfg.SilenceDBThreshold = monitorConfig.SilenceDBThreshold
 		}
+		if monitorConfig.SilenceMinDurationSec > 0 {
+			cfg.SilenceMinDuration = monitorConfig.SilenceMinDurationSec
+		}
+		if monitorConfig.SilenceRatioThreshold > 0 {
+			cfg.SilenceRatioThreshold = monitorConfig.SilenceRatioThreshold
+		}
+		if monitorConfig.BlackMinDurationSec > 0 {
+			cfg.BlackMinDuration = monitorConfig.BlackMinDurationSec
+		}
+		if monitorConfig.BlackPixelThreshold > 0 {
+			cfg.BlackPixelThreshold = monitorConfig.BlackPixelThreshold
+		}
+		if monitorConfig.BlackRatioThreshold > 0 {
+			cfg.BlackRatioThreshold = monitorConfig.BlackRatioThreshold
+		}
+		if monitorConfig.FreezeNoiseDB != 0 {
+			cfg.FreezeNoiseDB = monitorConfig.FreezeNoiseDB
+		}
+		if monitorConfig.FreezeMinDurationSec > 0 {
+			cfg.FreezeMinDuration = monitorConfig.FreezeMinDurationSec
+		}
+		if monitorConfig.FreezeRatioThreshold > 0 {
+			cfg.FreezeRatioThreshold = monitorConfig.FreezeRatioThreshold
+		}
+		if monitorConfig.MinVideoHeight > 0 {
+			cfg.MinVideoHeight = monitorConfig.MinVideoHeight
+		}
+		if monitorConfig.MinBitrateKbps > 0 {
+			cfg.MinBitrateKbps = monitorConfig.MinBitrateKbps
+		}
+		if monitorConfig.QualityDegradedSegments > 0 {
+			cfg.QualityDegradedSegments = monitorConfig.QualityDegradedSegments
+		}
+		if monitorConfig.CatchupMaxSegments > 0 {
+			cfg.CatchupMaxSegments = monitorConfig.CatchupMaxSegments
+		}
+		if monitorConfig.AVDesyncMaxOffsetMs > 0 {
+			cfg.AVDesyncMaxOffsetMs = monitorConfig.AVDesyncMaxOffsetMs
+		}
+		if monitorConfig.AVDesyncThresholdSec > 0 {
+			cfg.AVDesyncThreshold = time.Duration(monitorConfig.AVDesyncThresholdSec) * time.Second
+		}
+		for _, ref := range monitorConfig.SlateReferences {
+			data, err := ref.ImageData()
+			if err != nil {
+				return nil, fmt.Errorf("parse CONFIG_JSON slate_references: %w", err)
+			}
+			decoded, err := ffmpeg.DecodeSlateReference(ref.Name, data)
+			if err != nil {
+				return nil, fmt.Errorf("parse CONFIG_JSON slate_references: %w", err)
+			}
+			cfg.SlateReferences = append(cfg.SlateReferences, decoded)
+		}
+		if monitorConfig.SlateMatchThreshold > 0 {
+			cfg.SlateMatchThreshold = monitorConfig.SlateMatchThreshold
+		}
+		if monitorConfig.SlateMaxMotion > 0 {
+			cfg.SlateMaxMotion = monitorConfig.SlateMaxMotion
+		}
+		if monitorConfig.SlateThresholdSec > 0 {
+			cfg.SlateThreshold = time.Duration(monitorConfig.SlateThresholdSec) * time.Second
+		}
+		if monitorConfig.ToneFrequencyHz > 0 {
+			cfg.ToneFrequencyHz = monitorConfig.ToneFrequencyHz
+		}
+		cfg.CaptionsRequired = monitorConfig.CaptionsRequired
+		if monitorConfig.CaptionsThresholdSec > 0 {
+			cfg.CaptionsThreshold = time.Duration(monitorConfig.CaptionsThresholdSec) * time.Second
+		}
+		if monitorConfig.LatencyMaxSec > 0 {
+			cfg.LatencyMax = time.Duration(monitorConfig.LatencyMaxSec) * time.Second
+		}
+		if monitorConfig.LatencyThresholdSec > 0 {
+			cfg.LatencyThreshold = time.Duration(monitorConfig.LatencyThresholdSec) * time.Second
+		}
+		cfg.LowLatency = monitorConfig.LowLatency
+		variantSelection, err := manifest.ParseVariantSelection(monitorConfig.VariantSelection)
+		if err != nil {
+			return nil, fmt.Errorf("parse CONFIG_JSON variant_selection: %w", err)
+		}
+		cfg.VariantSelection = variantSelection
+		for _, v := range monitorConfig.Variants {
+			selection, err := manifest.ParseVariantSelection(v)
+			if err != nil {
+				return nil, fmt.Errorf("parse CONFIG_JSON variants: %w", err)
+			}
+			cfg.Variants = append(cfg.Variants, selection)
+		}
 	}
 
 	if metadataJSON := os.Getenv("METADATA_JSON"); metadataJSON != "" {
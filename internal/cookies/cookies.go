@@ -0,0 +1,187 @@
+// Package cookies loads Netscape cookie files, the format yt-dlp reads with
+// --cookies, so the same logged-in session can be used for manifest and
+// segment requests. Errors never include cookie names or values.
+package cookies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in files written by browsers and
+// yt-dlp; the line is otherwise a normal cookie line.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// reloadInterval bounds how often a FileJar checks its file for changes.
+const reloadInterval = 30 * time.Second
+
+// Parse reads a Netscape cookie file. Domain is kept as written: a leading
+// dot (include subdomains) marks a domain cookie, anything else a host-only
+// one. Expired cookies are skipped; an expiry of 0 is a session cookie.
+func Parse(r io.Reader, now time.Time) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+			httpOnly = true
+		} else if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNo, len(fields))
+		}
+		domain, path, secure, expires, name, value := fields[0], fields[2], fields[3], fields[4], fields[5], fields[6]
+		if domain == "" || name == "" {
+			return nil, fmt.Errorf("line %d: missing domain or name", lineNo)
+		}
+		expiry, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry", lineNo)
+		}
+
+		cookie := &http.Cookie{
+			Name:     name,
+			Domain:   domain,
+			Path:     path,
+			Value:    value,
+			Secure:   strings.EqualFold(secure, "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+			if !cookie.Expires.After(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// NewJar returns a cookie jar holding cookies.
+func NewJar(cookies []*http.Cookie) (*cookiejar.Jar, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, fmt.Errorf("create cookie jar: %w", err)
+	}
+	byHost := make(map[string][]*http.Cookie)
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		if !strings.HasPrefix(cookie.Domain, ".") {
+			// The jar treats cookies without a Domain as host-only.
+			hostOnly := *cookie
+			hostOnly.Domain = ""
+			cookie = &hostOnly
+		}
+		byHost[host] = append(byHost[host], cookie)
+	}
+	for host, hostCookies := range byHost {
+		jar.SetCookies(&url.URL{Scheme: "https", Host: host, Path: "/"}, hostCookies)
+	}
+	return jar, nil
+}
+
+// FileJar is an http.CookieJar backed by a Netscape cookie file. It reloads
+// the file when its modification time changes, so a rotated Kubernetes
+// Secret is picked up without restarting the worker.
+type FileJar struct {
+	path string
+	now  func() time.Time
+
+	mu        sync.Mutex
+	jar       *cookiejar.Jar
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewFileJar loads path into a FileJar.
+func NewFileJar(path string) (*FileJar, error) {
+	j := &FileJar{path: path, now: time.Now}
+	if err := j.reload(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Cookies implements http.CookieJar.
+func (j *FileJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.reloadIfChangedLocked()
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar. Cookies set by servers are kept
+// until the file is next reloaded.
+func (j *FileJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+// reloadIfChangedLocked reloads the file at most once per reloadInterval
+// when its modification time has changed. A file that cannot be read keeps
+// the previous cookies. j.mu must be held.
+func (j *FileJar) reloadIfChangedLocked() {
+	now := j.now()
+	if now.Sub(j.checkedAt) < reloadInterval {
+		return
+	}
+	j.checkedAt = now
+	info, err := os.Stat(j.path)
+	if err != nil || info.ModTime().Equal(j.modTime) {
+		return
+	}
+	_ = j.loadLocked()
+}
+
+func (j *FileJar) reload() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.checkedAt = j.now()
+	return j.loadLocked()
+}
+
+func (j *FileJar) loadLocked() error {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return fmt.Errorf("open cookies file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat cookies file: %w", err)
+	}
+	cookies, err := Parse(f, j.now())
+	if err != nil {
+		return fmt.Errorf("parse cookies file: %w", err)
+	}
+	jar, err := NewJar(cookies)
+	if err != nil {
+		return err
+	}
+	j.jar = jar
+	j.modTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,115 @@
+package cookies
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cookiesFile = "# Netscape HTTP Cookie File\n" +
+	"# This is a generated file! Do not edit.\n" +
+	"\n" +
+	".youtube.com\tTRUE\t/\tTRUE\t1893456000\tSID\tsession-value\n" +
+	"#HttpOnly_.youtube.com\tTRUE\t/\tTRUE\t0\tHSID\thttponly-value\n" +
+	"accounts.google.com\tFALSE\t/\tTRUE\t1893456000\tLSID\thost-value\n" +
+	".youtube.com\tTRUE\t/\tFALSE\t1000000000\tOLD\texpired-value\n"
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	cookies, err := Parse(strings.NewReader(cookiesFile), now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cookies) != 3 {
+		t.Fatalf("got %d cookies, want 3 (expired one skipped)", len(cookies))
+	}
+	if c := cookies[0]; c.Name != "SID" || c.Domain != ".youtube.com" || !c.Secure || c.HttpOnly {
+		t.Fatalf("unexpected first cookie: %+v", c)
+	}
+	if c := cookies[1]; c.Name != "HSID" || !c.HttpOnly || !c.Expires.IsZero() {
+		t.Fatalf("unexpected HttpOnly session cookie: %+v", c)
+	}
+}
+
+func TestParseErrorsOmitValues(t *testing.T) {
+	_, err := Parse(strings.NewReader(".youtube.com\tTRUE\t/\tTRUE\tnever\tSID\tsecret-value\n"), time.Now())
+	if err == nil {
+		t.Fatal("expected an error for an invalid expiry")
+	}
+	if !strings.Contains(err.Error(), "line 1") || strings.Contains(err.Error(), "secret-value") || strings.Contains(err.Error(), "SID") {
+		t.Fatalf("error = %q, want a line number without cookie contents", err)
+	}
+}
+
+func TestNewJarDomains(t *testing.T) {
+	cookies, err := Parse(strings.NewReader(cookiesFile), time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	jar, err := NewJar(cookies)
+	if err != nil {
+		t.Fatalf("NewJar: %v", err)
+	}
+
+	if got := names(jar.Cookies(mustParseURL(t, "https://www.youtube.com/watch"))); got != "HSID,SID" {
+		t.Fatalf("www.youtube.com cookies = %s, want domain cookies", got)
+	}
+	if got := names(jar.Cookies(mustParseURL(t, "https://accounts.google.com/"))); got != "LSID" {
+		t.Fatalf("accounts.google.com cookies = %s, want the host-only cookie", got)
+	}
+	if got := names(jar.Cookies(mustParseURL(t, "https://mail.accounts.google.com/"))); got != "" {
+		t.Fatalf("host-only cookie leaked to a subdomain: %s", got)
+	}
+}
+
+func TestFileJarReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(path, []byte(".youtube.com\tTRUE\t/\tTRUE\t0\tSID\told\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := NewFileJar(path)
+	if err != nil {
+		t.Fatalf("NewFileJar: %v", err)
+	}
+	now := time.Now()
+	jar.now = func() time.Time { return now }
+	u := mustParseURL(t, "https://www.youtube.com/")
+
+	if err := os.WriteFile(path, []byte(".youtube.com\tTRUE\t/\tTRUE\t0\tSID\tnew\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, now.Add(time.Minute), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got := jar.Cookies(u); len(got) != 1 || got[0].Value != "old" {
+		t.Fatalf("reloaded before reloadInterval elapsed: %+v", got)
+	}
+
+	now = now.Add(reloadInterval)
+	if got := jar.Cookies(u); len(got) != 1 || got[0].Value != "new" {
+		t.Fatalf("cookies after rotation = %+v, want the new value", got)
+	}
+}
+
+func names(cookies []*http.Cookie) string {
+	var out []string
+	for _, c := range cookies {
+		out = append(out, c.Name)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -0,0 +1,70 @@
+// Package evidence stores artifacts captured from a segment when an alert
+// fires (a keyframe thumbnail and an optional audio clip), so the webhook
+// can link to what the stream looked like at that moment.
+package evidence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Sink kinds accepted by NewSink.
+const (
+	SinkLocal = "local"
+	SinkS3    = "s3"
+)
+
+// Sink stores an artifact under key and returns a URL it can be fetched from.
+type Sink interface {
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+}
+
+// Config selects and configures a Sink.
+type Config struct {
+	// Sink is "local", "s3" or empty to disable evidence capture.
+	Sink string
+	// BaseURL, when set, is used to build returned URLs as BaseURL/key
+	// instead of the sink's own location (e.g. a CDN in front of the bucket).
+	BaseURL string
+
+	// LocalDir is the directory the local sink writes to.
+	LocalDir string
+
+	// S3-compatible endpoint settings.
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+// NewSink builds the Sink described by cfg. It returns a nil Sink and no
+// error when evidence capture is disabled.
+func NewSink(cfg Config) (Sink, error) {
+	switch cfg.Sink {
+	case "":
+		return nil, nil
+	case SinkLocal:
+		if cfg.LocalDir == "" {
+			return nil, fmt.Errorf("local evidence sink requires a directory")
+		}
+		return NewLocalSink(cfg.LocalDir, cfg.BaseURL), nil
+	case SinkS3:
+		return NewS3Sink(S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			BaseURL:         cfg.BaseURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown evidence sink %q", cfg.Sink)
+	}
+}
+
+// joinURL joins a base URL and an object key with a single slash.
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
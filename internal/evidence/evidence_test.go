@@ -0,0 +1,140 @@
+package evidence
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSinkDisabled(t *testing.T) {
+	sink, err := NewSink(Config{})
+	if err != nil {
+		t.Fatalf("NewSink() error = %v", err)
+	}
+	if sink != nil {
+		t.Fatalf("expected nil sink when evidence is disabled")
+	}
+}
+
+func TestNewSinkInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown kind", Config{Sink: "ftp"}},
+		{"local without dir", Config{Sink: SinkLocal}},
+		{"s3 without bucket", Config{Sink: SinkS3, S3Endpoint: "https://s3.example.com", S3AccessKeyID: "a", S3SecretAccessKey: "b"}},
+		{"s3 without credentials", Config{Sink: SinkS3, S3Endpoint: "https://s3.example.com", S3Bucket: "b"}},
+		{"s3 bad endpoint", Config{Sink: SinkS3, S3Endpoint: "s3.example.com", S3Bucket: "b", S3AccessKeyID: "a", S3SecretAccessKey: "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSink(tt.cfg); err == nil {
+				t.Fatalf("expected error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestLocalSinkPut(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewLocalSink(dir, "https://evidence.example.com/")
+
+	url, err := sink.Put(context.Background(), "mon-1/alert.blackout-1.jpg", "image/jpeg", []byte("jpeg"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if url != "https://evidence.example.com/mon-1/alert.blackout-1.jpg" {
+		t.Fatalf("url = %q", url)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "mon-1", "alert.blackout-1.jpg"))
+	if err != nil {
+		t.Fatalf("read written file: %v", err)
+	}
+	if string(data) != "jpeg" {
+		t.Fatalf("file content = %q, want jpeg", data)
+	}
+
+	if _, err := sink.Put(context.Background(), "../escape.jpg", "image/jpeg", []byte("x")); err == nil {
+		t.Fatalf("expected key escaping the directory to be rejected")
+	}
+}
+
+func TestLocalSinkPutFileURL(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewLocalSink(dir, "")
+
+	url, err := sink.Put(context.Background(), "a.jpg", "image/jpeg", []byte("x"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if !strings.HasPrefix(url, "file://") || !strings.HasSuffix(url, "/a.jpg") {
+		t.Fatalf("url = %q, want file:// URL", url)
+	}
+}
+
+func TestS3SinkPut(t *testing.T) {
+	var gotPath, gotAuth, gotType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink, err := NewS3Sink(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "evidence",
+		Region:          "ap-northeast-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Sink() error = %v", err)
+	}
+	sink.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	url, err := sink.Put(context.Background(), "mon-1/thumb.jpg", "image/jpeg", []byte("jpeg"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if url != server.URL+"/evidence/mon-1/thumb.jpg" {
+		t.Fatalf("url = %q", url)
+	}
+	if gotPath != "/evidence/mon-1/thumb.jpg" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotType != "image/jpeg" || gotBody != "jpeg" {
+		t.Fatalf("content-type = %q, body = %q", gotType, gotBody)
+	}
+	wantPrefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/ap-northeast-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="
+	if !strings.HasPrefix(gotAuth, wantPrefix) {
+		t.Fatalf("Authorization = %q", gotAuth)
+	}
+}
+
+func TestS3SinkPutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sink, err := NewS3Sink(S3Config{Endpoint: server.URL, Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"})
+	if err != nil {
+		t.Fatalf("NewS3Sink() error = %v", err)
+	}
+	if _, err := sink.Put(context.Background(), "k.jpg", "image/jpeg", []byte("x")); err == nil {
+		t.Fatalf("expected error on 403 response")
+	}
+}
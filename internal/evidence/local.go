@@ -0,0 +1,46 @@
+package evidence
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalSink writes artifacts to a directory on the local filesystem.
+type LocalSink struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalSink creates a sink writing under dir. Returned URLs use baseURL
+// when set and a file:// URL otherwise.
+func NewLocalSink(dir, baseURL string) *LocalSink {
+	return &LocalSink{dir: dir, baseURL: baseURL}
+}
+
+// Put writes data to dir/key.
+func (s *LocalSink) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	// Keys are built by the worker, but never let one escape the directory.
+	if rel, err := filepath.Rel(s.dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid evidence key %q", key)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create evidence dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("write evidence: %w", err)
+	}
+
+	if s.baseURL != "" {
+		return joinURL(s.baseURL, key), nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve evidence path: %w", err)
+	}
+	return "file://" + filepath.ToSlash(abs), nil
+}
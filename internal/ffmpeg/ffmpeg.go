@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -31,38 +34,193 @@ type SilenceDetectResult struct {
 	TotalDuration    float64
 	SilenceRatio     float64
 	FullySilent      bool
+
+	// Channels holds per-channel results for multi-channel audio, ordered
+	// by channel index. It is nil for mono (or audio-less) segments.
+	Channels []ChannelSilenceResult
+}
+
+// ChannelSilenceResult contains the silence detection result of a single
+// audio channel.
+type ChannelSilenceResult struct {
+	Channel         int
+	SilenceDuration float64
+	SilenceRatio    float64
+	FullySilent     bool
+}
+
+// FreezeDetectResult contains the result of frozen frame detection.
+type FreezeDetectResult struct {
+	HasFreeze       bool
+	FreezeDuration  float64
+	FreezeStartTime float64
+	TotalDuration   float64
+	FreezeRatio     float64
+	FullyFrozen     bool
+}
+
+// LoudnessResult contains the EBU R128 loudness measurement of a segment.
+type LoudnessResult struct {
+	// Measured is false when ebur128 could not produce an integrated
+	// loudness value, e.g. because the whole segment fell below the
+	// absolute gate (-70 LUFS) or had no audio at all.
+	Measured       bool
+	IntegratedLUFS float64
+	TruePeakDBFS   float64
+	TotalDuration  float64
+}
+
+// QualityResult contains the stream properties of a segment. Fields are
+// zero when they could not be determined (e.g. an audio-only segment).
+type QualityResult struct {
+	Width       int
+	Height      int
+	FrameRate   float64
+	BitrateKbps float64
+}
+
+// CaptionResult reports whether a segment's video carries CEA-608/708
+// closed captions.
+type CaptionResult struct {
+	Present bool
+}
+
+// AVSyncResult compares the timing of the first audio and video streams
+// of a segment. Offsets are audio minus video in milliseconds, so a
+// positive value means audio lags video.
+type AVSyncResult struct {
+	// Measured is false when MeasureAVSync is off, the segment lacks an
+	// audio or video stream, or no frame carried a timestamp.
+	Measured      bool
+	VideoStart    float64
+	AudioStart    float64
+	VideoDuration float64
+	AudioDuration float64
+	StartOffsetMs float64
+	// EndOffsetMs compares stream end times; it equals StartOffsetMs when
+	// either stream duration is unknown.
+	EndOffsetMs float64
+	// OffsetMs is whichever of StartOffsetMs and EndOffsetMs is larger in
+	// magnitude.
+	OffsetMs float64
 }
 
 // AnalysisResult contains the combined analysis result.
 type AnalysisResult struct {
-	Black   *BlackDetectResult
-	Silence *SilenceDetectResult
+	Black    *BlackDetectResult
+	Silence  *SilenceDetectResult
+	Freeze   *FreezeDetectResult
+	Loudness *LoudnessResult
+	Quality  *QualityResult
+	AVSync   *AVSyncResult
+	// Captions is nil when the segment has no video.
+	Captions *CaptionResult
+	// Still is nil when still-image detection is disabled or the segment
+	// has no video.
+	Still *StillImageResult
+	// Tone is nil when tone detection is disabled or the segment has no
+	// audio.
+	Tone *ToneDetectResult
+}
+
+// DetectionParams controls the sensitivity of black, silence, still-image
+// and tone detection.
+// Zero values fall back to the defaults noted on each field.
+type DetectionParams struct {
+	// SilenceDBThreshold is the silencedetect noise level in dB (-50).
+	SilenceDBThreshold float64
+	// SilenceMinDuration is the shortest silence silencedetect reports, in seconds (0.5).
+	SilenceMinDuration float64
+	// SilenceRatioThreshold is the silent share of a segment above which it is fully silent (0.9).
+	SilenceRatioThreshold float64
+	// BlackMinDuration is the shortest black run blackdetect reports, in seconds (0.1).
+	BlackMinDuration float64
+	// BlackPixelThreshold is the blackdetect pixel luminance threshold, 0.0-1.0 (0.10).
+	BlackPixelThreshold float64
+	// BlackRatioThreshold is the black share of a segment above which it is fully black (0.9).
+	BlackRatioThreshold float64
+	// FreezeNoiseDB is the freezedetect noise tolerance between frames in dB (-60).
+	FreezeNoiseDB float64
+	// FreezeMinDuration is the shortest freeze freezedetect reports, in seconds (0.5).
+	FreezeMinDuration float64
+	// FreezeRatioThreshold is the frozen share of a segment above which it is fully frozen (0.9).
+	FreezeRatioThreshold float64
+	// SlateReferences are still images a segment is matched against (none).
+	SlateReferences []SlateReference
+	// SlateMatchThreshold is the similarity, 0.0-1.0, at which a segment matches a reference (0.9).
+	SlateMatchThreshold float64
+	// SlateMaxMotion is the frame difference, 0.0-1.0, at or below which a segment is low motion (0, disabled).
+	SlateMaxMotion float64
+	// ToneFrequency is the test-tone frequency in Hz; blocks louder than
+	// SilenceDBThreshold and dominated by it count as tone (0, disabled).
+	ToneFrequency float64
+	// MeasureAVSync logs frame timestamps so AnalysisResult.AVSync is
+	// measured (false).
+	MeasureAVSync bool
+}
+
+// withDefaults returns p with zero values replaced by defaults.
+func (p DetectionParams) withDefaults() DetectionParams {
+	if p.SilenceDBThreshold == 0 {
+		p.SilenceDBThreshold = -50
+	}
+	if p.SilenceMinDuration == 0 {
+		p.SilenceMinDuration = 0.5
+	}
+	if p.SilenceRatioThreshold == 0 {
+		p.SilenceRatioThreshold = 0.9
+	}
+	if p.BlackMinDuration == 0 {
+		p.BlackMinDuration = 0.1
+	}
+	if p.BlackPixelThreshold == 0 {
+		p.BlackPixelThreshold = 0.10
+	}
+	if p.BlackRatioThreshold == 0 {
+		p.BlackRatioThreshold = 0.9
+	}
+	if p.FreezeNoiseDB == 0 {
+		p.FreezeNoiseDB = -60
+	}
+	if p.FreezeMinDuration == 0 {
+		p.FreezeMinDuration = 0.5
+	}
+	if p.FreezeRatioThreshold == 0 {
+		p.FreezeRatioThreshold = 0.9
+	}
+	if p.SlateMatchThreshold == 0 {
+		p.SlateMatchThreshold = 0.9
+	}
+	return p
 }
 
 // Analyzer handles FFmpeg-based media analysis.
 type Analyzer struct {
-	ffmpegPath         string
-	ffprobePath        string
-	tmpDir             string
-	silenceDBThreshold float64
+	ffmpegPath  string
+	ffprobePath string
+	tmpDir      string
+	params      DetectionParams
+	slateRefs   []stillReference
 }
 
 // NewAnalyzer creates a new FFmpeg analyzer.
-func NewAnalyzer(ffmpegPath, ffprobePath, tmpDir string, silenceDBThreshold float64) *Analyzer {
+func NewAnalyzer(ffmpegPath, ffprobePath, tmpDir string, params DetectionParams) *Analyzer {
 	if ffmpegPath == "" {
 		ffmpegPath = "ffmpeg"
 	}
 	if ffprobePath == "" {
 		ffprobePath = "ffprobe"
 	}
-	if silenceDBThreshold == 0 {
-		silenceDBThreshold = -50
+	slateRefs := make([]stillReference, 0, len(params.SlateReferences))
+	for _, ref := range params.SlateReferences {
+		slateRefs = append(slateRefs, stillReference{name: ref.Name, pixels: stillFramePixels(ref.Image)})
 	}
 	return &Analyzer{
-		ffmpegPath:         ffmpegPath,
-		ffprobePath:        ffprobePath,
-		tmpDir:             tmpDir,
-		silenceDBThreshold: silenceDBThreshold,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+		tmpDir:      tmpDir,
+		params:      params.withDefaults(),
+		slateRefs:   slateRefs,
 	}
 }
 
@@ -71,32 +229,309 @@ func (a *Analyzer) EnsureTmpDir() error {
 	return os.MkdirAll(a.tmpDir, 0755)
 }
 
-// AnalyzeSegment performs black and silence detection on a segment file.
+// AnalyzeSegment performs black, silence and freeze detection, loudness
+// measurement and, when enabled, A/V sync, still-image and tone detection
+// on a segment file. Every detector hangs off a single ffmpeg invocation
+// so the segment is decoded only once.
 func (a *Analyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*AnalysisResult, error) {
-	// First, get the duration of the segment
-	duration, err := a.getDuration(ctx, segmentPath)
+	run, err := a.runAnalysis(ctx, segmentPath, true)
+	if err != nil && a.hasSampleOutputs() {
+		// A sample output fails the whole run when the segment lacks its
+		// stream (e.g. tone detection on a video-only stream), so retry
+		// with the filters alone; still and tone results are best effort.
+		run, err = a.runAnalysis(ctx, segmentPath, false)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("get duration: %w", err)
+		return nil, err
 	}
+	output := run.log
 
-	// Analyze for black frames
-	blackResult, err := a.detectBlack(ctx, segmentPath, duration)
-	if err != nil {
-		return nil, fmt.Errorf("detect black: %w", err)
+	// ffmpeg prints the container duration in its input header; only
+	// fall back to ffprobe when it is not available (e.g. "N/A").
+	duration, ok := parseInputDuration(output)
+	if !ok {
+		duration, err = a.getDuration(ctx, segmentPath)
+		if err != nil {
+			return nil, fmt.Errorf("get duration: %w", err)
+		}
 	}
 
-	// Analyze for silence
-	silenceResult, err := a.detectSilence(ctx, segmentPath, duration)
-	if err != nil {
-		return nil, fmt.Errorf("detect silence: %w", err)
+	channels, ok := parseAudioChannels(output)
+	if !ok {
+		channels, err = a.getAudioChannels(ctx, segmentPath)
+		if err != nil {
+			return nil, fmt.Errorf("get audio channels: %w", err)
+		}
+	}
+
+	silenceResult := parseSilenceOutput(mixedSilenceOutput(output), duration, a.params.SilenceRatioThreshold)
+	// Per-channel results are only meaningful when there is more than one
+	// channel; a single dead channel would otherwise be masked by the mixdown.
+	if channels > 1 {
+		silenceResult.Channels = parseChannelSilenceOutput(output, duration, channels, a.params.SilenceRatioThreshold)
+	}
+
+	quality := parseVideoQuality(output)
+	// The segment's overall bitrate (video and audio together) is what the
+	// encoder actually delivered; the container header value is often N/A
+	// for short TS segments.
+	if info, err := os.Stat(segmentPath); err == nil && duration > 0 {
+		quality.BitrateKbps = float64(info.Size()) * 8 / duration / 1000
+	}
+
+	var still *StillImageResult
+	if a.stillDetectionEnabled() && quality.Height > 0 && len(run.stillFrames) > 0 {
+		still = detectStill(splitStillFrames(run.stillFrames), a.slateRefs, a.params.SlateMaxMotion, a.params.SlateMatchThreshold)
+	}
+
+	var tone *ToneDetectResult
+	if a.params.ToneFrequency > 0 && channels > 0 && len(run.toneAudio) > 0 {
+		tone = detectTone(toneSamples(run.toneAudio), a.params.ToneFrequency, a.params.SilenceDBThreshold, a.params.SilenceRatioThreshold)
 	}
 
 	return &AnalysisResult{
-		Black:   blackResult,
-		Silence: silenceResult,
+		Black:    parseBlackOutput(output, duration, a.params.BlackRatioThreshold),
+		Silence:  silenceResult,
+		Freeze:   parseFreezeOutput(output, duration, a.params.FreezeRatioThreshold),
+		Loudness: parseLoudnessSummary(output, duration),
+		Quality:  quality,
+		// Unmeasured unless MeasureAVSync attached showinfo and ashowinfo.
+		AVSync:   parseFrameTimings(output),
+		Captions: parseClosedCaptions(output),
+		Still:    still,
+		Tone:     tone,
 	}, nil
 }
 
+// analysisRun is what a single analysis invocation of ffmpeg produced.
+type analysisRun struct {
+	// log is ffmpeg's stderr, where all detectors log their results.
+	log string
+	// stillFrames holds the raw frames written for still-image detection.
+	stillFrames []byte
+	// toneAudio holds the PCM written for tone detection.
+	toneAudio []byte
+}
+
+// hasSampleOutputs reports whether runAnalysis adds outputs that hand
+// decoded frames or samples back to a detector.
+func (a *Analyzer) hasSampleOutputs() bool {
+	return a.stillDetectionEnabled() || a.params.ToneFrequency > 0
+}
+
+// runAnalysis decodes the segment once with every detector attached.
+// With samples set, still-image and tone detection get their frames and
+// PCM from extra outputs of the same run, written to pipes.
+func (a *Analyzer) runAnalysis(ctx context.Context, filePath string, samples bool) (*analysisRun, error) {
+	// Video filters (both pass frames through unchanged):
+	// blackdetect d: minimum black duration to detect (default 100ms)
+	// blackdetect pix_th: pixel threshold (0.0-1.0, default 0.10)
+	// freezedetect n: noise tolerance between frames (default -60dB)
+	// freezedetect d: minimum freeze duration to detect (default 0.5s)
+	videoFilters := fmt.Sprintf(
+		"blackdetect=d=%g:pix_th=%g,freezedetect=n=%gdB:d=%g",
+		a.params.BlackMinDuration, a.params.BlackPixelThreshold,
+		a.params.FreezeNoiseDB, a.params.FreezeMinDuration,
+	)
+
+	// Audio filters (all pass samples through unchanged):
+	// silencedetect n: noise threshold, d: minimum silence duration
+	// silencedetect mono=1: same detection, reported per channel
+	// ebur128 peak=true: also measure true peak (oversampled)
+	// ebur128 framelog=verbose: keep per-frame lines out of the output
+	audioFilters := fmt.Sprintf(
+		"silencedetect=n=%[1]gdB:d=%[2]g,silencedetect=n=%[1]gdB:d=%[2]g:mono=1,ebur128=peak=true:framelog=verbose",
+		a.params.SilenceDBThreshold, a.params.SilenceMinDuration,
+	)
+
+	// showinfo and ashowinfo log every decoded frame's timestamp, which is
+	// what A/V sync tracking compares; they come first so the timestamps
+	// are the decoder's.
+	if a.params.MeasureAVSync {
+		videoFilters = "showinfo=checksum=0," + videoFilters
+		audioFilters = "ashowinfo," + audioFilters
+	}
+
+	args := []string{
+		"-hide_banner",
+		"-nostats",
+		"-i", filePath,
+		"-vf", videoFilters,
+		"-af", audioFilters,
+		"-f", "null",
+		"-",
+	}
+
+	run := &analysisRun{}
+	type sampleOutput struct {
+		args []string
+		dst  *[]byte
+	}
+	var outputs []sampleOutput
+	if samples {
+		if a.stillDetectionEnabled() {
+			outputs = append(outputs, sampleOutput{stillOutputArgs(), &run.stillFrames})
+		}
+		if a.params.ToneFrequency > 0 {
+			outputs = append(outputs, sampleOutput{toneOutputArgs(), &run.toneAudio})
+		}
+	}
+
+	var readers, writers []*os.File
+	closeAll := func(files []*os.File) {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	for i, out := range outputs {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeAll(readers)
+			closeAll(writers)
+			return nil, fmt.Errorf("create sample pipe: %w", err)
+		}
+		readers = append(readers, r)
+		writers = append(writers, w)
+		// ExtraFiles start at file descriptor 3 in the child.
+		args = append(args, out.args...)
+		args = append(args, fmt.Sprintf("pipe:%d", 3+i))
+	}
+	defer closeAll(readers)
+
+	cmd := exec.CommandContext(ctx, a.ffmpegPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.ExtraFiles = writers
+
+	if err := cmd.Start(); err != nil {
+		closeAll(writers)
+		return nil, fmt.Errorf("ffmpeg analysis failed: %w", err)
+	}
+	// Only the child writes now; closing our ends lets the readers see EOF.
+	closeAll(writers)
+
+	var wg sync.WaitGroup
+	for i, out := range outputs {
+		wg.Add(1)
+		go func(r *os.File, dst *[]byte) {
+			defer wg.Done()
+			*dst, _ = io.ReadAll(r)
+		}(readers[i], out.dst)
+	}
+	wg.Wait()
+
+	// FFmpeg outputs all detector info to stderr
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg analysis failed: %w (stderr: %s)", err, stderr.String())
+	}
+
+	run.log = stderr.String()
+	return run, nil
+}
+
+// parseInputDuration extracts the input duration from ffmpeg's header.
+// Format: Duration: 00:00:02.00, start: 1.400000, bitrate: 1234 kb/s
+func parseInputDuration(output string) (float64, bool) {
+	durationRegex := regexp.MustCompile(`Duration:\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+	match := durationRegex.FindStringSubmatch(output)
+	if len(match) < 4 {
+		return 0, false
+	}
+
+	hours, _ := strconv.ParseFloat(match[1], 64)
+	minutes, _ := strconv.ParseFloat(match[2], 64)
+	seconds, _ := strconv.ParseFloat(match[3], 64)
+	return hours*3600 + minutes*60 + seconds, true
+}
+
+// channelLayoutCounts maps ffmpeg channel layout names to channel counts.
+var channelLayoutCounts = map[string]int{
+	"mono":    1,
+	"stereo":  2,
+	"downmix": 2,
+	"2.1":     3,
+	"3.0":     3,
+	"4.0":     4,
+	"quad":    4,
+	"4.1":     5,
+	"5.0":     5,
+	"5.1":     6,
+	"6.0":     6,
+	"6.1":     7,
+	"7.0":     7,
+	"7.1":     8,
+}
+
+// parseAudioChannels extracts the channel count of the first audio stream
+// from ffmpeg's header. A file without audio reports 0 channels.
+// Format: Stream #0:1[0x101]: Audio: aac (LC), 48000 Hz, stereo, fltp, 128 kb/s
+func parseAudioChannels(output string) (int, bool) {
+	if !strings.Contains(output, "Stream #") {
+		return 0, false
+	}
+
+	audioRegex := regexp.MustCompile(`Stream #\d+:\d+.*: Audio: .*?, \d+ Hz, ([^,\n]+)`)
+	match := audioRegex.FindStringSubmatch(output)
+	if len(match) < 2 {
+		return 0, true
+	}
+
+	layout := strings.TrimSpace(match[1])
+	// "6 channels" or "2 channels (FL+FR)"
+	if fields := strings.Fields(layout); len(fields) >= 2 && strings.HasPrefix(fields[1], "channels") {
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			return n, true
+		}
+	}
+	// "5.1(side)" is still six channels
+	if idx := strings.Index(layout, "("); idx > 0 {
+		layout = layout[:idx]
+	}
+	if n, ok := channelLayoutCounts[layout]; ok {
+		return n, true
+	}
+
+	return 0, false
+}
+
+// parseVideoQuality extracts the resolution and frame rate of the first
+// video stream from ffmpeg's header.
+// Format: Stream #0:0[0x100]: Video: h264 (High), yuv420p(tv, bt709), 1920x1080 [SAR 1:1 DAR 16:9], 29.97 fps, 29.97 tbr, 90k tbn
+func parseVideoQuality(output string) *QualityResult {
+	result := &QualityResult{}
+
+	videoRegex := regexp.MustCompile(`Stream #\d+:\d+.*: Video: (.*)`)
+	match := videoRegex.FindStringSubmatch(output)
+	if len(match) < 2 {
+		return result
+	}
+	line := match[1]
+
+	if m := regexp.MustCompile(`\b(\d{2,5})x(\d{2,5})\b`).FindStringSubmatch(line); len(m) >= 3 {
+		result.Width, _ = strconv.Atoi(m[1])
+		result.Height, _ = strconv.Atoi(m[2])
+	}
+	// Prefer the stream's fps; fall back to tbr when fps is not printed.
+	if m := regexp.MustCompile(`([0-9.]+) fps`).FindStringSubmatch(line); len(m) >= 2 {
+		result.FrameRate, _ = strconv.ParseFloat(m[1], 64)
+	} else if m := regexp.MustCompile(`([0-9.]+) tbr`).FindStringSubmatch(line); len(m) >= 2 {
+		result.FrameRate, _ = strconv.ParseFloat(m[1], 64)
+	}
+
+	return result
+}
+
+// parseClosedCaptions reports whether the first video stream carries
+// closed captions. ffmpeg marks the stream "Closed Captions" in its input
+// header when it finds CEA-608/708 data (A/53 side data) while probing.
+func parseClosedCaptions(output string) *CaptionResult {
+	match := regexp.MustCompile(`Stream #\d+:\d+.*: Video: (.*)`).FindStringSubmatch(output)
+	if len(match) < 2 {
+		return nil
+	}
+	return &CaptionResult{Present: strings.Contains(match[1], "Closed Captions")}
+}
+
 // getDuration gets the duration of a media file using ffprobe.
 func (a *Analyzer) getDuration(ctx context.Context, filePath string) (float64, error) {
 	args := []string{
@@ -124,28 +559,109 @@ func (a *Analyzer) getDuration(ctx context.Context, filePath string) (float64, e
 	return duration, nil
 }
 
-// detectBlack detects black frames in a media file.
-func (a *Analyzer) detectBlack(ctx context.Context, filePath string, totalDuration float64) (*BlackDetectResult, error) {
-	// blackdetect parameters:
-	// d=0.1: minimum black duration to detect (100ms)
-	// pix_th=0.10: pixel threshold (0.0-1.0)
+// getAudioChannels returns the channel count of the first audio stream,
+// or 0 if the file has no audio.
+func (a *Analyzer) getAudioChannels(ctx context.Context, filePath string) (int, error) {
 	args := []string{
-		"-i", filePath,
-		"-vf", "blackdetect=d=0.1:pix_th=0.10",
-		"-an",
-		"-f", "null",
-		"-",
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=channels",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		filePath,
 	}
 
-	cmd := exec.CommandContext(ctx, a.ffmpegPath, args...)
-	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, a.ffprobePath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// FFmpeg outputs blackdetect info to stderr
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("ffmpeg blackdetect failed: %w (stderr: %s)", err, stderr.String())
+		return 0, fmt.Errorf("ffprobe failed: %w (stderr: %s)", err, stderr.String())
 	}
 
+	channelsStr := strings.TrimSpace(stdout.String())
+	if channelsStr == "" {
+		return 0, nil
+	}
+	channels, err := strconv.Atoi(channelsStr)
+	if err != nil {
+		return 0, fmt.Errorf("parse channels: %w", err)
+	}
+
+	return channels, nil
+}
+
+var (
+	videoFrameRegex = regexp.MustCompile(`\[Parsed_showinfo_\d+ @ [^\]]*\] n:\s*\d+ pts:\s*\S+ pts_time:(\S+) .*?duration_time:(\S+)`)
+	audioFrameRegex = regexp.MustCompile(`\[Parsed_ashowinfo_\d+ @ [^\]]*\] n:\s*\d+ pts:\s*\S+ pts_time:(\S+) .*? rate:(\d+) nb_samples:(\d+)`)
+)
+
+// parseFrameTimings compares the first and last decoded audio and video
+// frames logged by showinfo and ashowinfo, which runAnalysis attaches when
+// MeasureAVSync is set.
+// Format: [Parsed_showinfo_0 @ 0x5581] n:   0 pts: 126000 pts_time:1.4     duration:   3003 duration_time:0.03337 fmt:yuv420p ...
+// Format: [Parsed_ashowinfo_0 @ 0x5582] n:0 pts:136800 pts_time:1.52 fmt:fltp channels:2 chlayout:stereo rate:48000 nb_samples:1024 ...
+func parseFrameTimings(output string) *AVSyncResult {
+	type timing struct {
+		start, end float64
+		ok         bool
+	}
+	var video, audio timing
+	extend := func(t *timing, pts, duration float64) {
+		if !t.ok {
+			t.start, t.end, t.ok = pts, pts+duration, true
+			return
+		}
+		t.end = math.Max(t.end, pts+duration)
+	}
+
+	for _, match := range videoFrameRegex.FindAllStringSubmatch(output, -1) {
+		pts, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			// pts_time is "NOPTS" for frames without a timestamp.
+			continue
+		}
+		duration, _ := strconv.ParseFloat(match[2], 64)
+		extend(&video, pts, duration)
+	}
+	for _, match := range audioFrameRegex.FindAllStringSubmatch(output, -1) {
+		pts, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			continue
+		}
+		rate, _ := strconv.ParseFloat(match[2], 64)
+		samples, _ := strconv.ParseFloat(match[3], 64)
+		var duration float64
+		if rate > 0 {
+			duration = samples / rate
+		}
+		extend(&audio, pts, duration)
+	}
+
+	result := &AVSyncResult{}
+	if !video.ok || !audio.ok {
+		return result
+	}
+	result.Measured = true
+	result.VideoStart = video.start
+	result.AudioStart = audio.start
+	result.VideoDuration = video.end - video.start
+	result.AudioDuration = audio.end - audio.start
+	result.StartOffsetMs = (audio.start - video.start) * 1000
+	result.EndOffsetMs = result.StartOffsetMs
+	if result.VideoDuration > 0 && result.AudioDuration > 0 {
+		result.EndOffsetMs = (audio.end - video.end) * 1000
+	}
+	result.OffsetMs = result.StartOffsetMs
+	if math.Abs(result.EndOffsetMs) > math.Abs(result.StartOffsetMs) {
+		result.OffsetMs = result.EndOffsetMs
+	}
+	return result
+}
+
+// parseBlackOutput parses blackdetect output into a BlackDetectResult.
+// A segment is fully black when its black ratio exceeds ratioThreshold.
+func parseBlackOutput(output string, totalDuration, ratioThreshold float64) *BlackDetectResult {
 	result := &BlackDetectResult{
 		TotalDuration: totalDuration,
 	}
@@ -153,7 +669,7 @@ func (a *Analyzer) detectBlack(ctx context.Context, filePath string, totalDurati
 	// Parse blackdetect output
 	// Format: [blackdetect @ 0x...] black_start:0 black_end:2.0 black_duration:2.0
 	blackRegex := regexp.MustCompile(`black_start:([0-9.]+)\s+black_end:([0-9.]+)\s+black_duration:([0-9.]+)`)
-	matches := blackRegex.FindAllStringSubmatch(stderr.String(), -1)
+	matches := blackRegex.FindAllStringSubmatch(output, -1)
 
 	var totalBlackDuration float64
 	var firstBlackStart float64 = -1
@@ -178,35 +694,58 @@ func (a *Analyzer) detectBlack(ctx context.Context, filePath string, totalDurati
 
 	if totalDuration > 0 {
 		result.BlackRatio = totalBlackDuration / totalDuration
-		// Consider fully black if >90% is black
-		result.FullyBlack = result.BlackRatio > 0.9
+		result.FullyBlack = result.BlackRatio > ratioThreshold
 	}
 
-	return result, nil
+	return result
 }
 
-// detectSilence detects silence in a media file.
-func (a *Analyzer) detectSilence(ctx context.Context, filePath string, totalDuration float64) (*SilenceDetectResult, error) {
-	// silencedetect parameters:
-	// n=-50dB: noise threshold
-	// d=0.5: minimum silence duration
-	args := []string{
-		"-i", filePath,
-		"-af", fmt.Sprintf("silencedetect=n=%gdB:d=0.5", a.silenceDBThreshold),
-		"-vn",
-		"-f", "null",
-		"-",
+// mixedSilenceOutput returns the silencedetect output of the mixdown
+// detector, dropping the per-channel (mono=1) detector's lines.
+func mixedSilenceOutput(output string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "silence_") && !strings.Contains(line, "channel:") {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
 	}
+	return b.String()
+}
 
-	cmd := exec.CommandContext(ctx, a.ffmpegPath, args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// parseChannelSilenceOutput splits per-channel silencedetect output by
+// channel and parses each channel on its own.
+// Format: [silencedetect @ 0x...] channel: 1 | silence_start: 0
+// Format: [silencedetect @ 0x...] channel: 1 | silence_end: 2.0 | silence_duration: 2.0
+func parseChannelSilenceOutput(output string, totalDuration float64, channels int, ratioThreshold float64) []ChannelSilenceResult {
+	channelRegex := regexp.MustCompile(`channel:\s*(\d+)\s*\|\s*(silence_.*)`)
 
-	// FFmpeg outputs silencedetect info to stderr
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("ffmpeg silencedetect failed: %w (stderr: %s)", err, stderr.String())
+	perChannel := make([]strings.Builder, channels)
+	for _, match := range channelRegex.FindAllStringSubmatch(output, -1) {
+		ch, err := strconv.Atoi(match[1])
+		if err != nil || ch < 0 || ch >= channels {
+			continue
+		}
+		perChannel[ch].WriteString(match[2])
+		perChannel[ch].WriteString("\n")
 	}
 
+	results := make([]ChannelSilenceResult, channels)
+	for ch := range perChannel {
+		parsed := parseSilenceOutput(perChannel[ch].String(), totalDuration, ratioThreshold)
+		results[ch] = ChannelSilenceResult{
+			Channel:         ch,
+			SilenceDuration: parsed.SilenceDuration,
+			SilenceRatio:    parsed.SilenceRatio,
+			FullySilent:     parsed.FullySilent,
+		}
+	}
+	return results
+}
+
+// parseSilenceOutput parses silencedetect output into a SilenceDetectResult.
+// A segment is fully silent when its silence ratio exceeds ratioThreshold.
+func parseSilenceOutput(output string, totalDuration, ratioThreshold float64) *SilenceDetectResult {
 	result := &SilenceDetectResult{
 		TotalDuration: totalDuration,
 	}
@@ -217,9 +756,8 @@ func (a *Analyzer) detectSilence(ctx context.Context, filePath string, totalDura
 	startRegex := regexp.MustCompile(`silence_start:\s*([0-9.]+)`)
 	endRegex := regexp.MustCompile(`silence_end:\s*([0-9.]+)\s*\|\s*silence_duration:\s*([0-9.]+)`)
 
-	stderrStr := stderr.String()
-	startMatches := startRegex.FindAllStringSubmatch(stderrStr, -1)
-	endMatches := endRegex.FindAllStringSubmatch(stderrStr, -1)
+	startMatches := startRegex.FindAllStringSubmatch(output, -1)
+	endMatches := endRegex.FindAllStringSubmatch(output, -1)
 
 	var totalSilenceDuration float64
 	var firstSilenceStart float64 = -1
@@ -262,30 +800,225 @@ func (a *Analyzer) detectSilence(ctx context.Context, filePath string, totalDura
 
 	if totalDuration > 0 {
 		result.SilenceRatio = totalSilenceDuration / totalDuration
-		// Consider fully silent if >90% is silent
-		result.FullySilent = result.SilenceRatio > 0.9
+		result.FullySilent = result.SilenceRatio > ratioThreshold
+	}
+
+	return result
+}
+
+// parseFreezeOutput parses freezedetect output into a FreezeDetectResult.
+// ratioThreshold is the frozen share above which the segment is fully frozen.
+func parseFreezeOutput(output string, totalDuration, ratioThreshold float64) *FreezeDetectResult {
+	result := &FreezeDetectResult{
+		TotalDuration: totalDuration,
+	}
+
+	// Parse freezedetect output
+	// Format: [freezedetect @ 0x...] lavfi.freezedetect.freeze_start: 0.5
+	// Format: [freezedetect @ 0x...] lavfi.freezedetect.freeze_duration: 2.0
+	// Format: [freezedetect @ 0x...] lavfi.freezedetect.freeze_end: 2.5
+	startRegex := regexp.MustCompile(`freeze_start:\s*([0-9.]+)`)
+	durationRegex := regexp.MustCompile(`freeze_duration:\s*([0-9.]+)`)
+
+	startMatches := startRegex.FindAllStringSubmatch(output, -1)
+	durationMatches := durationRegex.FindAllStringSubmatch(output, -1)
+
+	var totalFreezeDuration float64
+	var firstFreezeStart float64 = -1
+
+	for _, match := range startMatches {
+		if len(match) >= 2 {
+			result.HasFreeze = true
+			freezeStart, _ := strconv.ParseFloat(match[1], 64)
+			if firstFreezeStart < 0 {
+				firstFreezeStart = freezeStart
+			}
+		}
+	}
+
+	for _, match := range durationMatches {
+		if len(match) >= 2 {
+			freezeDuration, _ := strconv.ParseFloat(match[1], 64)
+			totalFreezeDuration += freezeDuration
+		}
+	}
+
+	// Handle case where the freeze continues to end of file (no freeze_end)
+	if len(startMatches) > len(durationMatches) && len(startMatches) > 0 {
+		lastStartMatch := startMatches[len(startMatches)-1]
+		if len(lastStartMatch) >= 2 {
+			lastStart, _ := strconv.ParseFloat(lastStartMatch[1], 64)
+			remainingFreeze := totalDuration - lastStart
+			if remainingFreeze > 0 {
+				totalFreezeDuration += remainingFreeze
+			}
+		}
+	}
+
+	result.FreezeDuration = totalFreezeDuration
+	if firstFreezeStart >= 0 {
+		result.FreezeStartTime = firstFreezeStart
+	}
+
+	if totalDuration > 0 {
+		result.FreezeRatio = totalFreezeDuration / totalDuration
+		// Consider fully frozen if >90% is frozen
+		result.FullyFrozen = result.FreezeRatio > ratioThreshold
+	}
+
+	return result
+}
+
+// ebur128AbsoluteGateLUFS is the EBU R128 absolute gate. ebur128 reports
+// exactly this value as the integrated loudness when every block was gated
+// out, which means "no measurable programme audio" rather than a real level.
+const ebur128AbsoluteGateLUFS = -70.0
+
+// parseLoudnessSummary parses the summary block ebur128 prints at the end
+// of the run:
+//
+//	[Parsed_ebur128_0 @ 0x...] Summary:
+//	  Integrated loudness:
+//	    I:         -23.0 LUFS
+//	    Threshold: -33.0 LUFS
+//	  ...
+//	  True peak:
+//	    Peak:       -1.0 dBFS
+func parseLoudnessSummary(output string, totalDuration float64) *LoudnessResult {
+	result := &LoudnessResult{
+		TotalDuration: totalDuration,
+		TruePeakDBFS:  math.Inf(-1),
+	}
+
+	// Per-frame log lines also contain "I: ... LUFS"; only the summary is
+	// the integrated value for the whole file.
+	idx := strings.LastIndex(output, "Summary:")
+	if idx < 0 {
+		return result
 	}
+	summary := output[idx:]
+
+	integratedRegex := regexp.MustCompile(`I:\s*(-?[0-9.]+)\s*LUFS`)
+	peakRegex := regexp.MustCompile(`Peak:\s*(-?[0-9.]+|-inf)\s*dBFS`)
 
-	return result, nil
+	if match := integratedRegex.FindStringSubmatch(summary); len(match) >= 2 {
+		integrated, err := strconv.ParseFloat(match[1], 64)
+		if err == nil && integrated > ebur128AbsoluteGateLUFS {
+			result.Measured = true
+			result.IntegratedLUFS = integrated
+		}
+	}
+	if match := peakRegex.FindStringSubmatch(summary); len(match) >= 2 && match[1] != "-inf" {
+		if peak, err := strconv.ParseFloat(match[1], 64); err == nil {
+			result.TruePeakDBFS = peak
+		}
+	}
+
+	return result
+}
+
+// ExtractThumbnail returns the first keyframe of a segment as a JPEG.
+func (a *Analyzer) ExtractThumbnail(ctx context.Context, segmentPath string) ([]byte, error) {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-skip_frame", "nokey",
+		"-i", segmentPath,
+		"-an",
+		"-frames:v", "1",
+		"-q:v", "3",
+		"-f", "image2pipe",
+		"-c:v", "mjpeg",
+		"-",
+	}
+	return a.runToStdout(ctx, args, "thumbnail")
+}
+
+// ExtractAudioClip returns up to duration of a segment's audio as ADTS AAC.
+func (a *Analyzer) ExtractAudioClip(ctx context.Context, segmentPath string, duration time.Duration) ([]byte, error) {
+	args := []string{
+		"-hide_banner",
+		"-loglevel", "error",
+		"-i", segmentPath,
+		"-vn",
+		"-t", strconv.FormatFloat(duration.Seconds(), 'f', -1, 64),
+		"-c:a", "aac",
+		"-f", "adts",
+		"-",
+	}
+	return a.runToStdout(ctx, args, "audio clip")
+}
+
+// runToStdout runs ffmpeg and returns what it wrote to stdout.
+func (a *Analyzer) runToStdout(ctx context.Context, args []string, what string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, a.ffmpegPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg %s failed: %w (stderr: %s)", what, err, stderr.String())
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("ffmpeg %s produced no output", what)
+	}
+	return stdout.Bytes(), nil
 }
 
 // SaveSegment saves segment data to a temporary file and returns the path.
-func (a *Analyzer) SaveSegment(monitorID string, data []byte) (string, error) {
+// When init is non-empty it is written first, so that fragmented MP4
+// segments can be decoded on their own. The file extension follows the
+// container, which ffmpeg uses when probing.
+func (a *Analyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
 	segmentDir := filepath.Join(a.tmpDir, monitorID)
 	if err := os.MkdirAll(segmentDir, 0755); err != nil {
 		return "", fmt.Errorf("create segment dir: %w", err)
 	}
 
-	filename := fmt.Sprintf("segment_%d.ts", time.Now().UnixNano())
+	filename := fmt.Sprintf("segment_%d%s", time.Now().UnixNano(), segmentExtension(init, data))
 	segmentPath := filepath.Join(segmentDir, filename)
 
-	if err := os.WriteFile(segmentPath, data, 0644); err != nil {
+	file, err := os.OpenFile(segmentPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", fmt.Errorf("write segment: %w", err)
+	}
+	_, err = file.Write(init)
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(segmentPath)
 		return "", fmt.Errorf("write segment: %w", err)
 	}
 
 	return segmentPath, nil
 }
 
+// segmentExtension returns ".mp4" for fragmented MP4 (an init segment, or
+// data starting with an ISO BMFF box) and ".ts" for MPEG-TS.
+func segmentExtension(init, data []byte) string {
+	if len(init) > 0 || isISOBMFF(data) {
+		return ".mp4"
+	}
+	return ".ts"
+}
+
+// isISOBMFF reports whether data starts with a box a CMAF segment can begin
+// with.
+func isISOBMFF(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+	switch string(data[4:8]) {
+	case "ftyp", "styp", "moov", "moof", "sidx", "emsg", "prft":
+		return true
+	}
+	return false
+}
+
 // CleanupSegment removes a segment file.
 func (a *Analyzer) CleanupSegment(segmentPath string) error {
 	return os.Remove(segmentPath)
@@ -0,0 +1,365 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseChannelSilenceOutput(t *testing.T) {
+	output := `[silencedetect @ 0x55d0] channel: 1 | silence_start: 0
+[silencedetect @ 0x55d0] channel: 0 | silence_start: 1.5
+[silencedetect @ 0x55d0] channel: 0 | silence_end: 2 | silence_duration: 0.5
+`
+	results := parseChannelSilenceOutput(output, 4.0, 2, 0.9)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 channel results, got %d", len(results))
+	}
+	if results[0].FullySilent {
+		t.Fatalf("expected channel 0 not to be fully silent")
+	}
+	if results[0].SilenceRatio != 0.125 {
+		t.Fatalf("channel 0 SilenceRatio = %v, want 0.125", results[0].SilenceRatio)
+	}
+	if !results[1].FullySilent {
+		t.Fatalf("expected channel 1 to be fully silent")
+	}
+	if results[1].SilenceDuration != 4.0 {
+		t.Fatalf("channel 1 SilenceDuration = %v, want 4", results[1].SilenceDuration)
+	}
+}
+
+func TestParseLoudnessSummary(t *testing.T) {
+	output := `[Parsed_ebur128_0 @ 0x55d0] t: 1.9         TARGET:-23 LUFS    M: -18.2 S:-120.7     I: -18.4 LUFS       LRA:   0.0 LU  FTPK: -3.1 dBFS  TPK: -3.1 dBFS
+[Parsed_ebur128_0 @ 0x55d0] Summary:
+
+  Integrated loudness:
+    I:         -16.9 LUFS
+    Threshold: -27.1 LUFS
+
+  Loudness range:
+    LRA:         0.8 LU
+    Threshold: -37.1 LUFS
+    LRA low:   -17.4 LUFS
+    LRA high:  -16.6 LUFS
+
+  True peak:
+    Peak:       -1.2 dBFS
+`
+	result := parseLoudnessSummary(output, 2.0)
+	if !result.Measured {
+		t.Fatalf("expected Measured to be true")
+	}
+	if result.IntegratedLUFS != -16.9 {
+		t.Fatalf("IntegratedLUFS = %v, want -16.9", result.IntegratedLUFS)
+	}
+	if result.TruePeakDBFS != -1.2 {
+		t.Fatalf("TruePeakDBFS = %v, want -1.2", result.TruePeakDBFS)
+	}
+}
+
+func TestParseLoudnessSummarySilence(t *testing.T) {
+	output := `[Parsed_ebur128_0 @ 0x55d0] Summary:
+
+  Integrated loudness:
+    I:         -70.0 LUFS
+    Threshold:   0.0 LUFS
+
+  True peak:
+    Peak:       -inf dBFS
+`
+	result := parseLoudnessSummary(output, 2.0)
+	if result.Measured {
+		t.Fatalf("expected Measured to be false for gated-out audio")
+	}
+	if !math.IsInf(result.TruePeakDBFS, -1) {
+		t.Fatalf("TruePeakDBFS = %v, want -Inf", result.TruePeakDBFS)
+	}
+}
+
+// combinedOutput is trimmed stderr of a single-pass analysis run on a
+// stereo segment whose right channel is dead.
+const combinedOutput = `Input #0, mpegts, from 'segment.ts':
+  Duration: 00:00:04.00, start: 1.400000, bitrate: 1043 kb/s
+  Program 1
+    Stream #0:0[0x100]: Video: h264 (Main) ([27][0][0][0] / 0x001B), yuv420p(tv, bt709), 640x360, 30 fps, 30 tbr, 90k tbn
+    Stream #0:1[0x101]: Audio: aac (LC) ([15][0][0][0] / 0x000F), 48000 Hz, stereo, fltp, 128 kb/s
+[blackdetect @ 0x55d1] black_start:0 black_end:1 black_duration:1
+[silencedetect @ 0x55d2] channel: 1 | silence_start: 0
+[freezedetect @ 0x55d3] lavfi.freezedetect.freeze_start: 0.5
+[freezedetect @ 0x55d3] lavfi.freezedetect.freeze_duration: 1.5
+[freezedetect @ 0x55d3] lavfi.freezedetect.freeze_end: 2
+[silencedetect @ 0x55d4] silence_start: 3
+[Parsed_ebur128_4 @ 0x55d5] Summary:
+
+  Integrated loudness:
+    I:         -23.0 LUFS
+    Threshold: -33.2 LUFS
+
+  True peak:
+    Peak:       -3.5 dBFS
+`
+
+func TestParseInputDuration(t *testing.T) {
+	duration, ok := parseInputDuration(combinedOutput)
+	if !ok {
+		t.Fatalf("expected duration to be parsed")
+	}
+	if duration != 4.0 {
+		t.Fatalf("duration = %v, want 4", duration)
+	}
+
+	if _, ok := parseInputDuration("  Duration: N/A, start: 0.000000, bitrate: N/A\n"); ok {
+		t.Fatalf("expected N/A duration not to be parsed")
+	}
+}
+
+func TestParseAudioChannels(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   int
+		wantOK bool
+	}{
+		{"stereo", combinedOutput, 2, true},
+		{"mono", "    Stream #0:1: Audio: aac (LC), 44100 Hz, mono, fltp\n", 1, true},
+		{"5.1 side", "    Stream #0:1: Audio: ac3, 48000 Hz, 5.1(side), fltp, 384 kb/s\n", 6, true},
+		{"channel count", "    Stream #0:1: Audio: pcm_s16le, 48000 Hz, 3 channels, s16\n", 3, true},
+		{"no audio", "    Stream #0:0: Video: h264 (Main), yuv420p, 640x360\n", 0, true},
+		{"unknown layout", "    Stream #0:1: Audio: opus, 48000 Hz, ambisonic 1, fltp\n", 0, false},
+		{"no streams", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseAudioChannels(tt.output)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("parseAudioChannels() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseCombinedOutput(t *testing.T) {
+	black := parseBlackOutput(combinedOutput, 4.0, 0.9)
+	if !black.HasBlackFrames || black.BlackDuration != 1 {
+		t.Fatalf("black = %+v, want 1s of black", black)
+	}
+
+	freeze := parseFreezeOutput(combinedOutput, 4.0, 0.9)
+	if !freeze.HasFreeze || freeze.FreezeDuration != 1.5 {
+		t.Fatalf("freeze = %+v, want 1.5s of freeze", freeze)
+	}
+
+	// The per-channel detector's lines must not leak into the mixdown result.
+	silence := parseSilenceOutput(mixedSilenceOutput(combinedOutput), 4.0, 0.9)
+	if silence.SilenceDuration != 1 || silence.SilenceStartTime != 3 {
+		t.Fatalf("silence = %+v, want 1s starting at 3", silence)
+	}
+
+	channels := parseChannelSilenceOutput(combinedOutput, 4.0, 2, 0.9)
+	if channels[0].FullySilent || !channels[1].FullySilent {
+		t.Fatalf("channels = %+v, want only channel 1 fully silent", channels)
+	}
+
+	loudness := parseLoudnessSummary(combinedOutput, 4.0)
+	if !loudness.Measured || loudness.IntegratedLUFS != -23.0 {
+		t.Fatalf("loudness = %+v, want -23 LUFS", loudness)
+	}
+}
+
+func TestParseBlackOutputRatioThreshold(t *testing.T) {
+	output := "[blackdetect @ 0x55d1] black_start:0 black_end:3 black_duration:3\n"
+
+	if result := parseBlackOutput(output, 4.0, 0.9); result.FullyBlack {
+		t.Fatalf("expected 75%% black not to be fully black at ratio 0.9")
+	}
+	if result := parseBlackOutput(output, 4.0, 0.7); !result.FullyBlack {
+		t.Fatalf("expected 75%% black to be fully black at ratio 0.7")
+	}
+}
+
+func TestParseVideoQuality(t *testing.T) {
+	quality := parseVideoQuality(combinedOutput)
+	if quality.Width != 640 || quality.Height != 360 {
+		t.Fatalf("resolution = %dx%d, want 640x360", quality.Width, quality.Height)
+	}
+	if quality.FrameRate != 30 {
+		t.Fatalf("FrameRate = %v, want 30", quality.FrameRate)
+	}
+
+	tbrOnly := "    Stream #0:0: Video: h264 (High), yuv420p(progressive), 1920x1080 [SAR 1:1 DAR 16:9], 59.94 tbr, 90k tbn\n"
+	quality = parseVideoQuality(tbrOnly)
+	if quality.Height != 1080 || quality.FrameRate != 59.94 {
+		t.Fatalf("quality = %+v, want 1080p at 59.94", quality)
+	}
+
+	audioOnly := "    Stream #0:0: Audio: aac (LC), 48000 Hz, stereo, fltp\n"
+	if quality := parseVideoQuality(audioOnly); quality.Height != 0 || quality.FrameRate != 0 {
+		t.Fatalf("expected zero quality for audio-only output, got %+v", quality)
+	}
+}
+
+func TestParseFrameTimings(t *testing.T) {
+	output := `[Parsed_showinfo_0 @ 0x55d1c0a0] n:   0 pts: 126000 pts_time:1.4     duration:   3003 duration_time:0.03337 fmt:yuv420p cl:left sar:1/1 s:1920x1080 i:P iskey:1 type:I
+[Parsed_ashowinfo_0 @ 0x55d1c0b0] n:0 pts:72960 pts_time:1.52 fmt:fltp channels:2 chlayout:stereo rate:48000 nb_samples:1024 checksum:00000000 plane_checksums: [ 00000000 00000000 ]
+[Parsed_showinfo_0 @ 0x55d1c0a0] n:  59 pts: 303177 pts_time:3.36863 duration:   3003 duration_time:0.03337 fmt:yuv420p cl:left sar:1/1 s:1920x1080 i:P iskey:0 type:P
+[Parsed_ashowinfo_0 @ 0x55d1c0b0] n:95 pts:167040 pts_time:3.48 fmt:fltp channels:2 chlayout:stereo rate:48000 nb_samples:1024 checksum:00000000 plane_checksums: [ 00000000 00000000 ]
+`
+	result := parseFrameTimings(output)
+	if !result.Measured {
+		t.Fatalf("expected Measured to be true")
+	}
+	if math.Abs(result.StartOffsetMs-120) > 1e-6 {
+		t.Fatalf("StartOffsetMs = %v, want 120", result.StartOffsetMs)
+	}
+	// Video ends at 3.36863+0.03337=3.402, audio at 3.48+1024/48000.
+	wantEnd := (3.48 + 1024.0/48000 - 3.402) * 1000
+	if math.Abs(result.EndOffsetMs-wantEnd) > 1e-6 {
+		t.Fatalf("EndOffsetMs = %v, want %v", result.EndOffsetMs, wantEnd)
+	}
+	if result.OffsetMs != result.StartOffsetMs {
+		t.Fatalf("OffsetMs = %v, want the larger start offset %v", result.OffsetMs, result.StartOffsetMs)
+	}
+}
+
+func TestParseFrameTimingsSkipsFramesWithoutTimestamps(t *testing.T) {
+	output := `[Parsed_showinfo_0 @ 0x1] n:   0 pts:NOPTS pts_time:NOPTS duration:      0 duration_time:0       fmt:yuv420p
+[Parsed_showinfo_0 @ 0x1] n:   1 pts: 900000 pts_time:10      duration:      0 duration_time:0       fmt:yuv420p
+[Parsed_ashowinfo_0 @ 0x2] n:0 pts:477600 pts_time:9.95 fmt:fltp channels:2 chlayout:stereo rate:48000 nb_samples:0 checksum:00000000
+`
+	result := parseFrameTimings(output)
+	if !result.Measured {
+		t.Fatalf("expected Measured to be true")
+	}
+	if math.Abs(result.OffsetMs+50) > 1e-6 || result.EndOffsetMs != result.StartOffsetMs {
+		t.Fatalf("OffsetMs = %v, EndOffsetMs = %v, want -50 for both", result.OffsetMs, result.EndOffsetMs)
+	}
+}
+
+func TestParseFrameTimingsAudioOnly(t *testing.T) {
+	result := parseFrameTimings("[Parsed_ashowinfo_0 @ 0x2] n:0 pts:0 pts_time:0 fmt:fltp channels:2 chlayout:stereo rate:48000 nb_samples:1024 checksum:00000000\n")
+	if result.Measured {
+		t.Fatalf("expected Measured to be false without video frames")
+	}
+	if result := parseFrameTimings(combinedOutput); result.Measured {
+		t.Fatalf("expected Measured to be false without showinfo output")
+	}
+}
+
+func TestParseClosedCaptions(t *testing.T) {
+	withCaptions := "    Stream #0:0[0x100]: Video: h264 (High) ([27][0][0][0] / 0x001B), yuv420p(tv, bt709, progressive), 1920x1080 [SAR 1:1 DAR 16:9], Closed Captions, 29.97 fps, 29.97 tbr, 90k tbn\n"
+	if result := parseClosedCaptions(withCaptions); result == nil || !result.Present {
+		t.Fatalf("expected captions to be present, got %+v", result)
+	}
+	if result := parseClosedCaptions(combinedOutput); result == nil || result.Present {
+		t.Fatalf("expected captions to be absent, got %+v", result)
+	}
+	audioOnly := "    Stream #0:0: Audio: aac (LC), 48000 Hz, stereo, fltp\n"
+	if result := parseClosedCaptions(audioOnly); result != nil {
+		t.Fatalf("expected nil for audio-only output, got %+v", result)
+	}
+}
+
+func TestAnalyzeSegmentSinglePass(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args.log")
+	// The fake ffmpeg logs its arguments, prints an input header and writes
+	// two still frames and two seconds of PCM to the sample pipes.
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$@" >> %q
+cat >&2 <<'HEADER'
+Input #0, mpegts, from 'segment.ts':
+  Duration: 00:00:02.00, start: 1.400000, bitrate: 1000 kb/s
+  Stream #0:0[0x100]: Video: h264 (High), yuv420p, 64x36, 30 fps, 30 tbr, 90k tbn
+  Stream #0:1[0x101]: Audio: aac (LC), 48000 Hz, mono, fltp, 128 kb/s
+HEADER
+head -c %d /dev/zero >&3
+head -c %d /dev/zero >&4
+`, argsLog, 2*stillFrameBytes, 2*2*toneSampleRate)
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(ffmpegPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	segmentPath := filepath.Join(dir, "segment.ts")
+	if err := os.WriteFile(segmentPath, []byte("ts"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, measure := range []bool{false, true} {
+		os.Remove(argsLog)
+		// A missing ffprobe proves no probe runs.
+		analyzer := NewAnalyzer(ffmpegPath, filepath.Join(dir, "missing-ffprobe"), dir, DetectionParams{
+			SlateMaxMotion: 0.01,
+			ToneFrequency:  1000,
+			MeasureAVSync:  measure,
+		})
+		result, err := analyzer.AnalyzeSegment(context.Background(), segmentPath)
+		if err != nil {
+			t.Fatalf("AnalyzeSegment error: %v", err)
+		}
+		if result.Still == nil || result.Still.Frames != 2 || !result.Still.LowMotion {
+			t.Fatalf("Still = %+v, want two low-motion frames", result.Still)
+		}
+		if result.Tone == nil || result.Tone.TotalDuration != 2 {
+			t.Fatalf("Tone = %+v, want two seconds analyzed", result.Tone)
+		}
+
+		data, err := os.ReadFile(argsLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(runs) != 1 {
+			t.Fatalf("ffmpeg ran %d times, want 1: %q", len(runs), runs)
+		}
+		if !strings.Contains(runs[0], "pipe:3") || !strings.Contains(runs[0], "pipe:4") {
+			t.Fatalf("args = %q, want still and tone sample outputs", runs[0])
+		}
+		if got := strings.Contains(runs[0], "showinfo"); got != measure {
+			t.Fatalf("MeasureAVSync %v: showinfo attached = %v", measure, got)
+		}
+	}
+}
+
+func TestSaveSegmentJoinsInitSegment(t *testing.T) {
+	analyzer := NewAnalyzer("ffmpeg", "ffprobe", t.TempDir(), DetectionParams{})
+	init := []byte("\x00\x00\x00\x08ftyp")
+	media := []byte("\x00\x00\x00\x08moof")
+
+	path, err := analyzer.SaveSegment("monitor", init, media)
+	if err != nil {
+		t.Fatalf("SaveSegment error: %v", err)
+	}
+	if filepath.Ext(path) != ".mp4" {
+		t.Fatalf("extension = %s, want .mp4", filepath.Ext(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read segment: %v", err)
+	}
+	if string(data) != string(init)+string(media) {
+		t.Fatalf("segment = %q, want init followed by media", data)
+	}
+
+	path, err = analyzer.SaveSegment("monitor", nil, []byte{0x47, 0x40, 0x00, 0x10, 0x00, 0x00, 0xb0, 0x0d})
+	if err != nil {
+		t.Fatalf("SaveSegment error: %v", err)
+	}
+	if filepath.Ext(path) != ".ts" {
+		t.Fatalf("extension = %s, want .ts", filepath.Ext(path))
+	}
+}
+
+func TestSegmentExtension(t *testing.T) {
+	if ext := segmentExtension(nil, []byte("\x00\x00\x00\x18styp")); ext != ".mp4" {
+		t.Fatalf("styp segment extension = %s, want .mp4", ext)
+	}
+	if ext := segmentExtension(nil, []byte{0x47}); ext != ".ts" {
+		t.Fatalf("short segment extension = %s, want .ts", ext)
+	}
+}
@@ -0,0 +1,169 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG for reference images
+	_ "image/png"  // register PNG for reference images
+	"math"
+	"strconv"
+)
+
+// Still-image detection compares small RGB thumbnails rather than full
+// frames: slates and test cards differ from programme video in their
+// overall layout, not in fine detail.
+const (
+	stillFrameWidth  = 64
+	stillFrameHeight = 36
+	stillFrameBytes  = stillFrameWidth * stillFrameHeight * 3
+	// stillSampleRate and stillMaxFrames bound the frames sampled per segment.
+	stillSampleRate = 2
+	stillMaxFrames  = 8
+)
+
+// SlateReference is a still image, such as SMPTE colour bars or a "we'll be
+// right back" slate, that video frames are matched against.
+type SlateReference struct {
+	Name  string
+	Image image.Image
+}
+
+// DecodeSlateReference decodes a PNG or JPEG reference image.
+func DecodeSlateReference(name string, data []byte) (SlateReference, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return SlateReference{}, fmt.Errorf("decode slate reference %q: %w", name, err)
+	}
+	return SlateReference{Name: name, Image: img}, nil
+}
+
+// StillImageResult contains the result of still-image (slate) detection.
+type StillImageResult struct {
+	// Frames is the number of frames sampled from the segment.
+	Frames int
+	// Motion is the largest mean absolute difference between consecutive
+	// sampled frames, from 0 (identical) to 1.
+	Motion float64
+	// LowMotion is true when Motion stayed at or below the configured
+	// maximum. It is always false when low-motion detection is disabled.
+	LowMotion bool
+	// MatchedReference is the name of the best matching reference whose
+	// similarity reached the match threshold, or empty when none did.
+	MatchedReference string
+	// Similarity is the best reference similarity, from 0 to 1.
+	Similarity float64
+}
+
+// IsSlate reports whether the segment looks like a slate or test card.
+func (r *StillImageResult) IsSlate() bool {
+	return r != nil && (r.LowMotion || r.MatchedReference != "")
+}
+
+// stillReference is a reference image reduced to a still frame.
+type stillReference struct {
+	name   string
+	pixels []byte
+}
+
+// stillDetectionEnabled reports whether the analyzer samples frames for
+// still-image detection.
+func (a *Analyzer) stillDetectionEnabled() bool {
+	return len(a.slateRefs) > 0 || a.params.SlateMaxMotion > 0
+}
+
+// stillOutputArgs returns the options of the analysis output that writes up
+// to stillMaxFrames frames of the first video stream, scaled to
+// stillFrameWidth x stillFrameHeight RGB24.
+func stillOutputArgs() []string {
+	return []string{
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=%d,scale=%d:%d", stillSampleRate, stillFrameWidth, stillFrameHeight),
+		"-frames:v", strconv.Itoa(stillMaxFrames),
+		"-f", "rawvideo",
+		"-pix_fmt", "rgb24",
+	}
+}
+
+// splitStillFrames splits the output of stillOutputArgs into frames.
+func splitStillFrames(raw []byte) [][]byte {
+	var frames [][]byte
+	for len(raw) >= stillFrameBytes {
+		frames = append(frames, raw[:stillFrameBytes])
+		raw = raw[stillFrameBytes:]
+	}
+	return frames
+}
+
+// detectStill scores sampled frames for motion and reference similarity.
+// maxMotion of 0 disables low-motion detection.
+func detectStill(frames [][]byte, refs []stillReference, maxMotion, matchThreshold float64) *StillImageResult {
+	result := &StillImageResult{Frames: len(frames)}
+	if len(frames) == 0 {
+		return result
+	}
+
+	for i := 1; i < len(frames); i++ {
+		result.Motion = math.Max(result.Motion, frameDifference(frames[i-1], frames[i]))
+	}
+	// A single frame says nothing about motion.
+	result.LowMotion = maxMotion > 0 && len(frames) > 1 && result.Motion <= maxMotion
+
+	for _, ref := range refs {
+		var total float64
+		for _, frame := range frames {
+			total += 1 - frameDifference(frame, ref.pixels)
+		}
+		similarity := total / float64(len(frames))
+		if similarity > result.Similarity {
+			result.Similarity = similarity
+			if similarity >= matchThreshold {
+				result.MatchedReference = ref.name
+			}
+		}
+	}
+	return result
+}
+
+// frameDifference returns the mean absolute difference of two equally
+// sized RGB24 frames, normalised to 0-1.
+func frameDifference(a, b []byte) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 1
+	}
+	var sum int
+	for i := range a {
+		d := int(a[i]) - int(b[i])
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return float64(sum) / float64(len(a)) / 255
+}
+
+// stillFramePixels box-filters img down to a still frame in RGB24.
+func stillFramePixels(img image.Image) []byte {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, stillFrameBytes)
+	for y := 0; y < stillFrameHeight; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/stillFrameHeight
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/stillFrameHeight, y0+1)
+		for x := 0; x < stillFrameWidth; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/stillFrameWidth
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/stillFrameWidth, x0+1)
+			var r, g, b, n uint64
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					cr, cg, cb, _ := img.At(px, py).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					n++
+				}
+			}
+			pixels = append(pixels, byte(r/n>>8), byte(g/n>>8), byte(b/n>>8))
+		}
+	}
+	return pixels
+}
@@ -0,0 +1,92 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// colourBars returns an image of vertical bars in the given colours.
+func colourBars(width, height int, colours ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		c := colours[x*len(colours)/width]
+		for y := 0; y < height; y++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+var smpteColours = []color.RGBA{
+	{192, 192, 192, 255}, {192, 192, 0, 255}, {0, 192, 192, 255}, {0, 192, 0, 255},
+	{192, 0, 192, 255}, {192, 0, 0, 255}, {0, 0, 192, 255},
+}
+
+func TestDetectStillMatchesReference(t *testing.T) {
+	bars := stillFramePixels(colourBars(1280, 720, smpteColours...))
+	grey := stillFramePixels(colourBars(320, 180, color.RGBA{128, 128, 128, 255}))
+	refs := []stillReference{{name: "grey", pixels: grey}, {name: "bars", pixels: bars}}
+
+	result := detectStill([][]byte{bars, bars, bars}, refs, 0, 0.9)
+	if result.MatchedReference != "bars" {
+		t.Fatalf("MatchedReference = %q, want bars", result.MatchedReference)
+	}
+	if result.Similarity != 1 {
+		t.Fatalf("Similarity = %v, want 1", result.Similarity)
+	}
+	if result.LowMotion {
+		t.Fatalf("expected LowMotion to stay false when disabled")
+	}
+	if !result.IsSlate() {
+		t.Fatalf("expected IsSlate to be true")
+	}
+}
+
+func TestDetectStillLowMotion(t *testing.T) {
+	a := stillFramePixels(colourBars(64, 36, color.RGBA{20, 40, 60, 255}))
+	b := stillFramePixels(colourBars(64, 36, color.RGBA{21, 40, 60, 255}))
+	moving := stillFramePixels(colourBars(64, 36, color.RGBA{200, 40, 60, 255}))
+
+	if result := detectStill([][]byte{a, b, a}, nil, 0.01, 0.9); !result.LowMotion || result.MatchedReference != "" {
+		t.Fatalf("expected low motion without a reference match, got %+v", result)
+	}
+	if result := detectStill([][]byte{a, moving}, nil, 0.01, 0.9); result.LowMotion || result.IsSlate() {
+		t.Fatalf("expected motion to rule out a slate, got %+v", result)
+	}
+	if result := detectStill([][]byte{a}, nil, 0.01, 0.9); result.LowMotion {
+		t.Fatalf("expected a single frame not to count as low motion")
+	}
+}
+
+func TestDetectStillBelowMatchThreshold(t *testing.T) {
+	bars := stillFramePixels(colourBars(640, 360, smpteColours...))
+	black := stillFramePixels(colourBars(640, 360, color.RGBA{0, 0, 0, 255}))
+
+	result := detectStill([][]byte{black}, []stillReference{{name: "bars", pixels: bars}}, 0, 0.9)
+	if result.MatchedReference != "" {
+		t.Fatalf("MatchedReference = %q, want none", result.MatchedReference)
+	}
+	if result.Similarity <= 0 || result.Similarity >= 0.9 {
+		t.Fatalf("Similarity = %v, want between 0 and 0.9", result.Similarity)
+	}
+}
+
+func TestDecodeSlateReference(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, colourBars(16, 9, smpteColours...)); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	ref, err := DecodeSlateReference("bars", buf.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeSlateReference error: %v", err)
+	}
+	if ref.Name != "bars" || ref.Image.Bounds().Dx() != 16 {
+		t.Fatalf("unexpected reference %q with bounds %v", ref.Name, ref.Image.Bounds())
+	}
+	if _, err := DecodeSlateReference("junk", []byte("not an image")); err == nil {
+		t.Fatalf("expected an error for invalid image data")
+	}
+}
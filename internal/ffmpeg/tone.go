@@ -0,0 +1,99 @@
+package ffmpeg
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// Tone detection decodes the segment audio to mono PCM and measures, per
+// short block, how much of the block's energy sits at the target frequency.
+const (
+	toneSampleRate = 16000
+	// toneBlockSamples is 0.1s, giving a 10 Hz analysis resolution.
+	toneBlockSamples = toneSampleRate / 10
+	// tonePurityThreshold is the share of a block's energy that must be at
+	// the target frequency for the block to count as tone.
+	tonePurityThreshold = 0.9
+)
+
+// ToneDetectResult contains the result of test-tone detection.
+type ToneDetectResult struct {
+	Frequency     float64
+	HasTone       bool
+	ToneDuration  float64
+	TotalDuration float64
+	ToneRatio     float64
+	FullyTone     bool
+	// Purity is the highest share of block energy found at Frequency,
+	// from 0 to 1; a pure sine is close to 1.
+	Purity float64
+}
+
+// toneOutputArgs returns the options of the analysis output that writes the
+// first audio stream as mono 16-bit PCM at toneSampleRate.
+func toneOutputArgs() []string {
+	return []string{
+		"-map", "0:a:0",
+		"-ac", "1",
+		"-ar", strconv.Itoa(toneSampleRate),
+		"-f", "s16le",
+	}
+}
+
+// toneSamples decodes the output of toneOutputArgs.
+func toneSamples(raw []byte) []int16 {
+	samples := make([]int16, len(raw)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(raw[2*i:]))
+	}
+	return samples
+}
+
+// detectTone splits samples (at toneSampleRate) into blocks and counts those
+// that are both louder than levelDB dBFS and dominated by frequency. A
+// segment is fully tone when the tone share exceeds ratioThreshold, the same
+// rule silence detection uses.
+func detectTone(samples []int16, frequency, levelDB, ratioThreshold float64) *ToneDetectResult {
+	result := &ToneDetectResult{
+		Frequency:     frequency,
+		TotalDuration: float64(len(samples)) / toneSampleRate,
+	}
+	blockSamples := toneBlockSamples
+	if len(samples) < blockSamples {
+		return result
+	}
+
+	coeff := 2 * math.Cos(2*math.Pi*frequency/toneSampleRate)
+	minEnergy := math.Pow(10, levelDB/10)
+	var toneBlocks, blocks int
+	for start := 0; start+blockSamples <= len(samples); start += blockSamples {
+		blocks++
+		// Goertzel filter for the target frequency, with the block's total
+		// energy accumulated alongside.
+		var s1, s2, energy float64
+		for _, sample := range samples[start : start+blockSamples] {
+			x := float64(sample) / 32768
+			energy += x * x
+			s0 := x + coeff*s1 - s2
+			s2, s1 = s1, s0
+		}
+		// Quiet blocks are silence, not tone.
+		if energy/float64(blockSamples) <= minEnergy {
+			continue
+		}
+		power := s1*s1 + s2*s2 - coeff*s1*s2
+		purity := 2 * power / (float64(blockSamples) * energy)
+		result.Purity = math.Max(result.Purity, math.Min(purity, 1))
+		if purity >= tonePurityThreshold {
+			toneBlocks++
+		}
+	}
+
+	blockDuration := float64(blockSamples) / toneSampleRate
+	result.ToneDuration = float64(toneBlocks) * blockDuration
+	result.HasTone = toneBlocks > 0
+	result.ToneRatio = float64(toneBlocks) / float64(blocks)
+	result.FullyTone = result.ToneRatio > ratioThreshold
+	return result
+}
@@ -0,0 +1,66 @@
+package ffmpeg
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sine returns seconds of a sine wave at toneSampleRate.
+func sine(frequency, amplitude, seconds float64) []int16 {
+	samples := make([]int16, int(seconds*toneSampleRate))
+	for i := range samples {
+		samples[i] = int16(amplitude * 32767 * math.Sin(2*math.Pi*frequency*float64(i)/toneSampleRate))
+	}
+	return samples
+}
+
+func TestDetectToneSteadySine(t *testing.T) {
+	result := detectTone(sine(1000, 0.5, 2), 1000, -50, 0.9)
+	if !result.HasTone || !result.FullyTone {
+		t.Fatalf("expected a fully tonal segment, got %+v", result)
+	}
+	if math.Abs(result.ToneDuration-2) > 1e-9 || result.ToneRatio != 1 {
+		t.Fatalf("ToneDuration = %v, ToneRatio = %v, want 2s and 1", result.ToneDuration, result.ToneRatio)
+	}
+	if result.Purity < 0.99 {
+		t.Fatalf("Purity = %v, want close to 1", result.Purity)
+	}
+}
+
+func TestDetectToneOtherFrequency(t *testing.T) {
+	result := detectTone(sine(440, 0.5, 2), 1000, -50, 0.9)
+	if result.HasTone || result.FullyTone {
+		t.Fatalf("expected a 440 Hz sine not to match 1 kHz, got %+v", result)
+	}
+}
+
+func TestDetectToneNoiseAndSilence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]int16, 2*toneSampleRate)
+	for i := range noise {
+		noise[i] = int16(rng.Intn(20000) - 10000)
+	}
+	if result := detectTone(noise, 1000, -50, 0.9); result.HasTone {
+		t.Fatalf("expected noise not to count as tone, got %+v", result)
+	}
+
+	// A tone far below the silence level is silence, not tone.
+	if result := detectTone(sine(1000, 0.0001, 2), 1000, -50, 0.9); result.HasTone {
+		t.Fatalf("expected a tone below the level threshold to be ignored, got %+v", result)
+	}
+	if result := detectTone(make([]int16, 2*toneSampleRate), 1000, -50, 0.9); result.HasTone || result.Purity != 0 {
+		t.Fatalf("expected digital silence to have no tone, got %+v", result)
+	}
+}
+
+func TestDetectTonePartial(t *testing.T) {
+	samples := append(sine(1000, 0.5, 1), make([]int16, toneSampleRate)...)
+	result := detectTone(samples, 1000, -50, 0.9)
+	if !result.HasTone || result.FullyTone {
+		t.Fatalf("expected partial tone, got %+v", result)
+	}
+	if math.Abs(result.ToneRatio-0.5) > 1e-9 {
+		t.Fatalf("ToneRatio = %v, want 0.5", result.ToneRatio)
+	}
+}
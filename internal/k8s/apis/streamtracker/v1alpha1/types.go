@@ -36,13 +36,45 @@ var GVR = SchemeGroupVersion.WithResource(Plural)
 // StreamMonitorSpec is the desired-state (writable by the API's
 // create/patch handlers) part of a StreamMonitor object.
 type StreamMonitorSpec struct {
-	StreamURL            string       `json:"streamURL"`
-	CallbackURL          string       `json:"callbackURL"`
-	CheckIntervalSec     int          `json:"checkIntervalSec"`
-	BlackoutThresholdSec int          `json:"blackoutThresholdSec"`
-	SilenceThresholdSec  int          `json:"silenceThresholdSec"`
-	SilenceDBThreshold   float64      `json:"silenceDBThreshold"`
-	ScheduledStartTime   *metav1.Time `json:"scheduledStartTime,omitempty"`
+	StreamURL               string                 `json:"streamURL"`
+	VideoID                 string                 `json:"videoID,omitempty"`
+	CallbackURL             string                 `json:"callbackURL"`
+	CheckIntervalSec        int                    `json:"checkIntervalSec"`
+	BlackoutThresholdSec    int                    `json:"blackoutThresholdSec"`
+	SilenceThresholdSec     int                    `json:"silenceThresholdSec"`
+	SilenceDBThreshold      float64                `json:"silenceDBThreshold"`
+	SilenceMinDurationSec   float64                `json:"silenceMinDurationSec"`
+	SilenceRatioThreshold   float64                `json:"silenceRatioThreshold"`
+	BlackMinDurationSec     float64                `json:"blackMinDurationSec"`
+	BlackPixelThreshold     float64                `json:"blackPixelThreshold"`
+	BlackRatioThreshold     float64                `json:"blackRatioThreshold"`
+	FreezeThresholdSec      int                    `json:"freezeThresholdSec"`
+	FreezeNoiseDB           float64                `json:"freezeNoiseDB"`
+	FreezeMinDurationSec    float64                `json:"freezeMinDurationSec"`
+	FreezeRatioThreshold    float64                `json:"freezeRatioThreshold"`
+	LoudnessMinLUFS         float64                `json:"loudnessMinLUFS"`
+	LoudnessMaxLUFS         float64                `json:"loudnessMaxLUFS"`
+	LoudnessThresholdSec    int                    `json:"loudnessThresholdSec"`
+	MinVideoHeight          int                    `json:"minVideoHeight"`
+	MinBitrateKbps          int                    `json:"minBitrateKbps"`
+	QualityDegradedSegments int                    `json:"qualityDegradedSegments"`
+	CatchupMaxSegments      int                    `json:"catchupMaxSegments"`
+	AVDesyncMaxOffsetMs     int                    `json:"avDesyncMaxOffsetMs"`
+	AVDesyncThresholdSec    int                    `json:"avDesyncThresholdSec"`
+	SlateReferences         []model.SlateReference `json:"slateReferences,omitempty"`
+	SlateMatchThreshold     float64                `json:"slateMatchThreshold"`
+	SlateMaxMotion          float64                `json:"slateMaxMotion"`
+	SlateThresholdSec       int                    `json:"slateThresholdSec"`
+	ToneFrequencyHz         int                    `json:"toneFrequencyHz"`
+	CaptionsRequired        bool                   `json:"captionsRequired"`
+	CaptionsThresholdSec    int                    `json:"captionsThresholdSec"`
+	LatencyMaxSec           int                    `json:"latencyMaxSec"`
+	LatencyThresholdSec     int                    `json:"latencyThresholdSec"`
+	LowLatency              bool                   `json:"lowLatency"`
+	CookiesSecret           string                 `json:"cookiesSecret,omitempty"`
+	VariantSelection        string                 `json:"variantSelection"`
+	Variants                []string               `json:"variants,omitempty"`
+	ScheduledStartTime      *metav1.Time           `json:"scheduledStartTime,omitempty"`
 	// ScheduledEndTime is defined in the CRD schema now (see the Decision
 	// Log in docs/coding-agent/plans/01-streammonitor-crd-migration.md on
 	// shipping the full schema up front) but is not yet wired up: nothing
@@ -58,15 +90,32 @@ type StreamMonitorSpec struct {
 // StreamMonitorStatus is the live-state (writable by the worker's status
 // callbacks, via the status subresource) part of a StreamMonitor object.
 type StreamMonitorStatus struct {
-	Phase          model.MonitorStatus `json:"phase,omitempty"`
-	PodName        string              `json:"podName,omitempty"`
-	StreamStatus   model.StreamStatus  `json:"streamStatus,omitempty"`
-	VideoHealth    model.HealthStatus  `json:"videoHealth,omitempty"`
-	AudioHealth    model.HealthStatus  `json:"audioHealth,omitempty"`
-	TotalSegments  int                 `json:"totalSegments,omitempty"`
-	BlackoutEvents int                 `json:"blackoutEvents,omitempty"`
-	SilenceEvents  int                 `json:"silenceEvents,omitempty"`
-	LastCheckAt    *metav1.Time        `json:"lastCheckAt,omitempty"`
+	Phase                 model.MonitorStatus `json:"phase,omitempty"`
+	PodName               string              `json:"podName,omitempty"`
+	StreamStatus          model.StreamStatus  `json:"streamStatus,omitempty"`
+	VideoHealth           model.HealthStatus  `json:"videoHealth,omitempty"`
+	AudioHealth           model.HealthStatus  `json:"audioHealth,omitempty"`
+	TotalSegments         int                 `json:"totalSegments,omitempty"`
+	SkippedSegments       int                 `json:"skippedSegments,omitempty"`
+	BlackoutEvents        int                 `json:"blackoutEvents,omitempty"`
+	SilenceEvents         int                 `json:"silenceEvents,omitempty"`
+	ChannelSilenceEvents  int                 `json:"channelSilenceEvents,omitempty"`
+	QualityDegradedEvents int                 `json:"qualityDegradedEvents,omitempty"`
+	FreezeEvents          int                 `json:"freezeEvents,omitempty"`
+	LoudnessEvents        int                 `json:"loudnessEvents,omitempty"`
+	AVDesyncEvents        int                 `json:"avDesyncEvents,omitempty"`
+	SlateEvents           int                 `json:"slateEvents,omitempty"`
+	ToneEvents            int                 `json:"toneEvents,omitempty"`
+	CaptionsEvents        int                 `json:"captionsEvents,omitempty"`
+	LatencyEvents         int                 `json:"latencyEvents,omitempty"`
+	PlaylistAnomalyEvents int                 `json:"playlistAnomalyEvents,omitempty"`
+	VideoWidth            int                 `json:"videoWidth,omitempty"`
+	VideoHeight           int                 `json:"videoHeight,omitempty"`
+	FrameRate             float64             `json:"frameRate,omitempty"`
+	BitrateKbps           float64             `json:"bitrateKbps,omitempty"`
+	AVOffsetMs            float64             `json:"avOffsetMs,omitempty"`
+	LatencySec            float64             `json:"latencySec,omitempty"`
+	LastCheckAt           *metav1.Time        `json:"lastCheckAt,omitempty"`
 }
 
 // StreamMonitor is one monitored YouTube livestream, represented as a
@@ -34,14 +34,20 @@ const (
 	LabelMonitorID = "monitor-id"
 	// PodNamePrefix is the prefix for worker pod names.
 	PodNamePrefix = "stream-monitor-"
+	// CookiesSecretKey is the key holding the Netscape cookies file in a
+	// monitor's cookies Secret.
+	CookiesSecretKey = "cookies.txt"
+
+	cookiesMountPath = "/var/run/stream-monitor/cookies"
 )
 
 // Client wraps Kubernetes client operations.
 type Client struct {
-	clientset   *kubernetes.Clientset
-	namespace   string
-	workerImage string
-	workerTag   string
+	clientset      *kubernetes.Clientset
+	namespace      string
+	workerImage    string
+	workerTag      string
+	workerEvidence WorkerEvidenceConfig
 }
 
 // Config holds configuration for creating a K8s client.
@@ -51,6 +57,21 @@ type Config struct {
 	Namespace      string
 	WorkerImage    string
 	WorkerImageTag string
+	WorkerEvidence WorkerEvidenceConfig
+}
+
+// WorkerEvidenceConfig holds the alert evidence settings passed to every
+// worker Pod. An empty Sink disables evidence capture.
+type WorkerEvidenceConfig struct {
+	Sink       string
+	BaseURL    string
+	S3Endpoint string
+	S3Bucket   string
+	S3Region   string
+	AudioClip  string
+	// Keys within the Pod's secrets Secret holding the S3 credentials.
+	S3AccessKeyIDKey     string
+	S3SecretAccessKeyKey string
 }
 
 // BuildRESTConfig builds the *rest.Config used to talk to the Kubernetes
@@ -100,10 +121,11 @@ func NewClient(cfg Config) (*Client, error) {
 	}
 
 	return &Client{
-		clientset:   clientset,
-		namespace:   namespace,
-		workerImage: cfg.WorkerImage,
-		workerTag:   cfg.WorkerImageTag,
+		clientset:      clientset,
+		namespace:      namespace,
+		workerImage:    cfg.WorkerImage,
+		workerTag:      cfg.WorkerImageTag,
+		workerEvidence: cfg.WorkerEvidence,
 	}, nil
 }
 
@@ -200,6 +222,8 @@ func (c *Client) CreateWorkerPod(ctx context.Context, params CreatePodParams) (*
 		envVars = append(envVars, corev1.EnvVar{Name: "HTTPS_PROXY", Value: params.HTTPSProxy})
 	}
 
+	envVars = append(envVars, evidenceEnvVars(c.workerEvidence, params.SecretsName)...)
+
 	// Add metadata if present
 	if params.Metadata != nil {
 		envVars = append(envVars, corev1.EnvVar{Name: "METADATA_JSON", Value: string(params.Metadata)})
@@ -306,6 +330,10 @@ func (c *Client) CreateWorkerPod(ctx context.Context, params CreatePodParams) (*
 		},
 	}
 
+	if params.Config != nil && params.Config.CookiesSecret != "" {
+		addCookiesVolume(pod, params.Config.CookiesSecret)
+	}
+
 	created, err := c.clientset.CoreV1().Pods(c.namespace).Create(ctx, pod, metav1.CreateOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("create pod: %w", err)
@@ -319,6 +347,70 @@ func (c *Client) CreateWorkerPod(ctx context.Context, params CreatePodParams) (*
 	return created, nil
 }
 
+// addCookiesVolume mounts the cookies file from secretName into the worker
+// container and points COOKIES_FILE at it. The directory is mounted without
+// subPath so a rotated Secret reaches the running Pod.
+func addCookiesVolume(pod *corev1.Pod, secretName string) {
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "cookies",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+				Items:      []corev1.KeyToPath{{Key: CookiesSecretKey, Path: CookiesSecretKey}},
+			},
+		},
+	})
+	container := &pod.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "cookies",
+		MountPath: cookiesMountPath,
+		ReadOnly:  true,
+	})
+	container.Env = append(container.Env, corev1.EnvVar{Name: "COOKIES_FILE", Value: filepath.Join(cookiesMountPath, CookiesSecretKey)})
+}
+
+// evidenceEnvVars builds the worker env for alert evidence capture. S3
+// credentials are referenced from secretsName as optional keys, so Pods
+// still start when the keys are missing (the worker then disables capture).
+func evidenceEnvVars(cfg WorkerEvidenceConfig, secretsName string) []corev1.EnvVar {
+	if cfg.Sink == "" {
+		return nil
+	}
+
+	envVars := []corev1.EnvVar{{Name: "EVIDENCE_SINK", Value: cfg.Sink}}
+	for _, kv := range []struct{ name, value string }{
+		{"EVIDENCE_BASE_URL", cfg.BaseURL},
+		{"EVIDENCE_S3_ENDPOINT", cfg.S3Endpoint},
+		{"EVIDENCE_S3_BUCKET", cfg.S3Bucket},
+		{"EVIDENCE_S3_REGION", cfg.S3Region},
+		{"EVIDENCE_AUDIO_CLIP", cfg.AudioClip},
+	} {
+		if kv.value != "" {
+			envVars = append(envVars, corev1.EnvVar{Name: kv.name, Value: kv.value})
+		}
+	}
+
+	if secretsName != "" && cfg.S3AccessKeyIDKey != "" && cfg.S3SecretAccessKeyKey != "" {
+		for _, kv := range []struct{ name, key string }{
+			{"EVIDENCE_S3_ACCESS_KEY_ID", cfg.S3AccessKeyIDKey},
+			{"EVIDENCE_S3_SECRET_ACCESS_KEY", cfg.S3SecretAccessKeyKey},
+		} {
+			envVars = append(envVars, corev1.EnvVar{
+				Name: kv.name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secretsName},
+						Key:                  kv.key,
+						Optional:             boolPtr(true),
+					},
+				},
+			})
+		}
+	}
+
+	return envVars
+}
+
 func int64Ptr(value int64) *int64 {
 	return &value
 }
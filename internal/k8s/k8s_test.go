@@ -3,6 +3,8 @@ package k8s
 import (
 	"context"
 	"testing"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestPodNamePrefix(t *testing.T) {
@@ -105,3 +107,54 @@ func TestCreateWorkerPodRejectsEmptyOwnerUID(t *testing.T) {
 // - Test fixtures for Pod creation/deletion
 // These are beyond the scope of basic unit tests and should be part of
 // integration test suite with proper test infrastructure.
+
+func TestEvidenceEnvVars(t *testing.T) {
+	if env := evidenceEnvVars(WorkerEvidenceConfig{}, "secrets"); env != nil {
+		t.Errorf("evidenceEnvVars() = %v, want nil when evidence is disabled", env)
+	}
+
+	env := evidenceEnvVars(WorkerEvidenceConfig{
+		Sink:                 "s3",
+		S3Endpoint:           "https://s3.example.com",
+		S3Bucket:             "evidence",
+		S3AccessKeyIDKey:     "evidence-s3-access-key-id",
+		S3SecretAccessKeyKey: "evidence-s3-secret-access-key",
+	}, "stream-monitor-secrets")
+
+	byName := make(map[string]int)
+	for i, e := range env {
+		byName[e.Name] = i
+	}
+	for _, name := range []string{"EVIDENCE_SINK", "EVIDENCE_S3_ENDPOINT", "EVIDENCE_S3_BUCKET", "EVIDENCE_S3_ACCESS_KEY_ID", "EVIDENCE_S3_SECRET_ACCESS_KEY"} {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("missing env var %s", name)
+		}
+	}
+	if _, ok := byName["EVIDENCE_BASE_URL"]; ok {
+		t.Errorf("expected empty EVIDENCE_BASE_URL to be omitted")
+	}
+
+	secret := env[byName["EVIDENCE_S3_SECRET_ACCESS_KEY"]]
+	if secret.Value != "" || secret.ValueFrom == nil || secret.ValueFrom.SecretKeyRef == nil {
+		t.Fatalf("EVIDENCE_S3_SECRET_ACCESS_KEY must come from a secret, got %+v", secret)
+	}
+	if secret.ValueFrom.SecretKeyRef.Name != "stream-monitor-secrets" || secret.ValueFrom.SecretKeyRef.Key != "evidence-s3-secret-access-key" {
+		t.Errorf("SecretKeyRef = %+v", secret.ValueFrom.SecretKeyRef)
+	}
+}
+
+func TestAddCookiesVolume(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "monitor"}}}}
+	addCookiesVolume(pod, "member-session")
+
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Secret == nil || pod.Spec.Volumes[0].Secret.SecretName != "member-session" {
+		t.Fatalf("volumes = %+v, want the cookies Secret", pod.Spec.Volumes)
+	}
+	container := pod.Spec.Containers[0]
+	if len(container.VolumeMounts) != 1 || !container.VolumeMounts[0].ReadOnly || container.VolumeMounts[0].SubPath != "" {
+		t.Fatalf("volume mounts = %+v, want a read-only directory mount", container.VolumeMounts)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != "COOKIES_FILE" || container.Env[0].Value != "/var/run/stream-monitor/cookies/cookies.txt" {
+		t.Fatalf("env = %+v, want COOKIES_FILE", container.Env)
+	}
+}
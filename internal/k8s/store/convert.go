@@ -10,6 +10,7 @@ import (
 
 	"github.com/xpadev-net/youtube-stream-tracker/internal/k8s/apis/streamtracker/v1alpha1"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtubeurl"
 )
 
 // fromUnstructured converts an *unstructured.Unstructured object (as
@@ -41,19 +42,54 @@ func toMonitor(sm *v1alpha1.StreamMonitor) *model.Monitor {
 		ID:          sm.Name,
 		UID:         sm.UID,
 		StreamURL:   sm.Spec.StreamURL,
+		VideoID:     sm.Spec.VideoID,
 		CallbackURL: sm.Spec.CallbackURL,
 		Status:      sm.Status.Phase,
 		CreatedAt:   sm.CreationTimestamp.Time,
 		UpdatedAt:   sm.CreationTimestamp.Time,
 		Config: model.MonitorConfig{
-			CheckIntervalSec:       sm.Spec.CheckIntervalSec,
-			BlackoutThresholdSec:   sm.Spec.BlackoutThresholdSec,
-			SilenceThresholdSec:    sm.Spec.SilenceThresholdSec,
-			SilenceDBThreshold:     sm.Spec.SilenceDBThreshold,
-			StartDelayToleranceSec: sm.Spec.StartDelayToleranceSec,
+			CheckIntervalSec:        sm.Spec.CheckIntervalSec,
+			BlackoutThresholdSec:    sm.Spec.BlackoutThresholdSec,
+			SilenceThresholdSec:     sm.Spec.SilenceThresholdSec,
+			SilenceDBThreshold:      sm.Spec.SilenceDBThreshold,
+			SilenceMinDurationSec:   sm.Spec.SilenceMinDurationSec,
+			SilenceRatioThreshold:   sm.Spec.SilenceRatioThreshold,
+			BlackMinDurationSec:     sm.Spec.BlackMinDurationSec,
+			BlackPixelThreshold:     sm.Spec.BlackPixelThreshold,
+			BlackRatioThreshold:     sm.Spec.BlackRatioThreshold,
+			FreezeThresholdSec:      sm.Spec.FreezeThresholdSec,
+			FreezeNoiseDB:           sm.Spec.FreezeNoiseDB,
+			FreezeMinDurationSec:    sm.Spec.FreezeMinDurationSec,
+			FreezeRatioThreshold:    sm.Spec.FreezeRatioThreshold,
+			LoudnessMinLUFS:         sm.Spec.LoudnessMinLUFS,
+			LoudnessMaxLUFS:         sm.Spec.LoudnessMaxLUFS,
+			LoudnessThresholdSec:    sm.Spec.LoudnessThresholdSec,
+			MinVideoHeight:          sm.Spec.MinVideoHeight,
+			MinBitrateKbps:          sm.Spec.MinBitrateKbps,
+			QualityDegradedSegments: sm.Spec.QualityDegradedSegments,
+			CatchupMaxSegments:      sm.Spec.CatchupMaxSegments,
+			AVDesyncMaxOffsetMs:     sm.Spec.AVDesyncMaxOffsetMs,
+			AVDesyncThresholdSec:    sm.Spec.AVDesyncThresholdSec,
+			SlateReferences:         sm.Spec.SlateReferences,
+			SlateMatchThreshold:     sm.Spec.SlateMatchThreshold,
+			SlateMaxMotion:          sm.Spec.SlateMaxMotion,
+			SlateThresholdSec:       sm.Spec.SlateThresholdSec,
+			ToneFrequencyHz:         sm.Spec.ToneFrequencyHz,
+			CaptionsRequired:        sm.Spec.CaptionsRequired,
+			CaptionsThresholdSec:    sm.Spec.CaptionsThresholdSec,
+			LatencyMaxSec:           sm.Spec.LatencyMaxSec,
+			LatencyThresholdSec:     sm.Spec.LatencyThresholdSec,
+			LowLatency:              sm.Spec.LowLatency,
+			CookiesSecret:           sm.Spec.CookiesSecret,
+			VariantSelection:        sm.Spec.VariantSelection,
+			Variants:                sm.Spec.Variants,
+			StartDelayToleranceSec:  sm.Spec.StartDelayToleranceSec,
 		},
 	}
 
+	// Objects created before a tunable existed carry 0 for it.
+	m.Config.DefaultUnsetTunables()
+
 	if sm.Spec.ScheduledStartTime != nil {
 		t := sm.Spec.ScheduledStartTime.Time
 		m.Config.ScheduledStartTime = &t
@@ -67,6 +103,10 @@ func toMonitor(sm *v1alpha1.StreamMonitor) *model.Monitor {
 		podName := sm.Status.PodName
 		m.PodName = &podName
 	}
+	// Objects created before spec.videoID existed only carry the URL.
+	if m.VideoID == "" {
+		m.VideoID, _ = youtubeurl.VideoID(m.StreamURL)
+	}
 
 	return m
 }
@@ -75,13 +115,30 @@ func toMonitor(sm *v1alpha1.StreamMonitor) *model.Monitor {
 // domain type used by the rest of the codebase.
 func toStats(sm *v1alpha1.StreamMonitor) *model.MonitorStats {
 	stats := &model.MonitorStats{
-		MonitorID:      sm.Name,
-		TotalSegments:  sm.Status.TotalSegments,
-		BlackoutEvents: sm.Status.BlackoutEvents,
-		SilenceEvents:  sm.Status.SilenceEvents,
-		VideoHealth:    sm.Status.VideoHealth,
-		AudioHealth:    sm.Status.AudioHealth,
-		StreamStatus:   sm.Status.StreamStatus,
+		MonitorID:             sm.Name,
+		TotalSegments:         sm.Status.TotalSegments,
+		SkippedSegments:       sm.Status.SkippedSegments,
+		BlackoutEvents:        sm.Status.BlackoutEvents,
+		SilenceEvents:         sm.Status.SilenceEvents,
+		ChannelSilenceEvents:  sm.Status.ChannelSilenceEvents,
+		QualityDegradedEvents: sm.Status.QualityDegradedEvents,
+		FreezeEvents:          sm.Status.FreezeEvents,
+		LoudnessEvents:        sm.Status.LoudnessEvents,
+		AVDesyncEvents:        sm.Status.AVDesyncEvents,
+		SlateEvents:           sm.Status.SlateEvents,
+		ToneEvents:            sm.Status.ToneEvents,
+		CaptionsEvents:        sm.Status.CaptionsEvents,
+		LatencyEvents:         sm.Status.LatencyEvents,
+		PlaylistAnomalyEvents: sm.Status.PlaylistAnomalyEvents,
+		VideoWidth:            sm.Status.VideoWidth,
+		VideoHeight:           sm.Status.VideoHeight,
+		FrameRate:             sm.Status.FrameRate,
+		BitrateKbps:           sm.Status.BitrateKbps,
+		AVOffsetMs:            sm.Status.AVOffsetMs,
+		LatencySec:            sm.Status.LatencySec,
+		VideoHealth:           sm.Status.VideoHealth,
+		AudioHealth:           sm.Status.AudioHealth,
+		StreamStatus:          sm.Status.StreamStatus,
 	}
 	if sm.Status.LastCheckAt != nil {
 		t := sm.Status.LastCheckAt.Time
@@ -35,6 +35,7 @@ import (
 
 	"github.com/xpadev-net/youtube-stream-tracker/internal/k8s/apis/streamtracker/v1alpha1"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtubeurl"
 )
 
 var (
@@ -43,17 +44,18 @@ var (
 	ErrMonitorNotActive = errors.New("monitor is not in an active state")
 )
 
-// LabelStreamURLHash is the label key holding StreamURLHash(spec.streamURL),
-// set on every StreamMonitor object at creation time so that the informer
-// can index and look up monitors by their stream URL without scanning every
-// object (mirrors the role the old Postgres unique index played).
-const LabelStreamURLHash = "streamtracker.xpadev.net/stream-url-hash"
+// LabelVideoIDHash is the label key holding VideoIDHash of the monitored
+// video, set on every StreamMonitor object at creation time so monitors of
+// one video can be selected with kubectl (video IDs themselves may start
+// with "-" or "_", which label values cannot).
+const LabelVideoIDHash = "streamtracker.xpadev.net/video-id-hash"
 
-// indexStreamURLHash and indexPhase are the names of the two indexes
-// registered on the informer (see NewStore).
+// indexVideoID and indexPhase are the names of the two indexes registered
+// on the informer (see NewStore). indexVideoID plays the role the old
+// Postgres unique index on the stream URL played, keyed by duplicateKey.
 const (
-	indexStreamURLHash = "streamURLHash"
-	indexPhase         = "phase"
+	indexVideoID = "videoID"
+	indexPhase   = "phase"
 )
 
 // Store is the informer-backed replacement for the old
@@ -82,8 +84,8 @@ func NewStore(dyn dynamic.Interface, namespace string) *Store {
 	}
 
 	informer := cache.NewSharedIndexInformer(lw, &unstructured.Unstructured{}, 0, cache.Indexers{
-		indexStreamURLHash: streamURLHashIndexFunc,
-		indexPhase:         phaseIndexFunc,
+		indexVideoID: videoIDIndexFunc,
+		indexPhase:   phaseIndexFunc,
 	})
 
 	return &Store{
@@ -93,16 +95,33 @@ func NewStore(dyn dynamic.Interface, namespace string) *Store {
 	}
 }
 
-func streamURLHashIndexFunc(obj interface{}) ([]string, error) {
+// videoIDIndexFunc indexes objects by duplicateKey of their spec, so objects
+// created before spec.videoID existed are still found by video ID.
+func videoIDIndexFunc(obj interface{}) ([]string, error) {
 	u, ok := obj.(*unstructured.Unstructured)
 	if !ok {
 		return nil, nil
 	}
-	hash := u.GetLabels()[LabelStreamURLHash]
-	if hash == "" {
+	videoID, _, _ := unstructured.NestedString(u.Object, "spec", "videoID")
+	streamURL, _, _ := unstructured.NestedString(u.Object, "spec", "streamURL")
+	key := duplicateKey(videoID, streamURL)
+	if key == "" {
 		return nil, nil
 	}
-	return []string{hash}, nil
+	return []string{key}, nil
+}
+
+// duplicateKey identifies the stream a monitor watches: its video ID, taken
+// from streamURL when videoID is empty, or the raw stream URL when neither
+// yields one.
+func duplicateKey(videoID, streamURL string) string {
+	if videoID != "" {
+		return videoID
+	}
+	if id, err := youtubeurl.VideoID(streamURL); err == nil {
+		return id
+	}
+	return streamURL
 }
 
 func phaseIndexFunc(obj interface{}) ([]string, error) {
@@ -129,11 +148,10 @@ func (s *Store) WaitForCacheSync(ctx context.Context) bool {
 	return cache.WaitForCacheSync(ctx.Done(), s.informer.HasSynced)
 }
 
-// StreamURLHash returns a short, stable, non-reversible identifier for a
-// stream URL, used as a label value (Kubernetes label values cannot contain
-// arbitrary characters like a raw URL can) to index monitors by stream URL.
-func StreamURLHash(streamURL string) string {
-	sum := sha256.Sum256([]byte(streamURL))
+// VideoIDHash returns a short, stable identifier for a video ID that is
+// safe to use as a label value.
+func VideoIDHash(videoID string) string {
+	sum := sha256.Sum256([]byte(videoID))
 	return hex.EncodeToString(sum[:])[:16]
 }
 
@@ -141,6 +159,7 @@ func StreamURLHash(streamURL string) string {
 type CreateMonitorParams struct {
 	ID           string
 	StreamURL    string
+	VideoID      string
 	CallbackURL  string
 	Config       model.MonitorConfig
 	Metadata     json.RawMessage
@@ -149,12 +168,12 @@ type CreateMonitorParams struct {
 
 // Create creates a new StreamMonitor object for the given parameters,
 // rejecting the request with ErrDuplicateMonitor if an active monitor for
-// the same stream URL already exists (per the informer's — possibly
-// slightly stale — view of the world).
+// the same video already exists (per the informer's — possibly slightly
+// stale — view of the world).
 func (s *Store) Create(ctx context.Context, p CreateMonitorParams) (*model.Monitor, error) {
-	hash := StreamURLHash(p.StreamURL)
+	key := duplicateKey(p.VideoID, p.StreamURL)
 
-	existing, err := s.informer.GetIndexer().ByIndex(indexStreamURLHash, hash)
+	existing, err := s.informer.GetIndexer().ByIndex(indexVideoID, key)
 	if err != nil {
 		return nil, fmt.Errorf("check duplicate stream URL: %w", err)
 	}
@@ -178,11 +197,12 @@ func (s *Store) Create(ctx context.Context, p CreateMonitorParams) (*model.Monit
 			Name:      p.ID,
 			Namespace: s.namespace,
 			Labels: map[string]string{
-				LabelStreamURLHash: hash,
+				LabelVideoIDHash: VideoIDHash(key),
 			},
 		},
 		Spec: StreamMonitorSpecFromConfig(p.StreamURL, p.CallbackURL, p.Config),
 	}
+	sm.Spec.VideoID = p.VideoID
 	if len(p.Metadata) > 0 {
 		sm.Spec.Metadata = &runtime.RawExtension{Raw: p.Metadata}
 	}
@@ -236,13 +256,44 @@ func (s *Store) Create(ctx context.Context, p CreateMonitorParams) (*model.Monit
 // need to build a spec directly (e.g. plan 2's scheduled-reservation path).
 func StreamMonitorSpecFromConfig(streamURL, callbackURL string, cfg model.MonitorConfig) v1alpha1.StreamMonitorSpec {
 	spec := v1alpha1.StreamMonitorSpec{
-		StreamURL:              streamURL,
-		CallbackURL:            callbackURL,
-		CheckIntervalSec:       cfg.CheckIntervalSec,
-		BlackoutThresholdSec:   cfg.BlackoutThresholdSec,
-		SilenceThresholdSec:    cfg.SilenceThresholdSec,
-		SilenceDBThreshold:     cfg.SilenceDBThreshold,
-		StartDelayToleranceSec: cfg.StartDelayToleranceSec,
+		StreamURL:               streamURL,
+		CallbackURL:             callbackURL,
+		CheckIntervalSec:        cfg.CheckIntervalSec,
+		BlackoutThresholdSec:    cfg.BlackoutThresholdSec,
+		SilenceThresholdSec:     cfg.SilenceThresholdSec,
+		SilenceDBThreshold:      cfg.SilenceDBThreshold,
+		SilenceMinDurationSec:   cfg.SilenceMinDurationSec,
+		SilenceRatioThreshold:   cfg.SilenceRatioThreshold,
+		BlackMinDurationSec:     cfg.BlackMinDurationSec,
+		BlackPixelThreshold:     cfg.BlackPixelThreshold,
+		BlackRatioThreshold:     cfg.BlackRatioThreshold,
+		FreezeThresholdSec:      cfg.FreezeThresholdSec,
+		FreezeNoiseDB:           cfg.FreezeNoiseDB,
+		FreezeMinDurationSec:    cfg.FreezeMinDurationSec,
+		FreezeRatioThreshold:    cfg.FreezeRatioThreshold,
+		LoudnessMinLUFS:         cfg.LoudnessMinLUFS,
+		LoudnessMaxLUFS:         cfg.LoudnessMaxLUFS,
+		LoudnessThresholdSec:    cfg.LoudnessThresholdSec,
+		MinVideoHeight:          cfg.MinVideoHeight,
+		MinBitrateKbps:          cfg.MinBitrateKbps,
+		QualityDegradedSegments: cfg.QualityDegradedSegments,
+		CatchupMaxSegments:      cfg.CatchupMaxSegments,
+		AVDesyncMaxOffsetMs:     cfg.AVDesyncMaxOffsetMs,
+		AVDesyncThresholdSec:    cfg.AVDesyncThresholdSec,
+		SlateReferences:         cfg.SlateReferences,
+		SlateMatchThreshold:     cfg.SlateMatchThreshold,
+		SlateMaxMotion:          cfg.SlateMaxMotion,
+		SlateThresholdSec:       cfg.SlateThresholdSec,
+		ToneFrequencyHz:         cfg.ToneFrequencyHz,
+		CaptionsRequired:        cfg.CaptionsRequired,
+		CaptionsThresholdSec:    cfg.CaptionsThresholdSec,
+		LatencyMaxSec:           cfg.LatencyMaxSec,
+		LatencyThresholdSec:     cfg.LatencyThresholdSec,
+		LowLatency:              cfg.LowLatency,
+		CookiesSecret:           cfg.CookiesSecret,
+		VariantSelection:        cfg.VariantSelection,
+		Variants:                cfg.Variants,
+		StartDelayToleranceSec:  cfg.StartDelayToleranceSec,
 	}
 	spec.ScheduledStartTime = metav1TimePtr(cfg.ScheduledStartTime)
 	return spec
@@ -474,12 +525,63 @@ func (s *Store) UpdateStats(ctx context.Context, stats *model.MonitorStats) erro
 		if err := unstructured.SetNestedField(live.Object, int64(stats.TotalSegments), "status", "totalSegments"); err != nil {
 			return fmt.Errorf("set totalSegments: %w", err)
 		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.SkippedSegments), "status", "skippedSegments"); err != nil {
+			return fmt.Errorf("set skippedSegments: %w", err)
+		}
 		if err := unstructured.SetNestedField(live.Object, int64(stats.BlackoutEvents), "status", "blackoutEvents"); err != nil {
 			return fmt.Errorf("set blackoutEvents: %w", err)
 		}
 		if err := unstructured.SetNestedField(live.Object, int64(stats.SilenceEvents), "status", "silenceEvents"); err != nil {
 			return fmt.Errorf("set silenceEvents: %w", err)
 		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.ChannelSilenceEvents), "status", "channelSilenceEvents"); err != nil {
+			return fmt.Errorf("set channelSilenceEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.QualityDegradedEvents), "status", "qualityDegradedEvents"); err != nil {
+			return fmt.Errorf("set qualityDegradedEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.FreezeEvents), "status", "freezeEvents"); err != nil {
+			return fmt.Errorf("set freezeEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.LoudnessEvents), "status", "loudnessEvents"); err != nil {
+			return fmt.Errorf("set loudnessEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.AVDesyncEvents), "status", "avDesyncEvents"); err != nil {
+			return fmt.Errorf("set avDesyncEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.SlateEvents), "status", "slateEvents"); err != nil {
+			return fmt.Errorf("set slateEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.ToneEvents), "status", "toneEvents"); err != nil {
+			return fmt.Errorf("set toneEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.CaptionsEvents), "status", "captionsEvents"); err != nil {
+			return fmt.Errorf("set captionsEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.LatencyEvents), "status", "latencyEvents"); err != nil {
+			return fmt.Errorf("set latencyEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.PlaylistAnomalyEvents), "status", "playlistAnomalyEvents"); err != nil {
+			return fmt.Errorf("set playlistAnomalyEvents: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.VideoWidth), "status", "videoWidth"); err != nil {
+			return fmt.Errorf("set videoWidth: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, int64(stats.VideoHeight), "status", "videoHeight"); err != nil {
+			return fmt.Errorf("set videoHeight: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, stats.FrameRate, "status", "frameRate"); err != nil {
+			return fmt.Errorf("set frameRate: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, stats.BitrateKbps, "status", "bitrateKbps"); err != nil {
+			return fmt.Errorf("set bitrateKbps: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, stats.AVOffsetMs, "status", "avOffsetMs"); err != nil {
+			return fmt.Errorf("set avOffsetMs: %w", err)
+		}
+		if err := unstructured.SetNestedField(live.Object, stats.LatencySec, "status", "latencySec"); err != nil {
+			return fmt.Errorf("set latencySec: %w", err)
+		}
 		if stats.VideoHealth != "" {
 			if err := unstructured.SetNestedField(live.Object, string(stats.VideoHealth), "status", "videoHealth"); err != nil {
 				return fmt.Errorf("set videoHealth: %w", err)
@@ -620,6 +722,115 @@ func (s *Store) UpdateMonitor(ctx context.Context, id string, p UpdateMonitorPar
 			if err := unstructured.SetNestedField(live.Object, p.Config.SilenceDBThreshold, "spec", "silenceDBThreshold"); err != nil {
 				return fmt.Errorf("set silenceDBThreshold: %w", err)
 			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.SilenceMinDurationSec, "spec", "silenceMinDurationSec"); err != nil {
+				return fmt.Errorf("set silenceMinDurationSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.SilenceRatioThreshold, "spec", "silenceRatioThreshold"); err != nil {
+				return fmt.Errorf("set silenceRatioThreshold: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.BlackMinDurationSec, "spec", "blackMinDurationSec"); err != nil {
+				return fmt.Errorf("set blackMinDurationSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.BlackPixelThreshold, "spec", "blackPixelThreshold"); err != nil {
+				return fmt.Errorf("set blackPixelThreshold: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.BlackRatioThreshold, "spec", "blackRatioThreshold"); err != nil {
+				return fmt.Errorf("set blackRatioThreshold: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.FreezeThresholdSec), "spec", "freezeThresholdSec"); err != nil {
+				return fmt.Errorf("set freezeThresholdSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.FreezeNoiseDB, "spec", "freezeNoiseDB"); err != nil {
+				return fmt.Errorf("set freezeNoiseDB: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.FreezeMinDurationSec, "spec", "freezeMinDurationSec"); err != nil {
+				return fmt.Errorf("set freezeMinDurationSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.FreezeRatioThreshold, "spec", "freezeRatioThreshold"); err != nil {
+				return fmt.Errorf("set freezeRatioThreshold: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.LoudnessMinLUFS, "spec", "loudnessMinLUFS"); err != nil {
+				return fmt.Errorf("set loudnessMinLUFS: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.LoudnessMaxLUFS, "spec", "loudnessMaxLUFS"); err != nil {
+				return fmt.Errorf("set loudnessMaxLUFS: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.LoudnessThresholdSec), "spec", "loudnessThresholdSec"); err != nil {
+				return fmt.Errorf("set loudnessThresholdSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.MinVideoHeight), "spec", "minVideoHeight"); err != nil {
+				return fmt.Errorf("set minVideoHeight: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.MinBitrateKbps), "spec", "minBitrateKbps"); err != nil {
+				return fmt.Errorf("set minBitrateKbps: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.QualityDegradedSegments), "spec", "qualityDegradedSegments"); err != nil {
+				return fmt.Errorf("set qualityDegradedSegments: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.CatchupMaxSegments), "spec", "catchupMaxSegments"); err != nil {
+				return fmt.Errorf("set catchupMaxSegments: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.AVDesyncMaxOffsetMs), "spec", "avDesyncMaxOffsetMs"); err != nil {
+				return fmt.Errorf("set avDesyncMaxOffsetMs: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.AVDesyncThresholdSec), "spec", "avDesyncThresholdSec"); err != nil {
+				return fmt.Errorf("set avDesyncThresholdSec: %w", err)
+			}
+			if len(p.Config.SlateReferences) > 0 {
+				refs := make([]interface{}, 0, len(p.Config.SlateReferences))
+				for _, ref := range p.Config.SlateReferences {
+					refs = append(refs, map[string]interface{}{"name": ref.Name, "image": ref.Image})
+				}
+				if err := unstructured.SetNestedSlice(live.Object, refs, "spec", "slateReferences"); err != nil {
+					return fmt.Errorf("set slateReferences: %w", err)
+				}
+			} else {
+				unstructured.RemoveNestedField(live.Object, "spec", "slateReferences")
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.SlateMatchThreshold, "spec", "slateMatchThreshold"); err != nil {
+				return fmt.Errorf("set slateMatchThreshold: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.SlateMaxMotion, "spec", "slateMaxMotion"); err != nil {
+				return fmt.Errorf("set slateMaxMotion: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.SlateThresholdSec), "spec", "slateThresholdSec"); err != nil {
+				return fmt.Errorf("set slateThresholdSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.ToneFrequencyHz), "spec", "toneFrequencyHz"); err != nil {
+				return fmt.Errorf("set toneFrequencyHz: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.CaptionsRequired, "spec", "captionsRequired"); err != nil {
+				return fmt.Errorf("set captionsRequired: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.CaptionsThresholdSec), "spec", "captionsThresholdSec"); err != nil {
+				return fmt.Errorf("set captionsThresholdSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.LatencyMaxSec), "spec", "latencyMaxSec"); err != nil {
+				return fmt.Errorf("set latencyMaxSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, int64(p.Config.LatencyThresholdSec), "spec", "latencyThresholdSec"); err != nil {
+				return fmt.Errorf("set latencyThresholdSec: %w", err)
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.LowLatency, "spec", "lowLatency"); err != nil {
+				return fmt.Errorf("set lowLatency: %w", err)
+			}
+			if p.Config.CookiesSecret != "" {
+				if err := unstructured.SetNestedField(live.Object, p.Config.CookiesSecret, "spec", "cookiesSecret"); err != nil {
+					return fmt.Errorf("set cookiesSecret: %w", err)
+				}
+			} else {
+				unstructured.RemoveNestedField(live.Object, "spec", "cookiesSecret")
+			}
+			if err := unstructured.SetNestedField(live.Object, p.Config.VariantSelection, "spec", "variantSelection"); err != nil {
+				return fmt.Errorf("set variantSelection: %w", err)
+			}
+			if len(p.Config.Variants) > 0 {
+				if err := unstructured.SetNestedStringSlice(live.Object, p.Config.Variants, "spec", "variants"); err != nil {
+					return fmt.Errorf("set variants: %w", err)
+				}
+			} else {
+				unstructured.RemoveNestedField(live.Object, "spec", "variants")
+			}
 			if err := unstructured.SetNestedField(live.Object, int64(p.Config.StartDelayToleranceSec), "spec", "startDelayToleranceSec"); err != nil {
 				return fmt.Errorf("set startDelayToleranceSec: %w", err)
 			}
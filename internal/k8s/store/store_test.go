@@ -137,6 +137,45 @@ func TestCreateDuplicateStreamURL(t *testing.T) {
 	}
 }
 
+func TestCreateDuplicateVideoID(t *testing.T) {
+	// Monitors are deduplicated by video ID, so another URL form of the
+	// same video, or an object created before spec.videoID existed,
+	// still conflicts.
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.Create(ctx, CreateMonitorParams{
+		ID:           "mon-1",
+		StreamURL:    "https://youtu.be/dQw4w9WgXcQ",
+		CallbackURL:  "https://example.com/cb",
+		Config:       model.DefaultMonitorConfig(),
+		InitialPhase: model.StatusInitializing,
+	}); err != nil {
+		t.Fatalf("first Create() error = %v", err)
+	}
+	waitInCache(t, s, "mon-1")
+
+	got, err := s.GetByID(ctx, "mon-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.VideoID != "dQw4w9WgXcQ" {
+		t.Fatalf("VideoID = %q, want it derived from the stream URL", got.VideoID)
+	}
+
+	_, err = s.Create(ctx, CreateMonitorParams{
+		ID:           "mon-2",
+		StreamURL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		VideoID:      "dQw4w9WgXcQ",
+		CallbackURL:  "https://example.com/cb",
+		Config:       model.DefaultMonitorConfig(),
+		InitialPhase: model.StatusInitializing,
+	})
+	if !errors.Is(err, ErrDuplicateMonitor) {
+		t.Fatalf("second Create() error = %v, want ErrDuplicateMonitor", err)
+	}
+}
+
 func TestCreateSameStreamURLAfterTerminal(t *testing.T) {
 	// A monitor in a terminal (non-active) phase should not block a new
 	// Create for the same stream URL.
@@ -255,3 +294,100 @@ func TestListNegativeOffsetDoesNotPanic(t *testing.T) {
 		t.Fatalf("len(monitors) = %d, want 1", len(monitors))
 	}
 }
+
+func TestUpdateStatsEventCounters(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.Create(ctx, CreateMonitorParams{
+		ID:           "mon-1",
+		StreamURL:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		CallbackURL:  "https://example.com/cb",
+		Config:       model.DefaultMonitorConfig(),
+		InitialPhase: model.StatusInitializing,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	waitInCache(t, s, "mon-1")
+
+	want := model.MonitorStats{
+		MonitorID:             "mon-1",
+		BlackoutEvents:        1,
+		FreezeEvents:          2,
+		LoudnessEvents:        3,
+		AVDesyncEvents:        4,
+		SlateEvents:           5,
+		ToneEvents:            6,
+		CaptionsEvents:        7,
+		LatencyEvents:         8,
+		PlaylistAnomalyEvents: 9,
+		ChannelSilenceEvents:  10,
+	}
+	if err := s.UpdateStats(ctx, &want); err != nil {
+		t.Fatalf("UpdateStats() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := s.GetWithStats(ctx, "mon-1")
+		if err == nil && got.Stats != nil && *got.Stats == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GetWithStats() = %+v, %v; want stats %+v", got, err, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestToMonitorDefaultsUnsetTunables(t *testing.T) {
+	// An object created before the detection tunables existed.
+	sm := &v1alpha1.StreamMonitor{
+		Spec: v1alpha1.StreamMonitorSpec{
+			StreamURL:        "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			CallbackURL:      "https://example.com/cb",
+			CheckIntervalSec: 10,
+		},
+	}
+	cfg := toMonitor(sm).Config
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want the unset tunables defaulted", err)
+	}
+	if want := model.DefaultMonitorConfig().BlackPixelThreshold; cfg.BlackPixelThreshold != want {
+		t.Errorf("BlackPixelThreshold = %v, want %v", cfg.BlackPixelThreshold, want)
+	}
+}
+
+func TestGetByIDDefaultsStoredZeroTunables(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	// A monitor stored with explicit zeros, as clients were allowed to send.
+	cfg := model.DefaultMonitorConfig()
+	cfg.SilenceDBThreshold = 0
+	cfg.SilenceMinDurationSec = 0
+	cfg.BlackPixelThreshold = 0
+	cfg.FreezeRatioThreshold = 0
+	cfg.SlateMatchThreshold = 0
+	if _, err := s.Create(ctx, CreateMonitorParams{
+		ID:           "mon-zero",
+		StreamURL:    "https://www.youtube.com/watch?v=abc",
+		CallbackURL:  "https://example.com/cb",
+		Config:       cfg,
+		InitialPhase: model.StatusInitializing,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	waitInCache(t, s, "mon-zero")
+
+	got, err := s.GetByID(ctx, "mon-zero")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if err := got.Config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want the stored zeros defaulted", err)
+	}
+	if want := model.DefaultMonitorConfig().SilenceDBThreshold; got.Config.SilenceDBThreshold != want {
+		t.Errorf("SilenceDBThreshold = %v, want %v", got.Config.SilenceDBThreshold, want)
+	}
+}
@@ -0,0 +1,152 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// maxCachedManifests bounds the manifest cache. A worker follows only a
+// handful of playlists, but their URLs change on re-resolution.
+const maxCachedManifests = 16
+
+var (
+	targetDurationPattern      = regexp.MustCompile(`(?m)^#EXT-X-TARGETDURATION:(\d+(?:\.\d+)?)`)
+	partTargetPattern          = regexp.MustCompile(`(?m)^#EXT-X-PART-INF:.*PART-TARGET=(\d+(?:\.\d+)?)`)
+	minimumUpdatePeriodPattern = regexp.MustCompile(`minimumUpdatePeriod="([^"]+)"`)
+)
+
+// cachedManifest is a manifest body with the validators needed to revalidate
+// it.
+type cachedManifest struct {
+	body         []byte
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+	// freshFor is how long the body is reused without asking the server
+	// again; see manifestFreshness.
+	freshFor time.Duration
+}
+
+// manifestFreshness returns how long a manifest can be reused without
+// asking the server again, taken from the timing it advertises. An HLS
+// media playlist is fresh for half its part target (LL-HLS) or target
+// duration, the wait the HLS spec sets before reloading an unchanged
+// playlist, so that the end-of-list check and the segment lookups of one
+// analysis cycle share a single fetch. A DASH MPD is fresh for its
+// minimumUpdatePeriod. Manifests that advertise neither, such as HLS
+// master playlists, are always revalidated.
+func manifestFreshness(body []byte) time.Duration {
+	seconds := func(match [][]byte) float64 {
+		if match == nil {
+			return 0
+		}
+		v, _ := strconv.ParseFloat(string(match[1]), 64)
+		return v
+	}
+	if partTarget := seconds(partTargetPattern.FindSubmatch(body)); partTarget > 0 {
+		return time.Duration(partTarget / 2 * float64(time.Second))
+	}
+	if target := seconds(targetDurationPattern.FindSubmatch(body)); target > 0 {
+		return time.Duration(target / 2 * float64(time.Second))
+	}
+	if match := minimumUpdatePeriodPattern.FindSubmatch(body); match != nil {
+		if period, err := parseXSDuration(string(match[1])); err == nil {
+			return time.Duration(period * float64(time.Second))
+		}
+	}
+	return 0
+}
+
+// fetchManifest returns the body of the manifest at manifestURL. A copy
+// that is still fresh (see manifestFreshness) is returned as is. Otherwise
+// the request
+// is made conditional on the cached copy's ETag or Last-Modified, and a
+// 304 Not Modified response returns the cached body, which yields no new
+// segments.
+func (p *Parser) fetchManifest(ctx context.Context, manifestURL string) ([]byte, error) {
+	p.mu.Lock()
+	cached := p.manifests[manifestURL]
+	p.mu.Unlock()
+	if cached != nil && p.clock().Sub(cached.fetchedAt) < cached.freshFor {
+		return cached.body, nil
+	}
+
+	fetched, err := p.downloadManifest(ctx, manifestURL, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.manifests == nil {
+		p.manifests = make(map[string]*cachedManifest)
+	}
+	if p.manifests[manifestURL] == nil && len(p.manifests) >= maxCachedManifests {
+		p.evictOldestManifest()
+	}
+	p.manifests[manifestURL] = fetched
+	return fetched.body, nil
+}
+
+// evictOldestManifest drops the manifest fetched longest ago. p.mu must be
+// held.
+func (p *Parser) evictOldestManifest() {
+	var oldestURL string
+	var oldest time.Time
+	for manifestURL, cached := range p.manifests {
+		if oldestURL == "" || cached.fetchedAt.Before(oldest) {
+			oldestURL, oldest = manifestURL, cached.fetchedAt
+		}
+	}
+	delete(p.manifests, oldestURL)
+}
+
+// downloadManifest fetches requestURL, revalidating cached when it is
+// non-nil.
+func (p *Parser) downloadManifest(ctx context.Context, requestURL string, cached *cachedManifest) (*cachedManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	now := p.clock()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		revalidated := *cached
+		revalidated.fetchedAt = now
+		return &revalidated, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("manifest fetch failed with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return &cachedManifest{
+		body:         body,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		fetchedAt:    now,
+		freshFor:     manifestFreshness(body),
+	}, nil
+}
@@ -0,0 +1,184 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManifestCacheSharesFetchWithinCycle(t *testing.T) {
+	master := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1280000,RESOLUTION=1280x720
+media.m3u8
+`
+	media := `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXTINF:4.0,
+seg100.ts
+`
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/master.m3u8":
+			_, _ = w.Write([]byte(master))
+		case "/media.m3u8":
+			_, _ = w.Write([]byte(media))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	now := time.Now()
+	parser.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := parser.IsEndList(ctx, server.URL+"/master.m3u8"); err != nil {
+		t.Fatalf("IsEndList: %v", err)
+	}
+	if _, err := parser.GetLatestSegment(ctx, server.URL+"/master.m3u8"); err != nil {
+		t.Fatalf("GetLatestSegment: %v", err)
+	}
+	// The master playlist advertises no reload timing, so it is always
+	// revalidated; the media playlist is fresh for half its target duration.
+	mu.Lock()
+	if requests["/master.m3u8"] != 2 || requests["/media.m3u8"] != 1 {
+		t.Fatalf("requests = %v, want the media playlist fetched once", requests)
+	}
+	mu.Unlock()
+
+	now = now.Add(2 * time.Second)
+	if _, err := parser.GetLatestSegment(ctx, server.URL+"/master.m3u8"); err != nil {
+		t.Fatalf("GetLatestSegment after expiry: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/media.m3u8"] != 2 {
+		t.Fatalf("requests = %v, want a new fetch once the cache expired", requests)
+	}
+}
+
+func TestManifestCacheConditionalRequests(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXTINF:4.0,
+seg100.ts
+#EXTINF:4.0,
+seg101.ts
+`
+	const (
+		etag         = `"v1"`
+		lastModified = "Wed, 14 Oct 2026 10:00:00 GMT"
+	)
+	var mu sync.Mutex
+	var conditions [][2]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conditions = append(conditions, [2]string{r.Header.Get("If-None-Match"), r.Header.Get("If-Modified-Since")})
+		mu.Unlock()
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte(playlist))
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	now := time.Now()
+	parser.now = func() time.Time { return now }
+	ctx := context.Background()
+	manifestURL := server.URL + "/playlist.m3u8"
+
+	segments, err := parser.GetVariantSegmentsSince(ctx, manifestURL, VariantSelection{}, 0, 5)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince: %v", err)
+	}
+	if len(segments) != 2 || segments[1].Sequence != 101 {
+		t.Fatalf("unexpected segments: %d", len(segments))
+	}
+
+	// 304 Not Modified reuses the cached playlist: nothing newer than 101.
+	now = now.Add(2 * time.Second)
+	segments, err = parser.GetVariantSegmentsSince(ctx, manifestURL, VariantSelection{}, 101, 5)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince after 304: %v", err)
+	}
+	if len(segments) != 1 || segments[0].Sequence != 101 {
+		t.Fatalf("expected only the latest segment after 304, got %d segments", len(segments))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conditions) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(conditions))
+	}
+	if conditions[0] != [2]string{"", ""} {
+		t.Fatalf("first request was conditional: %q", conditions[0])
+	}
+	if conditions[1] != [2]string{etag, lastModified} {
+		t.Fatalf("second request conditions = %q, want ETag and Last-Modified", conditions[1])
+	}
+}
+
+func TestManifestFreshness(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want time.Duration
+	}{
+		{"media playlist", "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXTINF:6.0,\nseg.ts\n", 3 * time.Second},
+		{"LL-HLS playlist", "#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXT-X-PART-INF:PART-TARGET=1.002\n", 501 * time.Millisecond},
+		{"dynamic MPD", `<MPD type="dynamic" minimumUpdatePeriod="PT2S">`, 2 * time.Second},
+		{"master playlist", "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1280000\nmedia.m3u8\n", 0},
+		{"static MPD", `<MPD type="static">`, 0},
+	}
+	for _, tt := range tests {
+		if got := manifestFreshness([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s: manifestFreshness = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestManifestCacheEvictsOldest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("#EXTM3U\n#EXT-X-TARGETDURATION:60\n#EXTINF:60.0,\nseg.ts\n"))
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	now := time.Now()
+	parser.now = func() time.Time { return now }
+	for i := 0; i <= maxCachedManifests; i++ {
+		if _, err := parser.fetchManifest(context.Background(), fmt.Sprintf("%s/%d.m3u8", server.URL, i)); err != nil {
+			t.Fatalf("fetchManifest(%d): %v", i, err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	// Only the playlist fetched longest ago made room for the new one.
+	if len(parser.manifests) != maxCachedManifests {
+		t.Fatalf("cached %d manifests, want %d", len(parser.manifests), maxCachedManifests)
+	}
+	if _, ok := parser.manifests[server.URL+"/0.m3u8"]; ok {
+		t.Fatal("oldest playlist is still cached")
+	}
+	for i := 1; i <= maxCachedManifests; i++ {
+		if _, ok := parser.manifests[fmt.Sprintf("%s/%d.m3u8", server.URL, i)]; !ok {
+			t.Fatalf("playlist %d was evicted", i)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// PlaylistRule names an HLS media playlist conformance check.
+type PlaylistRule string
+
+const (
+	// RuleSegmentExceedsTargetDuration: an EXTINF duration, rounded to the
+	// nearest second, is longer than EXT-X-TARGETDURATION.
+	RuleSegmentExceedsTargetDuration PlaylistRule = "segment_exceeds_target_duration"
+	// RuleMediaSequenceRegressed: EXT-X-MEDIA-SEQUENCE went backwards
+	// between refreshes.
+	RuleMediaSequenceRegressed PlaylistRule = "media_sequence_regressed"
+	// RuleUnexpectedDiscontinuity: a newly listed segment carries
+	// EXT-X-DISCONTINUITY, i.e. the encoder output was interrupted.
+	RuleUnexpectedDiscontinuity PlaylistRule = "unexpected_discontinuity"
+	// RuleStalePlaylist: the playlist has not gained a segment for longer
+	// than staleTargetDurations target durations.
+	RuleStalePlaylist PlaylistRule = "stale_playlist"
+)
+
+// staleTargetDurations is how many target durations a live playlist may go
+// without a new segment before it counts as stale.
+const staleTargetDurations = 1.5
+
+// PlaylistViolation is a single broken conformance rule.
+type PlaylistViolation struct {
+	Rule PlaylistRule
+	// Sequence is the segment the violation concerns, or the newest
+	// listed segment for playlist-level rules.
+	Sequence uint64
+	Detail   string
+}
+
+// PlaylistValidator checks successive refreshes of one HLS media playlist.
+// The zero value is ready to use; the first refresh only sets the baseline.
+// It is not safe for concurrent use.
+type PlaylistValidator struct {
+	started       bool
+	mediaSequence uint64
+	lastSequence  uint64
+	lastChange    time.Time
+	staleReported bool
+}
+
+// Check validates a refresh given the segments listed in it (oldest first,
+// all from one fetch, normally PlaylistInfo.Segments) and the time the
+// playlist was fetched.
+// Segments without Playlist info (DASH) are not checked.
+func (v *PlaylistValidator) Check(segments []*Segment, fetchedAt time.Time) []PlaylistViolation {
+	if len(segments) == 0 || segments[len(segments)-1].Playlist == nil {
+		return nil
+	}
+	info := segments[len(segments)-1].Playlist
+	newest := segments[len(segments)-1].Sequence
+
+	if !v.started {
+		v.started = true
+		v.mediaSequence = info.MediaSequence
+		v.lastSequence = newest
+		v.lastChange = fetchedAt
+		return nil
+	}
+
+	var violations []PlaylistViolation
+	regressed := info.MediaSequence < v.mediaSequence
+	if regressed {
+		violations = append(violations, PlaylistViolation{
+			Rule:     RuleMediaSequenceRegressed,
+			Sequence: newest,
+			Detail:   fmt.Sprintf("EXT-X-MEDIA-SEQUENCE went from %d to %d", v.mediaSequence, info.MediaSequence),
+		})
+	}
+
+	changed := false
+	for _, segment := range segments {
+		// After a regression the numbering restarted, so every listed
+		// segment is new.
+		if !regressed && segment.Sequence <= v.lastSequence {
+			continue
+		}
+		changed = true
+		if info.TargetDuration > 0 && math.Round(segment.Duration) > info.TargetDuration {
+			violations = append(violations, PlaylistViolation{
+				Rule:     RuleSegmentExceedsTargetDuration,
+				Sequence: segment.Sequence,
+				Detail:   fmt.Sprintf("EXTINF %gs exceeds EXT-X-TARGETDURATION %gs", segment.Duration, info.TargetDuration),
+			})
+		}
+		if segment.Discontinuity {
+			violations = append(violations, PlaylistViolation{
+				Rule:     RuleUnexpectedDiscontinuity,
+				Sequence: segment.Sequence,
+				Detail:   fmt.Sprintf("EXT-X-DISCONTINUITY before segment %d", segment.Sequence),
+			})
+		}
+	}
+
+	if changed {
+		v.lastChange = fetchedAt
+		v.staleReported = false
+	} else if info.TargetDuration > 0 && !v.staleReported {
+		limit := time.Duration(info.TargetDuration * staleTargetDurations * float64(time.Second))
+		if unchanged := fetchedAt.Sub(v.lastChange); unchanged > limit {
+			v.staleReported = true
+			violations = append(violations, PlaylistViolation{
+				Rule:     RuleStalePlaylist,
+				Sequence: newest,
+				Detail:   fmt.Sprintf("no new segment for %.1fs (limit %.1fs)", unchanged.Seconds(), limit.Seconds()),
+			})
+		}
+	}
+
+	v.mediaSequence = info.MediaSequence
+	if changed {
+		v.lastSequence = newest
+	}
+	return violations
+}
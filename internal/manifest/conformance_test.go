@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"testing"
+	"time"
+)
+
+// refresh builds the segments returned for one playlist fetch.
+func refresh(mediaSequence uint64, durations ...float64) []*Segment {
+	info := &PlaylistInfo{TargetDuration: 4, MediaSequence: mediaSequence}
+	segments := make([]*Segment, len(durations))
+	for i, d := range durations {
+		segments[i] = &Segment{Sequence: mediaSequence + uint64(i), Duration: d, Playlist: info}
+	}
+	return segments
+}
+
+func rules(violations []PlaylistViolation) []PlaylistRule {
+	out := make([]PlaylistRule, len(violations))
+	for i, v := range violations {
+		out[i] = v.Rule
+	}
+	return out
+}
+
+func TestPlaylistValidatorSegmentExceedsTargetDuration(t *testing.T) {
+	var v PlaylistValidator
+	now := time.Now()
+	// The baseline is never reported, even when it breaks a rule.
+	if got := v.Check(refresh(10, 4, 6), now); len(got) != 0 {
+		t.Fatalf("expected no violations on the first refresh, got %v", got)
+	}
+
+	// 4.4 rounds to 4 and is allowed; 4.6 rounds to 5 and is not.
+	got := v.Check(refresh(10, 4, 6, 4.4, 4.6), now.Add(4*time.Second))
+	if len(got) != 1 || got[0].Rule != RuleSegmentExceedsTargetDuration || got[0].Sequence != 13 {
+		t.Fatalf("expected segment 13 to exceed the target duration, got %+v", got)
+	}
+}
+
+func TestPlaylistValidatorMediaSequenceRegressed(t *testing.T) {
+	var v PlaylistValidator
+	now := time.Now()
+	v.Check(refresh(100, 4, 4), now)
+
+	got := v.Check(refresh(5, 4), now.Add(4*time.Second))
+	if len(got) != 1 || got[0].Rule != RuleMediaSequenceRegressed {
+		t.Fatalf("expected a media sequence regression, got %v", rules(got))
+	}
+	// The new numbering is the baseline from here on.
+	if got := v.Check(refresh(5, 4, 4), now.Add(8*time.Second)); len(got) != 0 {
+		t.Fatalf("expected no violations after the regression, got %v", rules(got))
+	}
+}
+
+func TestPlaylistValidatorUnexpectedDiscontinuity(t *testing.T) {
+	var v PlaylistValidator
+	now := time.Now()
+	first := refresh(10, 4, 4)
+	first[1].Discontinuity = true
+	v.Check(first, now)
+
+	second := refresh(10, 4, 4, 4)
+	second[1].Discontinuity = true
+	second[2].Discontinuity = true
+	got := v.Check(second, now.Add(4*time.Second))
+	// Only the newly listed segment is reported.
+	if len(got) != 1 || got[0].Rule != RuleUnexpectedDiscontinuity || got[0].Sequence != 12 {
+		t.Fatalf("expected a discontinuity before segment 12, got %+v", got)
+	}
+}
+
+func TestPlaylistValidatorStalePlaylist(t *testing.T) {
+	var v PlaylistValidator
+	now := time.Now()
+	v.Check(refresh(10, 4, 4), now)
+
+	// Unchanged within 1.5 x 4s is fine.
+	if got := v.Check(refresh(10, 4, 4), now.Add(5*time.Second)); len(got) != 0 {
+		t.Fatalf("expected no violations within the limit, got %v", rules(got))
+	}
+	got := v.Check(refresh(10, 4, 4), now.Add(7*time.Second))
+	if len(got) != 1 || got[0].Rule != RuleStalePlaylist {
+		t.Fatalf("expected a stale playlist, got %v", rules(got))
+	}
+	// Reported once per stale run.
+	if got := v.Check(refresh(10, 4, 4), now.Add(12*time.Second)); len(got) != 0 {
+		t.Fatalf("expected the stale playlist to be reported once, got %v", rules(got))
+	}
+	v.Check(refresh(10, 4, 4, 4), now.Add(13*time.Second))
+	if got := v.Check(refresh(10, 4, 4, 4), now.Add(20*time.Second)); len(got) != 1 || got[0].Rule != RuleStalePlaylist {
+		t.Fatalf("expected a new stale run to be reported, got %v", rules(got))
+	}
+}
+
+func TestPlaylistValidatorIgnoresDASH(t *testing.T) {
+	var v PlaylistValidator
+	segments := []*Segment{{Sequence: 1, Duration: 30}}
+	v.Check(segments, time.Now())
+	if got := v.Check(segments, time.Now().Add(time.Hour)); got != nil {
+		t.Fatalf("expected DASH segments to be ignored, got %v", rules(got))
+	}
+}
@@ -0,0 +1,897 @@
+package manifest
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DASH support covers the three segment addressing schemes (SegmentTemplate
+// with @duration or a SegmentTimeline, SegmentList, and SegmentBase indexed
+// by a sidx box), multi-Period MPDs and the availability window of dynamic
+// MPDs. Addressing elements are inherited from Period and AdaptationSet.
+
+type dashMPD struct {
+	XMLName                   xml.Name     `xml:"MPD"`
+	Type                      string       `xml:"type,attr"`
+	BaseURL                   string       `xml:"BaseURL"`
+	MediaPresentationDuration string       `xml:"mediaPresentationDuration,attr"`
+	AvailabilityStartTime     string       `xml:"availabilityStartTime,attr"`
+	TimeShiftBufferDepth      string       `xml:"timeShiftBufferDepth,attr"`
+	Periods                   []dashPeriod `xml:"Period"`
+}
+
+// dashSegmentInfo holds the elements that may appear at Period,
+// AdaptationSet and Representation level, the most specific winning.
+type dashSegmentInfo struct {
+	BaseURL         string               `xml:"BaseURL"`
+	SegmentTemplate *dashSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *dashSegmentList     `xml:"SegmentList"`
+	SegmentBase     *dashSegmentBase     `xml:"SegmentBase"`
+}
+
+type dashPeriod struct {
+	ID       string `xml:"id,attr"`
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	dashSegmentInfo
+	AdaptationSets []dashAdaptationSet `xml:"AdaptationSet"`
+}
+
+type dashAdaptationSet struct {
+	dashSegmentInfo
+	Representations []dashRepresentation `xml:"Representation"`
+}
+
+type dashRepresentation struct {
+	ID        string `xml:"id,attr"`
+	Bandwidth int64  `xml:"bandwidth,attr"`
+	Height    int    `xml:"height,attr"`
+	dashSegmentInfo
+}
+
+type dashSegmentTemplate struct {
+	Timescale              int64                `xml:"timescale,attr"`
+	Duration               int64                `xml:"duration,attr"`
+	StartNumber            int64                `xml:"startNumber,attr"`
+	PresentationTimeOffset int64                `xml:"presentationTimeOffset,attr"`
+	Media                  string               `xml:"media,attr"`
+	Initialization         string               `xml:"initialization,attr"`
+	SegmentTimeline        *dashSegmentTimeline `xml:"SegmentTimeline"`
+}
+
+type dashSegmentList struct {
+	Timescale              int64                `xml:"timescale,attr"`
+	Duration               int64                `xml:"duration,attr"`
+	StartNumber            int64                `xml:"startNumber,attr"`
+	PresentationTimeOffset int64                `xml:"presentationTimeOffset,attr"`
+	Initialization         *dashURL             `xml:"Initialization"`
+	SegmentTimeline        *dashSegmentTimeline `xml:"SegmentTimeline"`
+	SegmentURLs            []dashSegmentURL     `xml:"SegmentURL"`
+}
+
+type dashSegmentURL struct {
+	Media      string `xml:"media,attr"`
+	MediaRange string `xml:"mediaRange,attr"`
+}
+
+type dashSegmentBase struct {
+	Timescale              int64    `xml:"timescale,attr"`
+	PresentationTimeOffset int64    `xml:"presentationTimeOffset,attr"`
+	IndexRange             string   `xml:"indexRange,attr"`
+	Initialization         *dashURL `xml:"Initialization"`
+}
+
+type dashURL struct {
+	SourceURL string `xml:"sourceURL,attr"`
+	Range     string `xml:"range,attr"`
+}
+
+type dashSegmentTimeline struct {
+	Segments []dashSegmentTimelineS `xml:"S"`
+}
+
+type dashSegmentTimelineS struct {
+	// T is nil when the S element has no @t and follows on from the
+	// previous one.
+	T *int64 `xml:"t,attr"`
+	D int64  `xml:"d,attr"`
+	R int64  `xml:"r,attr"`
+}
+
+// inherit fills attributes missing from t with those of the template it
+// inherits from (the AdaptationSet or Period one).
+func (t *dashSegmentTemplate) inherit(parent *dashSegmentTemplate) *dashSegmentTemplate {
+	if t == nil {
+		return parent
+	}
+	if parent == nil {
+		return t
+	}
+	merged := *t
+	if merged.Timescale == 0 {
+		merged.Timescale = parent.Timescale
+	}
+	if merged.Duration == 0 {
+		merged.Duration = parent.Duration
+	}
+	if merged.StartNumber == 0 {
+		merged.StartNumber = parent.StartNumber
+	}
+	if merged.PresentationTimeOffset == 0 {
+		merged.PresentationTimeOffset = parent.PresentationTimeOffset
+	}
+	if merged.Media == "" {
+		merged.Media = parent.Media
+	}
+	if merged.Initialization == "" {
+		merged.Initialization = parent.Initialization
+	}
+	if merged.SegmentTimeline == nil {
+		merged.SegmentTimeline = parent.SegmentTimeline
+	}
+	return &merged
+}
+
+// dashPresentation is the timing context of the period being monitored.
+// Times are in seconds.
+type dashPresentation struct {
+	dynamic bool
+	// availabilityStart is zero when the MPD has none.
+	availabilityStart time.Time
+	// timeShiftBufferDepth is 0 when unbounded.
+	timeShiftBufferDepth float64
+	period               *dashPeriod
+	periodStart          float64
+	// periodDuration is 0 when unknown (an open-ended live period).
+	periodDuration float64
+	// liveEdge is the presentation time, relative to the period start, up
+	// to which a dynamic MPD's segments are available.
+	liveEdge float64
+}
+
+// newDASHPresentation picks the period to monitor: for a dynamic MPD the
+// last period that has started by now, otherwise the last period.
+func newDASHPresentation(mpd *dashMPD, now time.Time) (*dashPresentation, error) {
+	if len(mpd.Periods) == 0 {
+		return nil, fmt.Errorf("mpd has no period")
+	}
+	pres := &dashPresentation{dynamic: mpd.Type == "dynamic"}
+	if mpd.AvailabilityStartTime != "" {
+		ast, err := time.Parse(time.RFC3339Nano, mpd.AvailabilityStartTime)
+		if err != nil {
+			return nil, fmt.Errorf("parse availabilityStartTime: %w", err)
+		}
+		pres.availabilityStart = ast
+	}
+	if pres.dynamic && pres.availabilityStart.IsZero() {
+		return nil, fmt.Errorf("dynamic mpd without availabilityStartTime")
+	}
+	if mpd.TimeShiftBufferDepth != "" {
+		depth, err := parseXSDuration(mpd.TimeShiftBufferDepth)
+		if err != nil {
+			return nil, fmt.Errorf("parse timeShiftBufferDepth: %w", err)
+		}
+		pres.timeShiftBufferDepth = depth
+	}
+	var presentationDuration float64
+	if mpd.MediaPresentationDuration != "" {
+		total, err := parseXSDuration(mpd.MediaPresentationDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mediaPresentationDuration: %w", err)
+		}
+		presentationDuration = total
+	}
+
+	// A period without @start begins where the previous one ends.
+	starts := make([]float64, len(mpd.Periods))
+	durations := make([]float64, len(mpd.Periods))
+	for i := range mpd.Periods {
+		period := &mpd.Periods[i]
+		if period.Start != "" {
+			start, err := parseXSDuration(period.Start)
+			if err != nil {
+				return nil, fmt.Errorf("parse period start: %w", err)
+			}
+			starts[i] = start
+		} else if i > 0 {
+			starts[i] = starts[i-1] + durations[i-1]
+		}
+		if period.Duration != "" {
+			duration, err := parseXSDuration(period.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("parse period duration: %w", err)
+			}
+			durations[i] = duration
+		}
+	}
+	for i := range durations {
+		if durations[i] > 0 {
+			continue
+		}
+		if i+1 < len(starts) && mpd.Periods[i+1].Start != "" {
+			durations[i] = starts[i+1] - starts[i]
+		} else if i == len(durations)-1 && presentationDuration > 0 {
+			durations[i] = presentationDuration - starts[i]
+		}
+	}
+
+	chosen := len(mpd.Periods) - 1
+	var elapsed float64
+	if pres.dynamic {
+		elapsed = now.Sub(pres.availabilityStart).Seconds()
+		for chosen > 0 && starts[chosen] > elapsed {
+			chosen--
+		}
+	}
+	pres.period = &mpd.Periods[chosen]
+	pres.periodStart = starts[chosen]
+	pres.periodDuration = math.Max(durations[chosen], 0)
+	pres.liveEdge = elapsed - pres.periodStart
+	return pres, nil
+}
+
+// periodWallClock returns the wall-clock start of the period, or zero when
+// the MPD has no availabilityStartTime.
+func (pres *dashPresentation) periodWallClock() time.Time {
+	if pres.availabilityStart.IsZero() {
+		return time.Time{}
+	}
+	return pres.availabilityStart.Add(secondsToDuration(pres.periodStart))
+}
+
+// window returns the media-time bounds, for the given timescale and
+// presentationTimeOffset, that segment end times must fall within. A bound
+// of -1 is open.
+func (pres *dashPresentation) window(timescale, pto int64) (from, to int64) {
+	from, to = -1, -1
+	if !pres.dynamic {
+		return from, to
+	}
+	to = pto + int64(math.Floor(pres.liveEdge*float64(timescale)))
+	if pres.timeShiftBufferDepth > 0 {
+		from = to - int64(math.Ceil(pres.timeShiftBufferDepth*float64(timescale)))
+	}
+	return from, to
+}
+
+// dashRendition is the representation being monitored with its inherited
+// addressing.
+type dashRendition struct {
+	set            *dashAdaptationSet
+	representation *dashRepresentation
+	template       *dashSegmentTemplate
+	list           *dashSegmentList
+	base           *dashSegmentBase
+	baseURL        string
+}
+
+// selectDASHRendition returns the representation of period matching the
+// selection. Every representation with a known addressing scheme, or at
+// least a BaseURL of its own, is a candidate.
+func selectDASHRendition(mpd *dashMPD, period *dashPeriod, selection VariantSelection, manifestURL string) (*dashRendition, error) {
+	var renditions []*dashRendition
+	var candidates []variantCandidate
+	for i := range period.AdaptationSets {
+		set := &period.AdaptationSets[i]
+		for j := range set.Representations {
+			rep := &set.Representations[j]
+			rendition := &dashRendition{
+				set:            set,
+				representation: rep,
+				template:       rep.SegmentTemplate.inherit(set.SegmentTemplate.inherit(period.SegmentTemplate)),
+				list:           firstNonNil(rep.SegmentList, set.SegmentList, period.SegmentList),
+				base:           firstNonNil(rep.SegmentBase, set.SegmentBase, period.SegmentBase),
+			}
+			if rendition.template == nil && rendition.list == nil && rendition.base == nil && rep.BaseURL == "" {
+				continue
+			}
+			renditions = append(renditions, rendition)
+			candidates = append(candidates, variantCandidate{Bandwidth: rep.Bandwidth, Height: rep.Height})
+		}
+	}
+	if len(renditions) == 0 {
+		return nil, fmt.Errorf("no representation with segment addressing")
+	}
+	chosen := renditions[selection.pick(candidates)]
+	if chosen.template != nil && chosen.template.Media == "" {
+		return nil, fmt.Errorf("segment template media is empty")
+	}
+
+	// BaseURL resolves level by level: MPD, Period, AdaptationSet,
+	// Representation.
+	baseURL := manifestURL
+	for _, ref := range []string{mpd.BaseURL, period.BaseURL, chosen.set.BaseURL, chosen.representation.BaseURL} {
+		if strings.TrimSpace(ref) == "" {
+			continue
+		}
+		resolved, err := resolveRelativeBaseURL(baseURL, strings.TrimSpace(ref))
+		if err != nil {
+			return nil, err
+		}
+		baseURL = resolved
+	}
+	chosen.baseURL = baseURL
+	return chosen, nil
+}
+
+func firstNonNil[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func resolveRelativeBaseURL(baseURL, ref string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	return base.ResolveReference(refURL).String(), nil
+}
+
+// getDASHSegments retrieves up to limit of the newest available segments
+// of the selected representation, oldest first.
+func (p *Parser) getDASHSegments(ctx context.Context, manifestURL string, selection VariantSelection, limit int) ([]*Segment, error) {
+	body, err := p.fetchManifest(ctx, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var mpd dashMPD
+	if err := xml.Unmarshal(body, &mpd); err != nil {
+		return nil, fmt.Errorf("decode mpd: %w", err)
+	}
+
+	pres, err := newDASHPresentation(&mpd, p.clock())
+	if err != nil {
+		return nil, err
+	}
+	rendition, err := selectDASHRendition(&mpd, pres.period, selection, manifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*Segment
+	switch {
+	case rendition.template != nil:
+		segments, err = buildDASHTemplateSegments(pres, rendition, limit)
+	case rendition.list != nil:
+		segments, err = buildDASHListSegments(pres, rendition, limit)
+	default:
+		segments, err = p.buildDASHBaseSegments(ctx, pres, rendition, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("no available segments in mpd")
+	}
+	init, err := rendition.initSegment()
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		segment.MediaType = "dash"
+		segment.Bandwidth = rendition.representation.Bandwidth
+		segment.Height = rendition.representation.Height
+		segment.Init = init
+	}
+	return segments, nil
+}
+
+// initSegment returns the rendition's initialization section, or nil when
+// the MPD does not declare one.
+func (r *dashRendition) initSegment() (*Segment, error) {
+	var initialization *dashURL
+	switch {
+	case r.template != nil:
+		if r.template.Initialization == "" {
+			return nil, nil
+		}
+		initURL, err := fillSegmentTemplate(r.baseURL, r.template.Initialization, r.representation, 0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("resolve init segment URL: %w", err)
+		}
+		return &Segment{URL: initURL}, nil
+	case r.list != nil:
+		initialization = r.list.Initialization
+	case r.base != nil:
+		initialization = r.base.Initialization
+	}
+	if initialization == nil {
+		return nil, nil
+	}
+
+	init := &Segment{URL: r.baseURL}
+	if initialization.SourceURL != "" {
+		base, err := url.Parse(r.baseURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse base url: %w", err)
+		}
+		if init.URL, err = resolveURL(base, initialization.SourceURL); err != nil {
+			return nil, fmt.Errorf("resolve init segment URL: %w", err)
+		}
+	}
+	if initialization.Range != "" {
+		byteRange, err := parseByteRange(initialization.Range)
+		if err != nil {
+			return nil, fmt.Errorf("initialization range: %w", err)
+		}
+		init.ByteRange = byteRange
+	}
+	return init, nil
+}
+
+// dashRun is a run of consecutive segments of equal duration. start and
+// duration are in timescale units on the media timeline, which includes
+// presentationTimeOffset; index is the position of the first segment.
+type dashRun struct {
+	index    int64
+	start    int64
+	duration int64
+	count    int64
+}
+
+// timelineRuns expands a SegmentTimeline. An S element with a negative @r
+// repeats up to the next S@t or, failing that, to end; end of -1 means
+// unknown, in which case the element is not repeated.
+func timelineRuns(timeline *dashSegmentTimeline, end int64) ([]dashRun, error) {
+	var runs []dashRun
+	var index, t int64
+	for i, s := range timeline.Segments {
+		if s.D <= 0 {
+			return nil, fmt.Errorf("segment timeline duration missing")
+		}
+		// @t may jump forward, leaving a gap in the timeline.
+		if s.T != nil {
+			t = *s.T
+		}
+		count := 1 + max(s.R, 0)
+		if s.R < 0 {
+			limit := end
+			if i+1 < len(timeline.Segments) && timeline.Segments[i+1].T != nil {
+				limit = *timeline.Segments[i+1].T
+			}
+			count = 1
+			if limit > t {
+				count = (limit - t + s.D - 1) / s.D
+			}
+		}
+		runs = append(runs, dashRun{index: index, start: t, duration: s.D, count: count})
+		index += count
+		t += s.D * count
+	}
+	return runs, nil
+}
+
+// windowRuns keeps the segments that end within [from, to], bounds of -1
+// being open, and then only the newest limit of them.
+func windowRuns(runs []dashRun, from, to int64, limit int) []dashRun {
+	var kept []dashRun
+	for _, run := range runs {
+		first, last := int64(0), run.count
+		if to >= 0 {
+			last = min(last, max((to-run.start)/run.duration, 0))
+		}
+		if from >= 0 && from > run.start {
+			first = max(first, (from-run.start+run.duration-1)/run.duration-1)
+		}
+		if first >= last {
+			continue
+		}
+		kept = append(kept, dashRun{
+			index:    run.index + first,
+			start:    run.start + first*run.duration,
+			duration: run.duration,
+			count:    last - first,
+		})
+	}
+
+	remaining := int64(limit)
+	for i := len(kept) - 1; i >= 0; i-- {
+		if remaining <= 0 {
+			return kept[i+1:]
+		}
+		if kept[i].count > remaining {
+			skip := kept[i].count - remaining
+			kept[i].index += skip
+			kept[i].start += skip * kept[i].duration
+			kept[i].count = remaining
+		}
+		remaining -= kept[i].count
+	}
+	return kept
+}
+
+// dashTiming converts run positions to segments.
+type dashTiming struct {
+	timescale   int64
+	pto         int64
+	startNumber int64
+	// wallClock is the period's wall-clock start, or zero.
+	wallClock time.Time
+}
+
+func newDASHTiming(pres *dashPresentation, timescale, pto, startNumber int64) dashTiming {
+	if timescale <= 0 {
+		timescale = 1
+	}
+	if startNumber <= 0 {
+		startNumber = 1
+	}
+	return dashTiming{timescale: timescale, pto: pto, startNumber: startNumber, wallClock: pres.periodWallClock()}
+}
+
+// segments builds one Segment per run position; fill sets its URL and
+// byte range.
+func (t dashTiming) segments(runs []dashRun, fill func(segment *Segment, index, number, start int64) error) ([]*Segment, error) {
+	var segments []*Segment
+	for _, run := range runs {
+		for k := int64(0); k < run.count; k++ {
+			index := run.index + k
+			start := run.start + k*run.duration
+			segment := &Segment{
+				Duration: float64(run.duration) / float64(t.timescale),
+				Sequence: uint64(t.startNumber + index),
+			}
+			if !t.wallClock.IsZero() {
+				offset := float64(start-t.pto) / float64(t.timescale)
+				segment.ProgramDateTime = t.wallClock.Add(secondsToDuration(offset))
+			}
+			if err := fill(segment, index, t.startNumber+index, start); err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+		}
+	}
+	return segments, nil
+}
+
+// buildDASHTemplateSegments lists the newest segments addressed by a
+// SegmentTemplate, with either a SegmentTimeline or a fixed @duration.
+func buildDASHTemplateSegments(pres *dashPresentation, rendition *dashRendition, limit int) ([]*Segment, error) {
+	template := rendition.template
+	timing := newDASHTiming(pres, template.Timescale, template.PresentationTimeOffset, template.StartNumber)
+	runs, err := dashAddressingRuns(pres, timing, template.SegmentTimeline, template.Duration, -1)
+	if err != nil {
+		return nil, err
+	}
+	from, to := pres.window(timing.timescale, timing.pto)
+	return timing.segments(windowRuns(runs, from, to, limit), func(segment *Segment, _, number, start int64) error {
+		segmentURL, err := fillSegmentTemplate(rendition.baseURL, template.Media, rendition.representation, uint64(number), start)
+		segment.URL = segmentURL
+		return err
+	})
+}
+
+// buildDASHListSegments lists the newest segments of a SegmentList.
+func buildDASHListSegments(pres *dashPresentation, rendition *dashRendition, limit int) ([]*Segment, error) {
+	list := rendition.list
+	if len(list.SegmentURLs) == 0 {
+		return nil, fmt.Errorf("segment list is empty")
+	}
+	timing := newDASHTiming(pres, list.Timescale, list.PresentationTimeOffset, list.StartNumber)
+	runs, err := dashAddressingRuns(pres, timing, list.SegmentTimeline, list.Duration, int64(len(list.SegmentURLs)))
+	if err != nil {
+		return nil, err
+	}
+	from, to := pres.window(timing.timescale, timing.pto)
+	return timing.segments(windowRuns(runs, from, to, limit), func(segment *Segment, index, _, _ int64) error {
+		entry := list.SegmentURLs[index]
+		segmentURL := rendition.baseURL
+		if entry.Media != "" {
+			base, err := url.Parse(rendition.baseURL)
+			if err != nil {
+				return fmt.Errorf("parse base url: %w", err)
+			}
+			if segmentURL, err = resolveURL(base, entry.Media); err != nil {
+				return fmt.Errorf("resolve segment URL: %w", err)
+			}
+		}
+		segment.URL = segmentURL
+		if entry.MediaRange != "" {
+			byteRange, err := parseByteRange(entry.MediaRange)
+			if err != nil {
+				return fmt.Errorf("segment %d mediaRange: %w", index, err)
+			}
+			segment.ByteRange = byteRange
+		}
+		return nil
+	})
+}
+
+// dashAddressingRuns returns the runs of a SegmentTemplate or SegmentList:
+// its SegmentTimeline if it has one, otherwise a single run of @duration
+// segments covering the period (or, for an open-ended live period, up to
+// the live edge). maxCount caps the number of segments; -1 is no cap.
+func dashAddressingRuns(pres *dashPresentation, timing dashTiming, timeline *dashSegmentTimeline, duration, maxCount int64) ([]dashRun, error) {
+	timescale := timing.timescale
+	periodEnd := int64(-1)
+	if pres.periodDuration > 0 {
+		periodEnd = timing.pto + int64(math.Ceil(pres.periodDuration*float64(timescale)))
+	} else if pres.dynamic {
+		_, periodEnd = pres.window(timescale, timing.pto)
+	}
+
+	var runs []dashRun
+	if timeline != nil && len(timeline.Segments) > 0 {
+		var err error
+		if runs, err = timelineRuns(timeline, periodEnd); err != nil {
+			return nil, err
+		}
+	} else {
+		if duration <= 0 {
+			return nil, fmt.Errorf("segment duration missing")
+		}
+		var count int64
+		switch {
+		case maxCount >= 0:
+			count = maxCount
+		case periodEnd >= 0:
+			count = max((periodEnd-timing.pto+duration-1)/duration, 1)
+		default:
+			return nil, fmt.Errorf("mediaPresentationDuration missing")
+		}
+		runs = []dashRun{{start: timing.pto, duration: duration, count: count}}
+	}
+
+	if maxCount >= 0 {
+		capped := runs[:0]
+		for _, run := range runs {
+			if run.index >= maxCount {
+				break
+			}
+			run.count = min(run.count, maxCount-run.index)
+			capped = append(capped, run)
+		}
+		runs = capped
+	}
+	return runs, nil
+}
+
+// buildDASHBaseSegments lists the newest subsegments of a SegmentBase
+// representation from its sidx index. Without an indexRange the whole
+// resource is a single segment.
+func (p *Parser) buildDASHBaseSegments(ctx context.Context, pres *dashPresentation, rendition *dashRendition, limit int) ([]*Segment, error) {
+	base := rendition.base
+	if base == nil || base.IndexRange == "" {
+		return []*Segment{{
+			URL:             rendition.baseURL,
+			Duration:        pres.periodDuration,
+			Sequence:        1,
+			ProgramDateTime: pres.periodWallClock(),
+		}}, nil
+	}
+
+	indexRange, err := parseByteRange(base.IndexRange)
+	if err != nil {
+		return nil, fmt.Errorf("segment base indexRange: %w", err)
+	}
+	data, err := p.fetchRange(ctx, rendition.baseURL, indexRange)
+	if err != nil {
+		return nil, fmt.Errorf("fetch segment index: %w", err)
+	}
+	index, err := parseSidx(data, indexRange.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	timescale := base.Timescale
+	if timescale <= 0 {
+		timescale = int64(index.timescale)
+	}
+	runs := make([]dashRun, len(index.references))
+	ranges := make([]*ByteRange, len(index.references))
+	start, offset := int64(index.earliestPresentationTime), index.firstOffset
+	for i, ref := range index.references {
+		runs[i] = dashRun{index: int64(i), start: start, duration: int64(ref.duration), count: 1}
+		ranges[i] = &ByteRange{Offset: offset, Length: int64(ref.size)}
+		start += int64(ref.duration)
+		offset += int64(ref.size)
+	}
+
+	timing := newDASHTiming(pres, timescale, base.PresentationTimeOffset, 1)
+	from, to := pres.window(timing.timescale, timing.pto)
+	return timing.segments(windowRuns(runs, from, to, limit), func(segment *Segment, i, _, _ int64) error {
+		segment.URL = rendition.baseURL
+		segment.ByteRange = ranges[i]
+		return nil
+	})
+}
+
+// sidxIndex is the content of a Segment Index box (ISO/IEC 14496-12 8.16.3).
+type sidxIndex struct {
+	timescale                uint32
+	earliestPresentationTime uint64
+	// firstOffset is the absolute offset of the first subsegment.
+	firstOffset int64
+	references  []sidxReference
+}
+
+type sidxReference struct {
+	size     uint32
+	duration uint32
+}
+
+// parseSidx finds and parses the sidx box in data, which was read from
+// dataOffset within the resource.
+func parseSidx(data []byte, dataOffset int64) (*sidxIndex, error) {
+	for pos := 0; pos+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		boxType := string(data[pos+4 : pos+8])
+		if size < 8 || pos+size > len(data) {
+			return nil, fmt.Errorf("truncated %q box in segment index", boxType)
+		}
+		if boxType != "sidx" {
+			pos += size
+			continue
+		}
+		box := data[pos+8 : pos+size]
+		if len(box) < 4 {
+			return nil, fmt.Errorf("truncated sidx box")
+		}
+		version := box[0]
+		body := box[4:]
+		index := &sidxIndex{}
+		var need int
+		if version == 0 {
+			need = 4 + 4 + 4 + 4 + 4
+		} else {
+			need = 4 + 4 + 8 + 8 + 4
+		}
+		if len(body) < need {
+			return nil, fmt.Errorf("truncated sidx box")
+		}
+		index.timescale = binary.BigEndian.Uint32(body[4:])
+		var firstOffset uint64
+		if version == 0 {
+			index.earliestPresentationTime = uint64(binary.BigEndian.Uint32(body[8:]))
+			firstOffset = uint64(binary.BigEndian.Uint32(body[12:]))
+			body = body[16:]
+		} else {
+			index.earliestPresentationTime = binary.BigEndian.Uint64(body[8:])
+			firstOffset = binary.BigEndian.Uint64(body[16:])
+			body = body[24:]
+		}
+		count := int(binary.BigEndian.Uint16(body[2:]))
+		body = body[4:]
+		if len(body) < count*12 {
+			return nil, fmt.Errorf("truncated sidx references")
+		}
+		if index.timescale == 0 {
+			return nil, fmt.Errorf("sidx timescale is zero")
+		}
+		// Offsets count from the first byte after the sidx box.
+		index.firstOffset = dataOffset + int64(pos+size) + int64(firstOffset)
+		for i := 0; i < count; i++ {
+			ref := body[i*12:]
+			typeAndSize := binary.BigEndian.Uint32(ref)
+			if typeAndSize&0x80000000 != 0 {
+				return nil, fmt.Errorf("hierarchical sidx is not supported")
+			}
+			duration := binary.BigEndian.Uint32(ref[4:])
+			if duration == 0 {
+				return nil, fmt.Errorf("sidx subsegment duration is zero")
+			}
+			index.references = append(index.references, sidxReference{
+				size:     typeAndSize & 0x7fffffff,
+				duration: duration,
+			})
+		}
+		if len(index.references) == 0 {
+			return nil, fmt.Errorf("sidx has no references")
+		}
+		return index, nil
+	}
+	return nil, fmt.Errorf("no sidx box in segment index")
+}
+
+// parseByteRange parses a DASH byte range ("first-last", inclusive).
+func parseByteRange(s string) (*ByteRange, error) {
+	first, last, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid byte range %q", s)
+	}
+	start, err1 := strconv.ParseInt(first, 10, 64)
+	end, err2 := strconv.ParseInt(last, 10, 64)
+	if err1 != nil || err2 != nil || start < 0 || end < start {
+		return nil, fmt.Errorf("invalid byte range %q", s)
+	}
+	return &ByteRange{Offset: start, Length: end - start + 1}, nil
+}
+
+// dashTemplatePattern matches template identifiers, with an optional
+// printf width such as $Number%05d$, and the $$ escape.
+var dashTemplatePattern = regexp.MustCompile(`\$(?:(Number|Time|RepresentationID|Bandwidth)(?:%0(\d+)d)?)?\$`)
+
+func fillSegmentTemplate(baseURL string, media string, representation *dashRepresentation, number uint64, timeValue int64) (string, error) {
+	replaced := dashTemplatePattern.ReplaceAllStringFunc(media, func(match string) string {
+		parts := dashTemplatePattern.FindStringSubmatch(match)
+		var value string
+		switch parts[1] {
+		case "":
+			return "$"
+		case "Number":
+			value = strconv.FormatUint(number, 10)
+		case "Time":
+			value = strconv.FormatInt(timeValue, 10)
+		case "RepresentationID":
+			if representation != nil {
+				return representation.ID
+			}
+			return ""
+		case "Bandwidth":
+			if representation != nil {
+				value = strconv.FormatInt(representation.Bandwidth, 10)
+			}
+		}
+		if width, err := strconv.Atoi(parts[2]); err == nil && len(value) < width {
+			value = strings.Repeat("0", width-len(value)) + value
+		}
+		return value
+	})
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse base url: %w", err)
+	}
+	return resolveURL(base, replaced)
+}
+
+// Supported subset: days + integer hours/minutes + fractional seconds.
+// Weeks (PnW) and fractional hours/minutes are not supported.
+var mpdDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+var mpdDurationDaysOnlyPattern = regexp.MustCompile(`^P(\d+)D$`)
+
+// parseXSDuration parses an xs:duration value in seconds.
+func parseXSDuration(value string) (float64, error) {
+	daysOnly := mpdDurationDaysOnlyPattern.FindStringSubmatch(value)
+	if daysOnly != nil {
+		days, err := strconv.ParseFloat(daysOnly[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse days: %w", err)
+		}
+		return days * 24 * 3600, nil
+	}
+	match := mpdDurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var total float64
+	for i, unit := range []float64{24 * 3600, 3600, 60, 1} {
+		if match[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse duration %q: %w", value, err)
+		}
+		total += v * unit
+	}
+	return total, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func isDASHManifestURL(manifestURL string) bool {
+	parsed, err := url.Parse(manifestURL)
+	if err != nil {
+		return strings.HasSuffix(strings.ToLower(manifestURL), ".mpd")
+	}
+	return strings.HasSuffix(strings.ToLower(parsed.Path), ".mpd")
+}
@@ -0,0 +1,177 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Eyevinn/hls-m3u8/m3u8"
+)
+
+// HLS EXT-X-KEY methods.
+const (
+	EncryptionAES128    = "AES-128"
+	EncryptionSampleAES = "SAMPLE-AES"
+)
+
+// maxCachedKeys bounds the parser's key cache. Live streams that rotate keys
+// keep requesting new ones, so the cache is dropped when it fills up.
+const maxCachedKeys = 64
+
+// SegmentKey describes how an HLS segment is encrypted (EXT-X-KEY).
+type SegmentKey struct {
+	Method string
+	// URI is the resolved key URL.
+	URI string
+	// IV is the 16-byte initialization vector. When nil, the segment's
+	// media sequence number is used as the spec requires.
+	IV        []byte
+	KeyFormat string
+}
+
+// supported reports whether FetchSegment can decrypt segments with this key.
+func (k *SegmentKey) supported() bool {
+	return k.Method == EncryptionAES128 && isIdentityKeyFormat(k.KeyFormat)
+}
+
+// UnsupportedEncryptionError is returned by FetchSegment for segments
+// encrypted with a method or key format that cannot be decrypted here, such
+// as SAMPLE-AES or a DRM key system. The segment is not downloaded.
+type UnsupportedEncryptionError struct {
+	Method    string
+	KeyFormat string
+	// MissingIV is set for an AES-128 initialization section whose key has
+	// no IV attribute.
+	MissingIV bool
+}
+
+func (e *UnsupportedEncryptionError) Error() string {
+	if e.MissingIV {
+		return fmt.Sprintf("unsupported segment encryption: method %s initialization section without IV", e.Method)
+	}
+	if e.KeyFormat != "" && !isIdentityKeyFormat(e.KeyFormat) {
+		return fmt.Sprintf("unsupported segment encryption: method %s, key format %s", e.Method, e.KeyFormat)
+	}
+	return fmt.Sprintf("unsupported segment encryption: method %s", e.Method)
+}
+
+func isIdentityKeyFormat(format string) bool {
+	return format == "" || format == "identity"
+}
+
+// hlsSegmentKey resolves the EXT-X-KEY tags in effect for a segment. When
+// several key formats are offered, the identity one is preferred. It
+// returns nil when segments are not encrypted (METHOD=NONE).
+func hlsSegmentKey(baseURL *url.URL, keys []m3u8.Key) (*SegmentKey, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	chosen := keys[0]
+	for _, key := range keys {
+		if isIdentityKeyFormat(key.Keyformat) {
+			chosen = key
+			break
+		}
+	}
+	if chosen.Method == "" || strings.EqualFold(chosen.Method, "NONE") {
+		return nil, nil
+	}
+
+	key := &SegmentKey{Method: chosen.Method, KeyFormat: chosen.Keyformat}
+	if chosen.URI != "" {
+		keyURL, err := resolveURL(baseURL, chosen.URI)
+		if err != nil {
+			return nil, fmt.Errorf("resolve key URL: %w", err)
+		}
+		key.URI = keyURL
+	}
+	if chosen.IV != "" {
+		iv, err := parseHLSIV(chosen.IV)
+		if err != nil {
+			return nil, err
+		}
+		key.IV = iv
+	}
+	return key, nil
+}
+
+// parseHLSIV parses an EXT-X-KEY IV attribute, a 128-bit hexadecimal value.
+func parseHLSIV(value string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	iv, err := hex.DecodeString(digits)
+	if err != nil || len(iv) > aes.BlockSize {
+		return nil, fmt.Errorf("invalid key IV %q", value)
+	}
+	// Left-pad values written without leading zeros.
+	return append(make([]byte, aes.BlockSize-len(iv)), iv...), nil
+}
+
+// decryptSegment decrypts an AES-128 segment, fetching its key if needed.
+func (p *Parser) decryptSegment(ctx context.Context, segment *Segment, data []byte) ([]byte, error) {
+	key, err := p.segmentKey(ctx, segment.Key.URI)
+	if err != nil {
+		return nil, err
+	}
+	iv := segment.Key.IV
+	if iv == nil {
+		iv = make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint64(iv[8:], segment.Sequence)
+	}
+	return decryptAES128(key, iv, data)
+}
+
+// segmentKey returns the AES-128 key at keyURL, from the cache when it has
+// been fetched before. Keys are fetched with the same client, and so the
+// same outbound URL checks, as segments.
+func (p *Parser) segmentKey(ctx context.Context, keyURL string) ([]byte, error) {
+	if keyURL == "" {
+		return nil, fmt.Errorf("encrypted segment has no key URI")
+	}
+	p.mu.Lock()
+	key, ok := p.keys[keyURL]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := p.fetchRange(ctx, keyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch key: %w", err)
+	}
+	if len(key) != aes.BlockSize {
+		return nil, fmt.Errorf("key is %d bytes, want %d", len(key), aes.BlockSize)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys == nil || len(p.keys) >= maxCachedKeys {
+		p.keys = make(map[string][]byte)
+	}
+	p.keys[keyURL] = key
+	return key, nil
+}
+
+// decryptAES128 decrypts AES-128-CBC data with PKCS#7 padding.
+func decryptAES128(key, iv, data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted segment length %d is not a multiple of the block size", len(data))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, fmt.Errorf("decrypt segment: invalid padding, wrong key or IV")
+	}
+	return plain[:len(plain)-padding], nil
+}
@@ -0,0 +1,296 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testSegmentKey = []byte("0123456789abcdef")
+
+// encryptAES128 encrypts data as an HLS AES-128 segment.
+func encryptAES128(t *testing.T, key, iv, data []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("create cipher: %v", err)
+	}
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+	return encrypted
+}
+
+func sequenceIV(sequence uint64) []byte {
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv[8:], sequence)
+	return iv
+}
+
+func TestFetchSegmentDecryptsAES128(t *testing.T) {
+	explicitIV := bytes.Repeat([]byte{0xab}, aes.BlockSize)
+	playlist := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:7
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin"
+#EXTINF:4.0,
+seg7.ts
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0xabababababababababababababababab
+#EXTINF:4.0,
+seg8.ts
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:4.0,
+seg9.ts
+`
+	var keyRequests atomic.Int32
+	segments := map[string][]byte{
+		"/seg7.ts": encryptAES128(t, testSegmentKey, sequenceIV(7), []byte("segment seven")),
+		"/seg8.ts": encryptAES128(t, testSegmentKey, explicitIV, []byte("segment eight")),
+		"/seg9.ts": []byte("segment nine"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live.m3u8":
+			w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+			_, _ = w.Write([]byte(playlist))
+		case "/key.bin":
+			keyRequests.Add(1)
+			_, _ = w.Write(testSegmentKey)
+		default:
+			data, ok := segments[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	got, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/live.m3u8", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(got))
+	}
+	if got[0].Key == nil || got[0].Key.URI != server.URL+"/key.bin" || got[0].Key.IV != nil {
+		t.Fatalf("unexpected key for first segment: %+v", got[0].Key)
+	}
+	if got[1].Key == nil || !bytes.Equal(got[1].Key.IV, explicitIV) {
+		t.Fatalf("unexpected key for second segment: %+v", got[1].Key)
+	}
+	if got[2].Key != nil {
+		t.Fatalf("expected no key after METHOD=NONE, got %+v", got[2].Key)
+	}
+
+	want := []string{"segment seven", "segment eight", "segment nine"}
+	for i, segment := range got {
+		data, err := parser.FetchSegment(context.Background(), segment)
+		if err != nil {
+			t.Fatalf("FetchSegment(%s): %v", segment.URL, err)
+		}
+		if string(data) != want[i] {
+			t.Fatalf("FetchSegment(%s) = %q, want %q", segment.URL, data, want[i])
+		}
+	}
+	if n := keyRequests.Load(); n != 1 {
+		t.Fatalf("expected key to be fetched once, got %d requests", n)
+	}
+}
+
+func TestFetchSegmentUnsupportedEncryption(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:1
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXTINF:4.0,
+seg1.ts
+`
+	var segmentRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/live.m3u8" {
+			_, _ = w.Write([]byte(playlist))
+			return
+		}
+		segmentRequests.Add(1)
+		_, _ = w.Write([]byte("ciphertext"))
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	segment, err := parser.GetLatestSegment(context.Background(), server.URL+"/live.m3u8")
+	if err != nil {
+		t.Fatalf("GetLatestSegment: %v", err)
+	}
+	_, err = parser.FetchSegment(context.Background(), segment)
+	var unsupported *UnsupportedEncryptionError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedEncryptionError, got %v", err)
+	}
+	if unsupported.Method != EncryptionSampleAES || unsupported.KeyFormat != "com.apple.streamingkeydelivery" {
+		t.Fatalf("unexpected error details: %+v", unsupported)
+	}
+	if n := segmentRequests.Load(); n != 0 {
+		t.Fatalf("expected segment not to be downloaded, got %d requests", n)
+	}
+}
+
+func TestFetchSegmentRejectsPrivateKeyURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testSegmentKey)
+	}))
+	defer server.Close()
+
+	parser := NewParser(5 * time.Second)
+	if _, err := parser.segmentKey(context.Background(), server.URL+"/key.bin"); err == nil {
+		t.Fatal("expected key fetch from a loopback address to be rejected")
+	}
+}
+
+func TestDecryptAES128RejectsWrongKey(t *testing.T) {
+	encrypted := encryptAES128(t, testSegmentKey, sequenceIV(1), []byte("segment"))
+	if _, err := decryptAES128([]byte("fedcba9876543210"), sequenceIV(1), encrypted); err == nil {
+		t.Fatal("expected decryption with the wrong key to fail")
+	}
+}
+
+func TestFetchSegmentDecryptsLLHLSPartWithParentSequence(t *testing.T) {
+	playlist := `#EXTM3U
+#EXT-X-VERSION:9
+#EXT-X-TARGETDURATION:4
+#EXT-X-PART-INF:PART-TARGET=1.0
+#EXT-X-MEDIA-SEQUENCE:19
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin"
+#EXTINF:4.0,
+seg19.ts
+#EXT-X-PART:DURATION=1.0,URI="seg20.0.ts"
+#EXTINF:4.0,
+seg20.ts
+#EXT-X-PART:DURATION=1.0,URI="seg21.0.ts"
+`
+	parts := map[string][]byte{
+		"/seg20.0.ts": encryptAES128(t, testSegmentKey, sequenceIV(20), []byte("part of twenty")),
+		"/seg21.0.ts": encryptAES128(t, testSegmentKey, sequenceIV(21), []byte("part of twenty-one")),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live.m3u8":
+			_, _ = w.Write([]byte(playlist))
+		case "/key.bin":
+			_, _ = w.Write(testSegmentKey)
+		default:
+			data, ok := parts[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	got, err := parser.GetVariantPartsSince(context.Background(), server.URL+"/live.m3u8", VariantSelection{}, 19, -1, 10)
+	if err != nil {
+		t.Fatalf("GetVariantPartsSince: %v", err)
+	}
+	// A part without IV uses the media sequence number of its parent, both
+	// for a listed segment and for the one still in progress.
+	want := []string{"part of twenty", "part of twenty-one"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d parts, got %d", len(want), len(got))
+	}
+	for i, part := range got {
+		if part.Key == nil || part.Key.IV != nil {
+			t.Fatalf("part %s key = %+v, want AES-128 without IV", part.URL, part.Key)
+		}
+		data, err := parser.FetchSegment(context.Background(), part)
+		if err != nil {
+			t.Fatalf("FetchSegment(%s): %v", part.URL, err)
+		}
+		if string(data) != want[i] {
+			t.Fatalf("FetchSegment(%s) = %q, want %q", part.URL, data, want[i])
+		}
+	}
+}
+
+func TestFetchSegmentEncryptedInitSection(t *testing.T) {
+	explicitIV := bytes.Repeat([]byte{0xcd}, aes.BlockSize)
+	var initRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/with-iv.m3u8":
+			_, _ = w.Write([]byte(`#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:3
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0xcdcdcdcdcdcdcdcdcdcdcdcdcdcdcdcd
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:4.0,
+seg3.m4s
+`))
+		case "/without-iv.m3u8":
+			_, _ = w.Write([]byte(`#EXTM3U
+#EXT-X-VERSION:6
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:3
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin"
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:4.0,
+seg3.m4s
+`))
+		case "/key.bin":
+			_, _ = w.Write(testSegmentKey)
+		case "/init.mp4":
+			initRequests.Add(1)
+			_, _ = w.Write(encryptAES128(t, testSegmentKey, explicitIV, []byte("init section")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	segment, err := parser.GetLatestSegment(context.Background(), server.URL+"/with-iv.m3u8")
+	if err != nil {
+		t.Fatalf("GetLatestSegment: %v", err)
+	}
+	if segment.Init == nil || segment.Init.Key == nil || !bytes.Equal(segment.Init.Key.IV, explicitIV) {
+		t.Fatalf("init section = %+v, want the AES-128 key with its IV", segment.Init)
+	}
+	data, err := parser.FetchSegment(context.Background(), segment.Init)
+	if err != nil {
+		t.Fatalf("FetchSegment(init): %v", err)
+	}
+	if string(data) != "init section" {
+		t.Fatalf("FetchSegment(init) = %q, want %q", data, "init section")
+	}
+
+	// Without an IV there is no media sequence number to fall back on.
+	initRequests.Store(0)
+	segment, err = parser.GetLatestSegment(context.Background(), server.URL+"/without-iv.m3u8")
+	if err != nil {
+		t.Fatalf("GetLatestSegment: %v", err)
+	}
+	_, err = parser.FetchSegment(context.Background(), segment.Init)
+	var unsupported *UnsupportedEncryptionError
+	if !errors.As(err, &unsupported) || !unsupported.MissingIV || unsupported.Method != EncryptionAES128 {
+		t.Fatalf("expected UnsupportedEncryptionError for a missing IV, got %v", err)
+	}
+	if n := initRequests.Load(); n != 0 {
+		t.Fatalf("expected init section not to be downloaded, got %d requests", n)
+	}
+}
@@ -2,9 +2,13 @@ package manifest
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetLatestSegmentHLSMediaPlaylist(t *testing.T) {
@@ -248,3 +252,256 @@ segC.ts
 		t.Fatalf("segment sequence = %d, want 2", segment.Sequence)
 	}
 }
+
+func TestGetLatestSegmentHLSMasterPlaylistVariantSelection(t *testing.T) {
+	master := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1000000,RESOLUTION=1280x720
+media/720p.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=500000,RESOLUTION=854x480
+media/480p.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000000,RESOLUTION=1920x1080
+media/1080p.m3u8
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		switch r.URL.Path {
+		case "/master.m3u8":
+			_, _ = w.Write([]byte(master))
+		case "/media/480p.m3u8", "/media/720p.m3u8", "/media/1080p.m3u8":
+			name := strings.TrimSuffix(path.Base(r.URL.Path), ".m3u8")
+			_, _ = fmt.Fprintf(w, "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:50\n#EXTINF:6.0,\n%s_seg50.ts\n", name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		selection string
+		wantURL   string
+	}{
+		{"lowest", "/media/480p_seg50.ts"},
+		{"highest", "/media/1080p_seg50.ts"},
+		{"closest_to_height:700", "/media/720p_seg50.ts"},
+		{"bandwidth:3000000", "/media/1080p_seg50.ts"},
+		{"900000", "/media/720p_seg50.ts"},
+	}
+	for _, tt := range tests {
+		selection, err := ParseVariantSelection(tt.selection)
+		if err != nil {
+			t.Fatalf("ParseVariantSelection(%q) error: %v", tt.selection, err)
+		}
+		parser := newTestParser()
+		parser.SetVariantSelection(selection)
+		segment, err := parser.GetLatestSegment(context.Background(), server.URL+"/master.m3u8")
+		if err != nil {
+			t.Fatalf("%s: GetLatestSegment error: %v", tt.selection, err)
+		}
+		if segment.URL != server.URL+tt.wantURL {
+			t.Fatalf("%s: segment URL = %s, want %s", tt.selection, segment.URL, server.URL+tt.wantURL)
+		}
+	}
+}
+
+func TestGetLatestVariantSegmentHLSReportsRendition(t *testing.T) {
+	master := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=1000000,RESOLUTION=1280x720
+720p.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000000,RESOLUTION=1920x1080
+1080p.m3u8
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		switch r.URL.Path {
+		case "/master.m3u8":
+			_, _ = w.Write([]byte(master))
+		case "/720p.m3u8", "/1080p.m3u8":
+			name := strings.TrimSuffix(path.Base(r.URL.Path), ".m3u8")
+			_, _ = fmt.Fprintf(w, "#EXTM3U\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:7\n#EXTINF:6.0,\n%s_seg7.ts\n", name)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// The parser's own selection must not leak into explicit variant requests.
+	parser := newTestParser()
+	parser.SetVariantSelection(VariantSelection{Mode: VariantHighest})
+
+	segment, err := parser.GetLatestVariantSegment(context.Background(), server.URL+"/master.m3u8", VariantSelection{Mode: VariantClosestToHeight, Height: 720})
+	if err != nil {
+		t.Fatalf("GetLatestVariantSegment error: %v", err)
+	}
+	if segment.URL != server.URL+"/720p_seg7.ts" {
+		t.Fatalf("segment URL = %s, want %s", segment.URL, server.URL+"/720p_seg7.ts")
+	}
+	if segment.Bandwidth != 1000000 || segment.Height != 720 {
+		t.Fatalf("rendition = %d bps/%dp, want 1000000 bps/720p", segment.Bandwidth, segment.Height)
+	}
+
+	segment, err = parser.GetLatestSegment(context.Background(), server.URL+"/master.m3u8")
+	if err != nil {
+		t.Fatalf("GetLatestSegment error: %v", err)
+	}
+	if segment.Height != 1080 {
+		t.Fatalf("segment height = %d, want 1080", segment.Height)
+	}
+}
+
+func TestGetVariantSegmentsSinceHLS(t *testing.T) {
+	m3u8 := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:100
+#EXTINF:2.0,
+segment100.ts
+#EXTINF:2.0,
+segment101.ts
+#EXTINF:2.0,
+segment102.ts
+#EXTINF:2.0,
+segment103.ts
+#EXTINF:2.0,
+segment104.ts
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(m3u8))
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	tests := []struct {
+		after uint64
+		limit int
+		want  []uint64
+	}{
+		{after: 101, limit: 10, want: []uint64{102, 103, 104}},
+		{after: 50, limit: 2, want: []uint64{103, 104}},
+		{after: 104, limit: 10, want: []uint64{104}},
+		{after: 200, limit: 10, want: []uint64{104}},
+		{after: 101, limit: 0, want: []uint64{104}},
+	}
+	for _, tt := range tests {
+		segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/playlist.m3u8", VariantSelection{}, tt.after, tt.limit)
+		if err != nil {
+			t.Fatalf("after=%d limit=%d: GetVariantSegmentsSince error: %v", tt.after, tt.limit, err)
+		}
+		var got []uint64
+		for _, seg := range segments {
+			got = append(got, seg.Sequence)
+			if want := fmt.Sprintf("%s/segment%d.ts", server.URL, seg.Sequence); seg.URL != want {
+				t.Fatalf("after=%d limit=%d: segment URL = %s, want %s", tt.after, tt.limit, seg.URL, want)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Fatalf("after=%d limit=%d: sequences = %v, want %v", tt.after, tt.limit, got, tt.want)
+		}
+		// The playlist still lists every segment of the refresh.
+		if info := segments[len(segments)-1].Playlist; info == nil || len(info.Segments) != 5 || info.Segments[0].Sequence != 100 {
+			t.Fatalf("after=%d limit=%d: Playlist.Segments = %+v, want 100..104", tt.after, tt.limit, info)
+		}
+	}
+}
+
+func TestGetVariantSegmentsSinceHLSProgramDateTime(t *testing.T) {
+	m3u8 := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:10
+#EXTINF:4.0,
+segment10.ts
+#EXT-X-PROGRAM-DATE-TIME:2024-01-15T11:00:00.000Z
+#EXTINF:4.0,
+segment11.ts
+#EXTINF:2.5,
+segment12.ts
+#EXT-X-DISCONTINUITY
+#EXTINF:4.0,
+segment13.ts
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(m3u8))
+	}))
+	defer server.Close()
+
+	segments, err := newTestParser().GetVariantSegmentsSince(context.Background(), server.URL+"/live.m3u8", VariantSelection{}, 9, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	if len(segments) != 4 {
+		t.Fatalf("got %d segments, want 4", len(segments))
+	}
+	if !segments[3].Discontinuity || segments[2].Discontinuity {
+		t.Fatalf("expected only segment 13 to follow a discontinuity")
+	}
+	if info := segments[0].Playlist; info == nil || info.TargetDuration != 4 || info.MediaSequence != 10 {
+		t.Fatalf("Playlist = %+v, want target duration 4 and media sequence 10", info)
+	}
+	pdt := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+	want := []time.Time{{}, pdt, pdt.Add(4 * time.Second), {}}
+	for i, segment := range segments {
+		if !segment.ProgramDateTime.Equal(want[i]) {
+			t.Fatalf("segment %d ProgramDateTime = %v, want %v", segment.Sequence, segment.ProgramDateTime, want[i])
+		}
+	}
+}
+
+func TestGetVariantSegmentsSinceHLSInitSegment(t *testing.T) {
+	m3u8 := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:2
+#EXT-X-MEDIA-SEQUENCE:1
+#EXT-X-MAP:URI="init.mp4",BYTERANGE="720@0"
+#EXTINF:2.0,
+seg1.m4s
+#EXTINF:2.0,
+seg2.m4s
+#EXT-X-DISCONTINUITY
+#EXT-X-MAP:URI="init2.mp4"
+#EXTINF:2.0,
+seg3.m4s
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(m3u8))
+	}))
+	defer server.Close()
+
+	segments, err := newTestParser().GetVariantSegmentsSince(context.Background(), server.URL+"/live/playlist.m3u8", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	if len(segments) != 3 {
+		t.Fatalf("got %d segments, want 3", len(segments))
+	}
+	for _, segment := range segments[:2] {
+		init := segment.Init
+		if init == nil || init.URL != server.URL+"/live/init.mp4" || init.ByteRange == nil || *init.ByteRange != (ByteRange{Offset: 0, Length: 720}) {
+			t.Fatalf("segment %d init = %+v, want init.mp4 bytes 0-719", segment.Sequence, init)
+		}
+	}
+	if init := segments[2].Init; init == nil || init.URL != server.URL+"/live/init2.mp4" || init.ByteRange != nil {
+		t.Fatalf("segment 3 init = %+v, want whole init2.mp4", init)
+	}
+}
+
+func TestGetLatestSegmentHLSTransportStreamHasNoInit(t *testing.T) {
+	m3u8 := `#EXTM3U
+#EXT-X-TARGETDURATION:2
+#EXTINF:2.0,
+seg1.ts
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(m3u8))
+	}))
+	defer server.Close()
+
+	segment, err := newTestParser().GetLatestSegment(context.Background(), server.URL+"/playlist.m3u8")
+	if err != nil {
+		t.Fatalf("GetLatestSegment error: %v", err)
+	}
+	if segment.Init != nil {
+		t.Fatalf("Init = %+v, want nil", segment.Init)
+	}
+}
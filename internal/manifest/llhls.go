@@ -0,0 +1,168 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Eyevinn/hls-m3u8/m3u8"
+)
+
+// ErrNoPartialSegments is returned by GetVariantPartsSince when the media
+// playlist is not Low-Latency HLS, or the manifest is DASH.
+var ErrNoPartialSegments = errors.New("playlist has no partial segments")
+
+// GetVariantPartsSince returns the LL-HLS partial segments of the selected
+// rendition that come after part afterPart of media segment afterSequence,
+// oldest first. A negative afterPart means after the whole of afterSequence.
+// At most limit parts are returned; when more are available the oldest are
+// dropped. When no part is newer the latest part is returned on its own so
+// that callers still receive the refreshed playlist.
+//
+// Once the media playlist has advertised CAN-BLOCK-RELOAD, the request is a
+// blocking reload (_HLS_msn/_HLS_part) that the server holds until the next
+// part is available, so a caller polling in a loop receives each part as
+// soon as it is published.
+func (p *Parser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*Segment, error) {
+	if isDASHManifestURL(manifestURL) {
+		return nil, ErrNoPartialSegments
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	target := &hlsReloadTarget{msn: afterSequence, part: afterPart + 1}
+	if afterPart < 0 {
+		target = &hlsReloadTarget{msn: afterSequence + 1}
+	}
+	media, err := p.fetchHLSMediaPlaylist(ctx, manifestURL, selection, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(media.parts) == 0 {
+		return nil, ErrNoPartialSegments
+	}
+
+	var newer []*Segment
+	for _, part := range media.parts {
+		if part.Sequence > afterSequence || (part.Sequence == afterSequence && afterPart >= 0 && part.PartIndex > afterPart) {
+			newer = append(newer, part)
+		}
+	}
+	if len(newer) == 0 {
+		return media.parts[len(media.parts)-1:], nil
+	}
+	if len(newer) > limit {
+		newer = newer[len(newer)-limit:]
+	}
+	return newer, nil
+}
+
+// hlsReloadTarget is the position a blocking playlist reload waits for:
+// part of media segment msn.
+type hlsReloadTarget struct {
+	msn  uint64
+	part int
+}
+
+// apply adds the blocking reload query parameters to a media playlist URL.
+func (t *hlsReloadTarget) apply(mediaURL string) string {
+	separator := "?"
+	if strings.Contains(mediaURL, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%s_HLS_msn=%d&_HLS_part=%d", mediaURL, separator, t.msn, t.part)
+}
+
+func (p *Parser) canBlockReload(mediaURL string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.blockingReload[mediaURL]
+}
+
+func (p *Parser) setCanBlockReload(mediaURL string, enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !enabled {
+		delete(p.blockingReload, mediaURL)
+		return
+	}
+	if p.blockingReload == nil {
+		p.blockingReload = make(map[string]bool)
+	}
+	p.blockingReload[mediaURL] = true
+}
+
+// extractPartialSegments lists a media playlist's EXT-X-PART partial
+// segments in playlist order. Parts of a listed segment share its
+// initialization section, key and program date time; parts of the segment
+// still being produced follow on from the last listed one. init and key are
+// the initialization section and key in effect at the end of the playlist.
+// Parts marked GAP are left out but still counted in PartIndex.
+func extractPartialSegments(mediapl *m3u8.MediaPlaylist, baseURL *url.URL, segments []*Segment, init *Segment, key *SegmentKey) ([]*Segment, error) {
+	if len(mediapl.PartialSegments) == 0 {
+		return nil, nil
+	}
+	bySequence := make(map[uint64]*Segment, len(segments))
+	for _, segment := range segments {
+		bySequence[segment.Sequence] = segment
+	}
+	last := segments[len(segments)-1]
+
+	var (
+		parts    []*Segment
+		started  bool
+		sequence uint64
+		index    int
+		start    time.Time
+		partInit *Segment
+		partKey  *SegmentKey
+	)
+	for _, ps := range mediapl.PartialSegments {
+		if ps == nil {
+			continue
+		}
+		if !started || ps.SeqID != sequence {
+			started = true
+			sequence, index = ps.SeqID, 0
+			start, partInit, partKey = time.Time{}, init, key
+			if parent, ok := bySequence[sequence]; ok {
+				start, partInit, partKey = parent.ProgramDateTime, parent.Init, parent.Key
+			} else if sequence == last.Sequence+1 && !last.ProgramDateTime.IsZero() {
+				start = last.ProgramDateTime.Add(secondsToDuration(last.Duration))
+			}
+		}
+		if !ps.ProgramDateTime.IsZero() {
+			start = ps.ProgramDateTime
+		}
+		if !ps.Gap {
+			partURL, err := resolveURL(baseURL, ps.URI)
+			if err != nil {
+				return nil, fmt.Errorf("resolve partial segment URL: %w", err)
+			}
+			part := &Segment{
+				URL:             partURL,
+				Duration:        ps.Duration,
+				Sequence:        sequence,
+				MediaType:       "hls",
+				ProgramDateTime: start,
+				Playlist:        last.Playlist,
+				Partial:         true,
+				PartIndex:       index,
+				Init:            partInit,
+				Key:             partKey,
+			}
+			if ps.Limit > 0 {
+				part.ByteRange = &ByteRange{Offset: ps.Offset, Length: ps.Limit}
+			}
+			parts = append(parts, part)
+		}
+		if !start.IsZero() {
+			start = start.Add(secondsToDuration(ps.Duration))
+		}
+		index++
+	}
+	return parts, nil
+}
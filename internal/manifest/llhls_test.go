@@ -0,0 +1,157 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const llhlsPlaylist = `#EXTM3U
+#EXT-X-VERSION:9
+#EXT-X-TARGETDURATION:4
+#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=3.0
+#EXT-X-PART-INF:PART-TARGET=1.0
+#EXT-X-MEDIA-SEQUENCE:100
+#EXT-X-MAP:URI="init.mp4"
+#EXT-X-PROGRAM-DATE-TIME:2024-01-15T11:00:00Z
+#EXTINF:4.0,
+seg100.m4s
+#EXT-X-PART:DURATION=1.0,URI="seg101.0.m4s",INDEPENDENT=YES
+#EXT-X-PART:DURATION=1.0,URI="seg101.1.m4s"
+#EXT-X-PART:DURATION=1.0,URI="seg101.2.m4s"
+#EXT-X-PART:DURATION=1.0,URI="seg101.3.m4s"
+#EXTINF:4.0,
+seg101.m4s
+#EXT-X-PART:DURATION=1.0,URI="seg102.0.m4s",INDEPENDENT=YES
+#EXT-X-PART:DURATION=1.0,URI="seg102.1.m4s"
+#EXT-X-PART:DURATION=1.0,URI="seg102.2.m4s",BYTERANGE="500@1000"
+#EXT-X-PRELOAD-HINT:TYPE=PART,URI="seg102.3.m4s"
+`
+
+// recordingPlaylistServer serves playlist and records the query string of
+// every request.
+func recordingPlaylistServer(t *testing.T, playlist string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		_, _ = w.Write([]byte(playlist))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), queries...)
+	}
+}
+
+func TestGetVariantPartsSince(t *testing.T) {
+	server, _ := recordingPlaylistServer(t, llhlsPlaylist)
+	parts, err := newTestParser().GetVariantPartsSince(context.Background(), server.URL+"/live/playlist.m3u8", VariantSelection{}, 101, 2, 10)
+	if err != nil {
+		t.Fatalf("GetVariantPartsSince error: %v", err)
+	}
+	// After part 2 of 101: part 3 of 101, then the parts of 102 so far.
+	want := []string{
+		server.URL + "/live/seg101.3.m4s",
+		server.URL + "/live/seg102.0.m4s",
+		server.URL + "/live/seg102.1.m4s",
+		server.URL + "/live/seg102.2.m4s",
+	}
+	if got := segmentURLs(parts); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("part URLs = %v, want %v", got, want)
+	}
+	last := parts[3]
+	if !last.Partial || last.Sequence != 102 || last.PartIndex != 2 || last.Duration != 1 {
+		t.Fatalf("last part = %+v, want part 2 of 102 lasting 1s", last)
+	}
+	if last.ByteRange == nil || *last.ByteRange != (ByteRange{Offset: 1000, Length: 500}) {
+		t.Fatalf("ByteRange = %+v, want 1000+500", last.ByteRange)
+	}
+	if last.Init == nil || last.Init.URL != server.URL+"/live/init.mp4" {
+		t.Fatalf("Init = %+v, want init.mp4", last.Init)
+	}
+	// 102 starts after 100 and 101 (8s); part 2 is 2s into it.
+	if want := time.Date(2024, 1, 15, 11, 0, 10, 0, time.UTC); !last.ProgramDateTime.Equal(want) {
+		t.Fatalf("ProgramDateTime = %v, want %v", last.ProgramDateTime, want)
+	}
+	if info := last.Playlist; info == nil || info.PartTargetDuration != 1 || !info.CanBlockReload {
+		t.Fatalf("Playlist = %+v, want PART-TARGET 1 with blocking reload", info)
+	}
+
+	// Only the newest parts fit the limit; a whole segment skips its parts.
+	parts, err = newTestParser().GetVariantPartsSince(context.Background(), server.URL+"/live/playlist.m3u8", VariantSelection{}, 101, -1, 1)
+	if err != nil {
+		t.Fatalf("GetVariantPartsSince error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].URL != server.URL+"/live/seg102.2.m4s" {
+		t.Fatalf("part URLs = %v, want [seg102.2.m4s]", segmentURLs(parts))
+	}
+
+	// With nothing newer the latest part comes back with the playlist.
+	parts, err = newTestParser().GetVariantPartsSince(context.Background(), server.URL+"/live/playlist.m3u8", VariantSelection{}, 102, 2, 10)
+	if err != nil {
+		t.Fatalf("GetVariantPartsSince error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].URL != server.URL+"/live/seg102.2.m4s" {
+		t.Fatalf("part URLs = %v, want [seg102.2.m4s]", segmentURLs(parts))
+	}
+	if info := parts[0].Playlist; info == nil || len(info.Segments) != 2 {
+		t.Fatalf("Playlist = %+v, want the two full segments", info)
+	}
+}
+
+func TestGetVariantPartsSinceBlockingReload(t *testing.T) {
+	server, queries := recordingPlaylistServer(t, llhlsPlaylist)
+	parser := newTestParser()
+	manifestURL := server.URL + "/live/playlist.m3u8?token=abc"
+
+	for _, position := range []struct {
+		sequence uint64
+		part     int
+	}{{0, -1}, {102, 2}, {102, -1}} {
+		if _, err := parser.GetVariantPartsSince(context.Background(), manifestURL, VariantSelection{}, position.sequence, position.part, 3); err != nil {
+			t.Fatalf("GetVariantPartsSince error: %v", err)
+		}
+	}
+	// Blocking reloads are only requested once the playlist advertised them.
+	want := []string{
+		"token=abc",
+		"token=abc&_HLS_msn=102&_HLS_part=3",
+		"token=abc&_HLS_msn=103&_HLS_part=0",
+	}
+	if got := queries(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("queries = %v, want %v", got, want)
+	}
+}
+
+func TestGetVariantPartsSinceWithoutParts(t *testing.T) {
+	server, queries := recordingPlaylistServer(t, `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:100
+#EXTINF:4.0,
+seg100.ts
+`)
+	parser := newTestParser()
+	now := time.Now()
+	parser.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		// Let the cached playlist expire so each call reaches the server.
+		now = now.Add(2 * time.Second)
+		_, err := parser.GetVariantPartsSince(context.Background(), server.URL+"/playlist.m3u8", VariantSelection{}, 100, -1, 1)
+		if !errors.Is(err, ErrNoPartialSegments) {
+			t.Fatalf("error = %v, want ErrNoPartialSegments", err)
+		}
+	}
+	if got := queries(); fmt.Sprint(got) != fmt.Sprint([]string{"", ""}) {
+		t.Fatalf("queries = %q, want no blocking reload", got)
+	}
+}
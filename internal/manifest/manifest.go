@@ -1,17 +1,16 @@
 package manifest
 
 import (
+	"bytes"
 	"context"
-	"encoding/xml"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Eyevinn/hls-m3u8/m3u8"
@@ -25,12 +24,86 @@ type Segment struct {
 	Duration  float64
 	Sequence  uint64
 	MediaType string // "hls" or "dash"
+	// Bandwidth and Height describe the rendition the segment was taken
+	// from, as advertised by the manifest. Both are 0 when unknown, e.g.
+	// for an HLS media playlist fetched directly.
+	Bandwidth int64
+	Height    int
+	// ProgramDateTime is the wall-clock time of the segment's first sample,
+	// from EXT-X-PROGRAM-DATE-TIME or the DASH availabilityStartTime. It is
+	// zero when the manifest does not say.
+	ProgramDateTime time.Time
+	// Discontinuity is set when an HLS segment follows EXT-X-DISCONTINUITY.
+	Discontinuity bool
+	// Playlist describes the HLS media playlist the segment was listed in.
+	// It is shared by every segment from the same fetch and nil for DASH.
+	Playlist *PlaylistInfo
+	// ByteRange is set when the segment is only part of the resource at
+	// URL, e.g. a DASH SegmentBase subsegment.
+	ByteRange *ByteRange
+	// Partial marks an LL-HLS partial segment (EXT-X-PART) of media segment
+	// Sequence; PartIndex is its position within that segment.
+	Partial   bool
+	PartIndex int
+	// Init is the initialization section (HLS EXT-X-MAP or DASH
+	// Initialization) needed to decode the segment, as for fragmented MP4.
+	// Only its URL and ByteRange are set. It is nil for self-contained
+	// segments such as MPEG-TS.
+	Init *Segment
+	// Key is the HLS EXT-X-KEY the segment is encrypted with, nil when it
+	// is not encrypted. FetchSegment decrypts AES-128 segments.
+	Key *SegmentKey
+
+	// initSection marks an HLS EXT-X-MAP initialization section, which has
+	// no media sequence number to derive an IV from.
+	initSection bool
+}
+
+// ByteRange is a byte range within a resource.
+type ByteRange struct {
+	Offset int64
+	Length int64
+}
+
+// header returns the range as an HTTP Range header value.
+func (r *ByteRange) header() string {
+	return fmt.Sprintf("bytes=%d-%d", r.Offset, r.Offset+r.Length-1)
+}
+
+// PlaylistInfo holds the playlist-level tags of an HLS media playlist.
+type PlaylistInfo struct {
+	TargetDuration        float64
+	MediaSequence         uint64
+	DiscontinuitySequence uint64
+	// PartTargetDuration is the LL-HLS EXT-X-PART-INF PART-TARGET, 0 when
+	// the playlist has no partial segments.
+	PartTargetDuration float64
+	// CanBlockReload is set when EXT-X-SERVER-CONTROL allows blocking
+	// playlist reloads.
+	CanBlockReload bool
+	// Segments lists every media segment of the refresh, oldest first, so
+	// that playlist checks see the whole playlist even when only the newest
+	// segments are returned for analysis.
+	Segments []*Segment
 }
 
 // Parser handles manifest parsing.
 type Parser struct {
-	httpClient      *http.Client
-	maxSegmentBytes int64
+	httpClient       *http.Client
+	maxSegmentBytes  int64
+	variantSelection VariantSelection
+	// now is the clock used for the DASH availability window; nil means
+	// time.Now.
+	now func() time.Time
+
+	// blockingReload records which media playlists advertised
+	// CAN-BLOCK-RELOAD the last time they were fetched; keys caches
+	// AES-128 segment keys by URL; manifests caches manifest bodies by URL
+	// for conditional requests.
+	mu             sync.Mutex
+	blockingReload map[string]bool
+	keys           map[string][]byte
+	manifests      map[string]*cachedManifest
 }
 
 // NewParser creates a new manifest parser.
@@ -52,38 +125,119 @@ func NewParserWithLimit(timeout time.Duration, maxSegmentBytes int64) *Parser {
 	}
 }
 
-// GetLatestSegment retrieves the latest segment from the manifest URL.
-func (p *Parser) GetLatestSegment(ctx context.Context, manifestURL string) (*Segment, error) {
-	// Determine manifest type from URL
-	if isDASHManifestURL(manifestURL) {
-		return p.getLatestDASHSegment(ctx, manifestURL)
+// SetVariantSelection sets which rendition is followed when the manifest
+// offers several (HLS master playlist variants or DASH representations).
+func (p *Parser) SetVariantSelection(selection VariantSelection) {
+	p.variantSelection = selection
+}
+
+// SetCookieJar sends cookies from jar with manifest, key and segment
+// requests, for streams that need a logged-in session.
+func (p *Parser) SetCookieJar(jar http.CookieJar) {
+	p.httpClient.Jar = jar
+}
+
+func (p *Parser) clock() time.Time {
+	if p.now != nil {
+		return p.now()
 	}
-	if strings.Contains(strings.ToLower(manifestURL), ".m3u8") {
-		return p.getLatestHLSSegment(ctx, manifestURL)
+	return time.Now()
+}
+
+// GetLatestSegment retrieves the latest segment from the manifest URL,
+// following the parser's variant selection.
+func (p *Parser) GetLatestSegment(ctx context.Context, manifestURL string) (*Segment, error) {
+	return p.GetLatestVariantSegment(ctx, manifestURL, p.variantSelection)
+}
+
+// GetLatestVariantSegment retrieves the latest segment of the rendition
+// chosen by selection. Manifests with a single rendition ignore selection.
+func (p *Parser) GetLatestVariantSegment(ctx context.Context, manifestURL string, selection VariantSelection) (*Segment, error) {
+	segments, err := p.getVariantSegments(ctx, manifestURL, selection, 1)
+	if err != nil {
+		return nil, err
 	}
+	return segments[len(segments)-1], nil
+}
 
-	// Default to HLS
-	return p.getLatestHLSSegment(ctx, manifestURL)
+// GetVariantSegmentsSince returns the segments of the selected rendition
+// whose sequence is greater than afterSequence, oldest first. At most limit
+// segments are returned; when more are available the oldest are dropped.
+// When no segment is newer than afterSequence the latest segment is
+// returned on its own so callers can still detect URL changes.
+func (p *Parser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection VariantSelection, afterSequence uint64, limit int) ([]*Segment, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	segments, err := p.getVariantSegments(ctx, manifestURL, selection, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, seg := range segments {
+		if seg.Sequence > afterSequence {
+			return segments[i:], nil
+		}
+	}
+	return segments[len(segments)-1:], nil
 }
 
-// getLatestHLSSegment retrieves the latest segment from an HLS manifest.
-func (p *Parser) getLatestHLSSegment(ctx context.Context, manifestURL string) (*Segment, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
+// getVariantSegments returns up to limit of the newest segments of the
+// selected rendition, oldest first. The result is never empty.
+func (p *Parser) getVariantSegments(ctx context.Context, manifestURL string, selection VariantSelection, limit int) ([]*Segment, error) {
+	var segments []*Segment
+	var err error
+	// Determine manifest type from URL; default to HLS.
+	if isDASHManifestURL(manifestURL) {
+		segments, err = p.getDASHSegments(ctx, manifestURL, selection, limit)
+	} else {
+		segments, err = p.getHLSSegments(ctx, manifestURL, selection)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
+	}
+	if len(segments) > limit {
+		segments = segments[len(segments)-limit:]
 	}
+	return segments, nil
+}
 
-	resp, err := p.httpClient.Do(req)
+// getHLSSegments retrieves every segment listed in an HLS media playlist,
+// resolving a master playlist to the selected variant first.
+func (p *Parser) getHLSSegments(ctx context.Context, manifestURL string, selection VariantSelection) ([]*Segment, error) {
+	media, err := p.fetchHLSMediaPlaylist(ctx, manifestURL, selection, nil)
 	if err != nil {
-		return nil, fmt.Errorf("fetch manifest: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return media.segments, nil
+}
+
+// hlsMediaPlaylist holds the segments and LL-HLS partial segments of a
+// fetched media playlist.
+type hlsMediaPlaylist struct {
+	segments []*Segment
+	parts    []*Segment
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("manifest fetch failed with status %d", resp.StatusCode)
+// fetchHLSMediaPlaylist fetches an HLS media playlist, resolving a master
+// playlist to the selected variant first. A non-nil target is sent to the
+// media playlist as a blocking reload once it has advertised support.
+func (p *Parser) fetchHLSMediaPlaylist(ctx context.Context, manifestURL string, selection VariantSelection, target *hlsReloadTarget) (*hlsMediaPlaylist, error) {
+	var body []byte
+	if target != nil && p.canBlockReload(manifestURL) {
+		// A blocking reload waits for new content, so it bypasses the cache.
+		fetched, err := p.downloadManifest(ctx, target.apply(manifestURL), nil)
+		if err != nil {
+			return nil, err
+		}
+		body = fetched.body
+	} else {
+		var err error
+		if body, err = p.fetchManifest(ctx, manifestURL); err != nil {
+			return nil, err
+		}
 	}
 
-	playlist, listType, err := m3u8.DecodeFrom(resp.Body, true)
+	playlist, listType, err := decodeHLSPlaylist(bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("decode manifest: %w", err)
 	}
@@ -96,7 +250,8 @@ func (p *Parser) getLatestHLSSegment(ctx context.Context, manifestURL string) (*
 	switch listType {
 	case m3u8.MEDIA:
 		mediapl := playlist.(*m3u8.MediaPlaylist)
-		return p.extractLatestFromMediaPlaylist(mediapl, baseURL)
+		p.setCanBlockReload(manifestURL, mediapl.ServerControl != nil && mediapl.ServerControl.CanBlockReload)
+		return p.extractSegmentsFromMediaPlaylist(mediapl, baseURL)
 	case m3u8.MASTER:
 		// For master playlist, we need to fetch the actual media playlist
 		masterpl := playlist.(*m3u8.MasterPlaylist)
@@ -104,47 +259,139 @@ func (p *Parser) getLatestHLSSegment(ctx context.Context, manifestURL string) (*
 			return nil, fmt.Errorf("no variants in master playlist")
 		}
 
-		// Pick the lowest bandwidth variant to reduce processing load
-		variant := lowestBandwidthVariant(masterpl.Variants)
+		variant := selectHLSVariant(masterpl.Variants, selection)
 		mediaURL, err := resolveURL(baseURL, variant.URI)
 		if err != nil {
 			return nil, fmt.Errorf("resolve variant URL: %w", err)
 		}
 
-		return p.getLatestHLSSegment(ctx, mediaURL)
+		media, err := p.fetchHLSMediaPlaylist(ctx, mediaURL, selection, target)
+		if err != nil {
+			return nil, err
+		}
+		height := parseResolutionHeight(variant.Resolution)
+		for _, segments := range [][]*Segment{media.segments, media.parts} {
+			for _, segment := range segments {
+				segment.Bandwidth = int64(variant.Bandwidth)
+				segment.Height = height
+			}
+		}
+		return media, nil
 	default:
 		return nil, fmt.Errorf("unknown playlist type")
 	}
 }
 
-// extractLatestFromMediaPlaylist extracts the latest segment from a media playlist.
-func (p *Parser) extractLatestFromMediaPlaylist(mediapl *m3u8.MediaPlaylist, baseURL *url.URL) (*Segment, error) {
-	// Find the last non-nil segment
-	var lastSeg *m3u8.MediaSegment
-	var lastIdx int
+// extractSegmentsFromMediaPlaylist extracts all segments, and any partial
+// segments, from a media playlist in playlist order.
+func (p *Parser) extractSegmentsFromMediaPlaylist(mediapl *m3u8.MediaPlaylist, baseURL *url.URL) (*hlsMediaPlaylist, error) {
+	info := &PlaylistInfo{
+		TargetDuration:        float64(mediapl.TargetDuration),
+		MediaSequence:         mediapl.SeqNo,
+		DiscontinuitySequence: mediapl.DiscontinuitySeq,
+		PartTargetDuration:    mediapl.PartTargetDuration,
+		CanBlockReload:        mediapl.ServerControl != nil && mediapl.ServerControl.CanBlockReload,
+	}
+	var segments []*Segment
+	// EXT-X-PROGRAM-DATE-TIME usually appears only on some segments; the
+	// segments after it follow on by their durations until a discontinuity.
+	var programDateTime time.Time
+	// EXT-X-MAP and EXT-X-KEY apply to every following segment until the
+	// next one. An initialization section is encrypted with the key in
+	// effect for the first segment that uses it.
+	var init *Segment
+	var key *SegmentKey
+	initKeyed := false
+	if mediapl.Map != nil {
+		var err error
+		if init, err = hlsInitSegment(baseURL, mediapl.Map); err != nil {
+			return nil, err
+		}
+	}
 	for i := uint(0); i < mediapl.Count(); i++ {
 		seg := mediapl.Segments[i]
-		if seg != nil {
-			lastSeg = seg
-			lastIdx = int(i)
+		if seg == nil {
+			continue
+		}
+		if len(seg.Keys) > 0 {
+			var err error
+			if key, err = hlsSegmentKey(baseURL, seg.Keys); err != nil {
+				return nil, err
+			}
+		}
+		if seg.Map != nil {
+			var err error
+			if init, err = hlsInitSegment(baseURL, seg.Map); err != nil {
+				return nil, err
+			}
+			initKeyed = false
+		}
+		if init != nil && !initKeyed {
+			init.Key = key
+			initKeyed = true
+		}
+		segmentURL, err := resolveURL(baseURL, seg.URI)
+		if err != nil {
+			return nil, fmt.Errorf("resolve segment URL: %w", err)
+		}
+		switch {
+		case !seg.ProgramDateTime.IsZero():
+			programDateTime = seg.ProgramDateTime
+		case seg.Discontinuity:
+			programDateTime = time.Time{}
+		}
+		segments = append(segments, &Segment{
+			URL:             segmentURL,
+			Duration:        seg.Duration,
+			Sequence:        mediapl.SeqNo + uint64(i),
+			MediaType:       "hls",
+			ProgramDateTime: programDateTime,
+			Discontinuity:   seg.Discontinuity,
+			Playlist:        info,
+			Init:            init,
+			Key:             key,
+		})
+		if !programDateTime.IsZero() {
+			programDateTime = programDateTime.Add(time.Duration(seg.Duration * float64(time.Second)))
 		}
 	}
 
-	if lastSeg == nil {
+	if len(segments) == 0 {
 		return nil, fmt.Errorf("no segments in playlist")
 	}
+	info.Segments = segments
+	parts, err := extractPartialSegments(mediapl, baseURL, segments, init, key)
+	if err != nil {
+		return nil, err
+	}
+	return &hlsMediaPlaylist{segments: segments, parts: parts}, nil
+}
+
+// hlsByteRangeAttrPattern matches the quoted BYTERANGE of an EXT-X-MAP or
+// EXT-X-PART tag, which the m3u8 decoder only accepts unquoted.
+var hlsByteRangeAttrPattern = regexp.MustCompile(`(?m)^(#EXT-X-(?:MAP|PART):.*BYTERANGE=)"([0-9@]+)"`)
 
-	segmentURL, err := resolveURL(baseURL, lastSeg.URI)
+// decodeHLSPlaylist decodes an HLS playlist.
+func decodeHLSPlaylist(r io.Reader) (m3u8.Playlist, m3u8.ListType, error) {
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("resolve segment URL: %w", err)
+		return nil, 0, fmt.Errorf("read playlist: %w", err)
 	}
+	data = hlsByteRangeAttrPattern.ReplaceAll(data, []byte("${1}${2}"))
+	return m3u8.Decode(*bytes.NewBuffer(data), true)
+}
 
-	return &Segment{
-		URL:       segmentURL,
-		Duration:  lastSeg.Duration,
-		Sequence:  mediapl.SeqNo + uint64(lastIdx),
-		MediaType: "hls",
-	}, nil
+// hlsInitSegment resolves an EXT-X-MAP tag.
+func hlsInitSegment(baseURL *url.URL, xMap *m3u8.Map) (*Segment, error) {
+	initURL, err := resolveURL(baseURL, xMap.URI)
+	if err != nil {
+		return nil, fmt.Errorf("resolve init segment URL: %w", err)
+	}
+	init := &Segment{URL: initURL, initSection: true}
+	if xMap.Limit > 0 {
+		init.ByteRange = &ByteRange{Offset: xMap.Offset, Length: xMap.Limit}
+	}
+	return init, nil
 }
 
 // IsEndList returns true if the playlist is marked as ended (EXT-X-ENDLIST).
@@ -162,22 +409,12 @@ func (p *Parser) isEndListWithDepth(ctx context.Context, manifestURL string, dep
 		return false, fmt.Errorf("max master->media recursion depth exceeded")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
+	body, err := p.fetchManifest(ctx, manifestURL)
 	if err != nil {
-		return false, fmt.Errorf("create request: %w", err)
+		return false, err
 	}
 
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("fetch manifest: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("manifest fetch failed with status %d", resp.StatusCode)
-	}
-
-	playlist, listType, err := m3u8.DecodeFrom(resp.Body, true)
+	playlist, listType, err := decodeHLSPlaylist(bytes.NewReader(body))
 	if err != nil {
 		return false, fmt.Errorf("decode manifest: %w", err)
 	}
@@ -195,7 +432,7 @@ func (p *Parser) isEndListWithDepth(ctx context.Context, manifestURL string, dep
 		if err != nil {
 			return false, fmt.Errorf("parse manifest URL: %w", err)
 		}
-		variant := lowestBandwidthVariant(masterpl.Variants)
+		variant := selectHLSVariant(masterpl.Variants, p.variantSelection)
 		mediaURL, err := resolveURL(baseURL, variant.URI)
 		if err != nil {
 			return false, fmt.Errorf("resolve variant URL: %w", err)
@@ -206,59 +443,33 @@ func (p *Parser) isEndListWithDepth(ctx context.Context, manifestURL string, dep
 	}
 }
 
-// getLatestDASHSegment retrieves the latest segment from a DASH manifest.
-// Note: This is a simplified implementation. Full DASH support would require
-// a dedicated MPD parser library.
-func (p *Parser) getLatestDASHSegment(ctx context.Context, manifestURL string) (*Segment, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+// FetchSegment downloads a segment, requesting only its byte range when it
+// has one, and decrypts it when it is AES-128 encrypted. Segments with any
+// other encryption, and encrypted initialization sections without the IV
+// the spec requires for them, fail with *UnsupportedEncryptionError before
+// download.
+func (p *Parser) FetchSegment(ctx context.Context, segment *Segment) ([]byte, error) {
+	if segment.Key != nil && (!segment.Key.supported() || segment.initSection && segment.Key.IV == nil) {
+		return nil, &UnsupportedEncryptionError{Method: segment.Key.Method, KeyFormat: segment.Key.KeyFormat, MissingIV: segment.Key.supported()}
 	}
-
-	resp, err := p.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetch manifest: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("manifest fetch failed with status %d", resp.StatusCode)
-	}
-
-	var mpd dashMPD
-	if err := xml.NewDecoder(resp.Body).Decode(&mpd); err != nil {
-		return nil, fmt.Errorf("decode mpd: %w", err)
+	data, err := p.fetchRange(ctx, segment.URL, segment.ByteRange)
+	if err != nil || segment.Key == nil {
+		return data, err
 	}
-
-	segmentTemplate, representation, err := selectSegmentTemplate(&mpd)
-	if err != nil {
-		return nil, err
-	}
-
-	baseURL, err := resolveDASHBaseURL(&mpd, representation, manifestURL)
-	if err != nil {
-		return nil, err
-	}
-
-	segmentURL, duration, sequence, err := buildLatestDASHSegment(baseURL, segmentTemplate, representation, &mpd)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Segment{
-		URL:       segmentURL,
-		Duration:  duration,
-		Sequence:  sequence,
-		MediaType: "dash",
-	}, nil
+	return p.decryptSegment(ctx, segment, data)
 }
 
-// FetchSegment downloads a segment from the given URL.
-func (p *Parser) FetchSegment(ctx context.Context, segmentURL string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", segmentURL, nil)
+// fetchRange downloads rawURL, or only byteRange of it when non-nil. A
+// server that ignores the Range header and answers 200 is tolerated by
+// slicing the full body.
+func (p *Parser) fetchRange(ctx context.Context, rawURL string, byteRange *ByteRange) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
+	if byteRange != nil {
+		req.Header.Set("Range", byteRange.header())
+	}
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
@@ -266,15 +477,27 @@ func (p *Parser) FetchSegment(ctx context.Context, segmentURL string) ([]byte, e
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	partial := resp.StatusCode == http.StatusPartialContent && byteRange != nil
+	if resp.StatusCode != http.StatusOK && !partial {
 		return nil, fmt.Errorf("segment fetch failed with status %d", resp.StatusCode)
 	}
 
-	reader := io.LimitReader(resp.Body, p.maxSegmentBytes+1)
+	limit := p.maxSegmentBytes
+	if byteRange != nil && !partial {
+		limit += byteRange.Offset
+	}
+	reader := io.LimitReader(resp.Body, limit+1)
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("read segment: %w", err)
 	}
+	if byteRange != nil && !partial {
+		end := byteRange.Offset + byteRange.Length
+		if int64(len(data)) < end {
+			return nil, fmt.Errorf("segment shorter than byte range %s", byteRange.header())
+		}
+		data = data[byteRange.Offset:end]
+	}
 	if int64(len(data)) > p.maxSegmentBytes {
 		return nil, fmt.Errorf("segment exceeds max size of %d bytes", p.maxSegmentBytes)
 	}
@@ -309,304 +532,3 @@ func resolveURL(base *url.URL, ref string) (string, error) {
 	resolved.Fragment = refURL.Fragment
 	return resolved.String(), nil
 }
-
-type dashMPD struct {
-	XMLName                   xml.Name   `xml:"MPD"`
-	BaseURL                   string     `xml:"BaseURL"`
-	MediaPresentationDuration string     `xml:"mediaPresentationDuration,attr"`
-	Period                    dashPeriod `xml:"Period"`
-}
-
-type dashPeriod struct {
-	AdaptationSets []dashAdaptationSet `xml:"AdaptationSet"`
-}
-
-type dashAdaptationSet struct {
-	Representations []dashRepresentation `xml:"Representation"`
-}
-
-type dashRepresentation struct {
-	ID              string               `xml:"id,attr"`
-	Bandwidth       int64                `xml:"bandwidth,attr"`
-	BaseURL         string               `xml:"BaseURL"`
-	SegmentTemplate *dashSegmentTemplate `xml:"SegmentTemplate"`
-}
-
-type dashSegmentTemplate struct {
-	Timescale       int64                `xml:"timescale,attr"`
-	Duration        int64                `xml:"duration,attr"`
-	StartNumber     int64                `xml:"startNumber,attr"`
-	Media           string               `xml:"media,attr"`
-	SegmentTimeline *dashSegmentTimeline `xml:"SegmentTimeline"`
-}
-
-type dashSegmentTimeline struct {
-	Segments []dashSegmentTimelineS `xml:"S"`
-}
-
-type dashSegmentTimelineS struct {
-	T int64 `xml:"t,attr"`
-	D int64 `xml:"d,attr"`
-	R int64 `xml:"r,attr"`
-}
-
-func selectSegmentTemplate(mpd *dashMPD) (*dashSegmentTemplate, *dashRepresentation, error) {
-	if mpd == nil {
-		return nil, nil, fmt.Errorf("mpd is nil")
-	}
-	var chosen *dashRepresentation
-	for i := range mpd.Period.AdaptationSets {
-		set := mpd.Period.AdaptationSets[i]
-		for j := range set.Representations {
-			rep := &set.Representations[j]
-			if rep.SegmentTemplate == nil {
-				continue
-			}
-			if chosen == nil || rep.Bandwidth < chosen.Bandwidth {
-				chosen = rep
-			}
-		}
-	}
-	if chosen == nil {
-		return nil, nil, fmt.Errorf("no representation with segment template")
-	}
-	if chosen.SegmentTemplate.Media == "" {
-		return nil, nil, fmt.Errorf("segment template media is empty")
-	}
-	return chosen.SegmentTemplate, chosen, nil
-}
-
-func resolveDASHBaseURL(mpd *dashMPD, representation *dashRepresentation, manifestURL string) (string, error) {
-	base, err := url.Parse(manifestURL)
-	if err != nil {
-		return "", fmt.Errorf("parse manifest url: %w", err)
-	}
-	if representation != nil && representation.BaseURL != "" {
-		return resolveRelativeBaseURL(base, representation.BaseURL)
-	}
-	if mpd != nil && mpd.BaseURL != "" {
-		return resolveRelativeBaseURL(base, mpd.BaseURL)
-	}
-	base.Path = path.Dir(base.Path)
-	if base.Path == "/" {
-		// Root path already has trailing slash.
-	} else if !strings.HasSuffix(base.Path, "/") {
-		base.Path += "/"
-	}
-	base.RawQuery = ""
-	base.Fragment = ""
-	return base.String(), nil
-}
-
-func resolveRelativeBaseURL(base *url.URL, baseURL string) (string, error) {
-	ref, err := url.Parse(baseURL)
-	if err != nil {
-		return "", fmt.Errorf("parse base url: %w", err)
-	}
-	return base.ResolveReference(ref).String(), nil
-}
-
-func buildLatestDASHSegment(baseURL string, template *dashSegmentTemplate, representation *dashRepresentation, mpd *dashMPD) (string, float64, uint64, error) {
-	if template == nil {
-		return "", 0, 0, fmt.Errorf("segment template is nil")
-	}
-	timescale := template.Timescale
-	if timescale <= 0 {
-		timescale = 1
-	}
-	startNumber := template.StartNumber
-	if startNumber == 0 {
-		startNumber = 1
-	}
-
-	number := startNumber
-	var timeValue int64
-	var duration int64
-	var segmentsCount int64
-
-	if template.SegmentTimeline != nil && len(template.SegmentTimeline.Segments) > 0 {
-		var currentTime int64
-		for i, seg := range template.SegmentTimeline.Segments {
-			if seg.D == 0 {
-				return "", 0, 0, fmt.Errorf("segment timeline duration missing")
-			}
-			if seg.T != 0 || i == 0 {
-				currentTime = seg.T
-			}
-			repeat := seg.R
-			// SegmentTimeline semantics: r=-1 repeats until the next S element, period end, or MPD update.
-			// Clamp negative seg.R to 0 to avoid infinite repeats in offline/static parsing
-			// (prevents infinite loops/counting); live MPDs should advance via periodic updates.
-			if repeat < 0 {
-				repeat = 0
-			}
-			duration = seg.D
-			timeValue = currentTime + duration*repeat
-			segmentsCount += 1 + repeat
-			currentTime += duration * (repeat + 1)
-		}
-	} else {
-		if template.Duration == 0 {
-			return "", 0, 0, fmt.Errorf("segment duration missing")
-		}
-		duration = template.Duration
-		segments, err := estimateSegmentCount(template, timescale, mpd)
-		if err != nil {
-			return "", 0, 0, err
-		}
-		segmentsCount = segments
-		timeValue = (segmentsCount - 1) * duration
-	}
-
-	if segmentsCount <= 0 {
-		segmentsCount = 1
-	}
-	number = startNumber + segmentsCount - 1
-	if number < 1 {
-		number = 1
-	}
-
-	urlStr, err := fillSegmentTemplate(baseURL, template.Media, representation, uint64(number), timeValue)
-	if err != nil {
-		return "", 0, 0, err
-	}
-
-	return urlStr, float64(duration) / float64(timescale), uint64(number), nil
-}
-
-func estimateSegmentCount(template *dashSegmentTemplate, timescale int64, mpd *dashMPD) (int64, error) {
-	if template == nil {
-		return 0, fmt.Errorf("segment template is nil")
-	}
-	if template.Duration <= 0 {
-		return 0, fmt.Errorf("segment duration missing")
-	}
-	if timescale <= 0 {
-		timescale = 1
-	}
-	totalSeconds, err := parseMPDDuration(mpd)
-	if err != nil {
-		return 0, err
-	}
-	segmentDuration := float64(template.Duration) / float64(timescale)
-	if segmentDuration <= 0 {
-		return 0, fmt.Errorf("segment duration missing")
-	}
-	segments := int64(math.Ceil(totalSeconds / segmentDuration))
-	if segments < 1 {
-		segments = 1
-	}
-	return segments, nil
-}
-
-var dashTemplatePattern = regexp.MustCompile(`\$(Number|Time|RepresentationID|Bandwidth)\$`)
-
-func fillSegmentTemplate(baseURL string, media string, representation *dashRepresentation, number uint64, timeValue int64) (string, error) {
-	replaced := dashTemplatePattern.ReplaceAllStringFunc(media, func(match string) string {
-		switch match {
-		case "$Number$":
-			return strconv.FormatUint(number, 10)
-		case "$Time$":
-			return strconv.FormatInt(timeValue, 10)
-		case "$RepresentationID$":
-			if representation != nil {
-				return representation.ID
-			}
-			return ""
-		case "$Bandwidth$":
-			if representation != nil {
-				return strconv.FormatInt(representation.Bandwidth, 10)
-			}
-			return ""
-		default:
-			return match
-		}
-	})
-	base, err := url.Parse(baseURL)
-	if err != nil {
-		return "", fmt.Errorf("parse base url: %w", err)
-	}
-	return resolveURL(base, replaced)
-}
-
-// Supported subset: days + integer hours/minutes + fractional seconds.
-// Weeks (PnW) and fractional hours/minutes are not supported.
-var mpdDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
-var mpdDurationDaysOnlyPattern = regexp.MustCompile(`^P(\d+)D$`)
-
-func parseMPDDuration(mpd *dashMPD) (float64, error) {
-	if mpd == nil {
-		return 0, fmt.Errorf("mpd is nil")
-	}
-	if mpd.MediaPresentationDuration == "" {
-		return 0, fmt.Errorf("mediaPresentationDuration missing")
-	}
-	daysOnly := mpdDurationDaysOnlyPattern.FindStringSubmatch(mpd.MediaPresentationDuration)
-	if daysOnly != nil {
-		days, err := strconv.ParseFloat(daysOnly[1], 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse days: %w", err)
-		}
-		total := days * 24 * 3600
-		if total <= 0 {
-			return 0, fmt.Errorf("parsed duration is zero or negative")
-		}
-		return total, nil
-	}
-	match := mpdDurationPattern.FindStringSubmatch(mpd.MediaPresentationDuration)
-	if match == nil {
-		return 0, fmt.Errorf("invalid mediaPresentationDuration")
-	}
-	var total float64
-	if match[1] != "" {
-		days, err := strconv.ParseFloat(match[1], 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse days: %w", err)
-		}
-		total += days * 24 * 3600
-	}
-	if match[2] != "" {
-		hours, err := strconv.ParseFloat(match[2], 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse hours: %w", err)
-		}
-		total += hours * 3600
-	}
-	if match[3] != "" {
-		minutes, err := strconv.ParseFloat(match[3], 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse minutes: %w", err)
-		}
-		total += minutes * 60
-	}
-	if match[4] != "" {
-		seconds, err := strconv.ParseFloat(match[4], 64)
-		if err != nil {
-			return 0, fmt.Errorf("parse seconds: %w", err)
-		}
-		total += seconds
-	}
-	if total <= 0 {
-		return 0, fmt.Errorf("parsed duration is zero or negative")
-	}
-	return total, nil
-}
-
-// lowestBandwidthVariant returns the variant with the lowest bandwidth.
-func lowestBandwidthVariant(variants []*m3u8.Variant) *m3u8.Variant {
-	lowest := variants[0]
-	for _, v := range variants[1:] {
-		if v.Bandwidth < lowest.Bandwidth {
-			lowest = v
-		}
-	}
-	return lowest
-}
-
-func isDASHManifestURL(manifestURL string) bool {
-	parsed, err := url.Parse(manifestURL)
-	if err != nil {
-		return strings.HasSuffix(strings.ToLower(manifestURL), ".mpd")
-	}
-	return strings.HasSuffix(strings.ToLower(parsed.Path), ".mpd")
-}
@@ -1,10 +1,16 @@
 package manifest
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGetLatestSegmentDASHSegmentTimeline(t *testing.T) {
@@ -78,3 +84,410 @@ func TestGetLatestSegmentDASHDuration(t *testing.T) {
 		t.Fatalf("segment duration = %v, want 5", segment.Duration)
 	}
 }
+
+func TestGetLatestSegmentDASHVariantSelection(t *testing.T) {
+	mpd := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD mediaPresentationDuration="PT20S">
+  <Period>
+    <AdaptationSet>
+      <Representation id="360p" bandwidth="500000" height="360">
+        <SegmentTemplate timescale="1" duration="5" media="$RepresentationID$/seg_$Number$.m4s" startNumber="1"/>
+      </Representation>
+      <Representation id="1080p" bandwidth="4000000" height="1080">
+        <SegmentTemplate timescale="1" duration="5" media="$RepresentationID$/seg_$Number$.m4s" startNumber="1"/>
+      </Representation>
+      <Representation id="720p" bandwidth="2000000" height="720">
+        <SegmentTemplate timescale="1" duration="5" media="$RepresentationID$/seg_$Number$.m4s" startNumber="1"/>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(mpd))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		selection VariantSelection
+		wantURL   string
+	}{
+		{VariantSelection{}, "/360p/seg_4.m4s"},
+		{VariantSelection{Mode: VariantHighest}, "/1080p/seg_4.m4s"},
+		{VariantSelection{Mode: VariantClosestToHeight, Height: 800}, "/720p/seg_4.m4s"},
+		{VariantSelection{Mode: VariantBandwidth, Bandwidth: 600000}, "/360p/seg_4.m4s"},
+	}
+	for _, tt := range tests {
+		parser := newTestParser()
+		parser.SetVariantSelection(tt.selection)
+		segment, err := parser.GetLatestSegment(context.Background(), server.URL+"/manifest.mpd")
+		if err != nil {
+			t.Fatalf("%s: GetLatestSegment error: %v", tt.selection, err)
+		}
+		if segment.URL != server.URL+tt.wantURL {
+			t.Fatalf("%s: segment URL = %s, want %s", tt.selection, segment.URL, server.URL+tt.wantURL)
+		}
+	}
+}
+
+func TestGetVariantSegmentsSinceDASH(t *testing.T) {
+	timeline := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD mediaPresentationDuration="PT60S">
+  <Period>
+    <AdaptationSet>
+      <Representation id="video" bandwidth="500000">
+        <SegmentTemplate timescale="1" media="seg_$Time$.m4s" startNumber="10">
+          <SegmentTimeline>
+            <S t="0" d="10" r="3"/>
+            <S d="5" r="1"/>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+	duration := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD mediaPresentationDuration="PT20S">
+  <Period>
+    <AdaptationSet>
+      <Representation id="video" bandwidth="500000">
+        <SegmentTemplate timescale="1" duration="4" media="seg_$Number$.m4s" startNumber="1" />
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/timeline.mpd" {
+			_, _ = w.Write([]byte(timeline))
+			return
+		}
+		_, _ = w.Write([]byte(duration))
+	}))
+	defer server.Close()
+
+	parser := newTestParser()
+	tests := []struct {
+		manifest string
+		after    uint64
+		limit    int
+		want     []string
+	}{
+		// Timeline numbers 10..15 at t=0,10,20,30,40,45.
+		{manifest: "/timeline.mpd", after: 12, limit: 10, want: []string{"seg_30.m4s", "seg_40.m4s", "seg_45.m4s"}},
+		{manifest: "/timeline.mpd", after: 0, limit: 2, want: []string{"seg_40.m4s", "seg_45.m4s"}},
+		{manifest: "/timeline.mpd", after: 15, limit: 10, want: []string{"seg_45.m4s"}},
+		// Duration-based numbers 1..5.
+		{manifest: "/duration.mpd", after: 2, limit: 10, want: []string{"seg_3.m4s", "seg_4.m4s", "seg_5.m4s"}},
+		{manifest: "/duration.mpd", after: 0, limit: 1, want: []string{"seg_5.m4s"}},
+	}
+	for _, tt := range tests {
+		segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+tt.manifest, VariantSelection{}, tt.after, tt.limit)
+		if err != nil {
+			t.Fatalf("%s after=%d: GetVariantSegmentsSince error: %v", tt.manifest, tt.after, err)
+		}
+		var got []string
+		for _, seg := range segments {
+			got = append(got, seg.URL)
+			if seg.MediaType != "dash" {
+				t.Fatalf("%s after=%d: MediaType = %s, want dash", tt.manifest, tt.after, seg.MediaType)
+			}
+		}
+		var want []string
+		for _, name := range tt.want {
+			want = append(want, server.URL+"/"+name)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%s after=%d limit=%d: segments = %v, want %v", tt.manifest, tt.after, tt.limit, got, want)
+		}
+	}
+}
+
+func TestGetVariantSegmentsSinceDASHProgramDateTime(t *testing.T) {
+	mpd := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD type="dynamic" availabilityStartTime="2024-01-15T11:00:00Z" mediaPresentationDuration="PT60S">
+  <Period start="PT10S">
+    <AdaptationSet>
+      <Representation id="video" bandwidth="500000">
+        <SegmentTemplate timescale="1000" presentationTimeOffset="5000" media="seg_$Time$.m4s">
+          <SegmentTimeline>
+            <S t="5000" d="4000" r="1"/>
+          </SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(mpd))
+	}))
+	defer server.Close()
+
+	segments, err := newTestParser().GetVariantSegmentsSince(context.Background(), server.URL+"/live.mpd", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(segments))
+	}
+	// availabilityStartTime + Period start + (t - presentationTimeOffset).
+	start := time.Date(2024, 1, 15, 11, 0, 10, 0, time.UTC)
+	if !segments[0].ProgramDateTime.Equal(start) {
+		t.Fatalf("first ProgramDateTime = %v, want %v", segments[0].ProgramDateTime, start)
+	}
+	if want := start.Add(4 * time.Second); !segments[1].ProgramDateTime.Equal(want) {
+		t.Fatalf("second ProgramDateTime = %v, want %v", segments[1].ProgramDateTime, want)
+	}
+}
+
+// serveMPDFixture serves testdata/name at /manifest.mpd and any other path
+// from files, honouring Range requests.
+func serveMPDFixture(t *testing.T, name string, files map[string][]byte) *httptest.Server {
+	t.Helper()
+	mpd, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/manifest.mpd" {
+			_, _ = w.Write(mpd)
+			return
+		}
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestParserAt(now time.Time) *Parser {
+	parser := newTestParser()
+	parser.now = func() time.Time { return now }
+	return parser
+}
+
+func segmentURLs(segments []*Segment) []string {
+	urls := make([]string, len(segments))
+	for i, segment := range segments {
+		urls[i] = segment.URL
+	}
+	return urls
+}
+
+func TestGetVariantSegmentsSinceDASHTimelineLiveWindow(t *testing.T) {
+	server := serveMPDFixture(t, "timeline_live.mpd", nil)
+	ast := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+	parser := newTestParserAt(ast.Add(31 * time.Second))
+
+	segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	// The open-ended S repeats to the live edge at 31s; the 10s time-shift
+	// buffer keeps segments ending between 21s and 31s.
+	var want []string
+	for start := 20000; start <= 28000; start += 2000 {
+		want = append(want, fmt.Sprintf("%s/v_%d.m4s", server.URL, start))
+	}
+	if got := segmentURLs(segments); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("segment URLs = %v, want %v", got, want)
+	}
+	// Numbering counts the segments before the @t gap.
+	if segments[0].Sequence != 109 || segments[len(segments)-1].Sequence != 113 {
+		t.Fatalf("sequences = %d..%d, want 109..113", segments[0].Sequence, segments[len(segments)-1].Sequence)
+	}
+	if want := ast.Add(28 * time.Second); !segments[len(segments)-1].ProgramDateTime.Equal(want) {
+		t.Fatalf("ProgramDateTime = %v, want %v", segments[len(segments)-1].ProgramDateTime, want)
+	}
+
+	// A later poll only returns what became available since.
+	parser.now = func() time.Time { return ast.Add(33 * time.Second) }
+	segments, err = parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{}, 113, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	if len(segments) != 1 || segments[0].URL != server.URL+"/v_30000.m4s" {
+		t.Fatalf("segment URLs = %v, want [%s/v_30000.m4s]", segmentURLs(segments), server.URL)
+	}
+}
+
+func TestGetVariantSegmentsSinceDASHTimelineGaps(t *testing.T) {
+	server := serveMPDFixture(t, "timeline_gap.mpd", nil)
+
+	segments, err := newTestParser().GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	var want []string
+	for _, start := range []int{0, 4, 12, 16, 20, 24} {
+		want = append(want, fmt.Sprintf("%s/seg_%d.m4s", server.URL, start))
+	}
+	if got := segmentURLs(segments); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("segment URLs = %v, want %v", got, want)
+	}
+}
+
+func TestGetVariantSegmentsSinceDASHSegmentList(t *testing.T) {
+	media := bytes.Repeat([]byte{'x'}, 4100)
+	copy(media[2000:], "second")
+	server := serveMPDFixture(t, "segment_list.mpd", map[string][]byte{"/media/high.mp4": media})
+	parser := newTestParser()
+	parser.maxSegmentBytes = 4096
+
+	segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{Mode: VariantLowest}, 0, 2)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	want := []string{server.URL + "/media/low_2.ts", server.URL + "/media/low_3.ts"}
+	if got := segmentURLs(segments); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("segment URLs = %v, want %v", got, want)
+	}
+	if segments[1].Sequence != 3 || segments[1].Duration != 4 || segments[1].ByteRange != nil {
+		t.Fatalf("last segment = %+v, want sequence 3, duration 4, no byte range", segments[1])
+	}
+
+	segments, err = parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{Mode: VariantHighest}, 0, 3)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	second := segments[1]
+	if second.URL != server.URL+"/media/high.mp4" {
+		t.Fatalf("segment URL = %s, want %s/media/high.mp4", second.URL, server.URL)
+	}
+	if second.ByteRange == nil || *second.ByteRange != (ByteRange{Offset: 2000, Length: 1500}) {
+		t.Fatalf("byte range = %+v, want 2000+1500", second.ByteRange)
+	}
+	data, err := parser.FetchSegment(context.Background(), second)
+	if err != nil {
+		t.Fatalf("FetchSegment error: %v", err)
+	}
+	if len(data) != 1500 || string(data[:6]) != "second" {
+		t.Fatalf("FetchSegment returned %d bytes starting %q", len(data), data[:6])
+	}
+}
+
+// buildSidx returns a version 0 sidx box with one subsegment per size, each
+// lasting one second at a 90kHz timescale.
+func buildSidx(sizes ...uint32) []byte {
+	body := make([]byte, 0, 24+12*len(sizes))
+	body = binary.BigEndian.AppendUint32(body, 0) // version and flags
+	body = binary.BigEndian.AppendUint32(body, 1) // reference_ID
+	body = binary.BigEndian.AppendUint32(body, 90000)
+	body = binary.BigEndian.AppendUint32(body, 0) // earliest_presentation_time
+	body = binary.BigEndian.AppendUint32(body, 0) // first_offset
+	body = binary.BigEndian.AppendUint16(body, 0)
+	body = binary.BigEndian.AppendUint16(body, uint16(len(sizes)))
+	for _, size := range sizes {
+		body = binary.BigEndian.AppendUint32(body, size)
+		body = binary.BigEndian.AppendUint32(body, 90000)
+		body = binary.BigEndian.AppendUint32(body, 0x90000000) // starts with SAP type 1
+	}
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	box = append(box, "sidx"...)
+	return append(box, body...)
+}
+
+func TestGetVariantSegmentsSinceDASHSegmentBase(t *testing.T) {
+	// Initialization (0-799), sidx (800-855), then two subsegments.
+	media := append(bytes.Repeat([]byte{'i'}, 800), buildSidx(1000, 1200)...)
+	media = append(media, bytes.Repeat([]byte{'a'}, 1000)...)
+	media = append(media, bytes.Repeat([]byte{'b'}, 1200)...)
+	server := serveMPDFixture(t, "segment_base.mpd", map[string][]byte{"/video.mp4": media})
+	parser := newTestParser()
+	parser.maxSegmentBytes = 4096
+
+	segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{}, 0, 10)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(segments))
+	}
+	if init := segments[0].Init; init == nil || init.URL != server.URL+"/video.mp4" || *init.ByteRange != (ByteRange{Offset: 0, Length: 800}) {
+		t.Fatalf("Init = %+v, want video.mp4 bytes 0-799", init)
+	}
+	if *segments[0].ByteRange != (ByteRange{Offset: 856, Length: 1000}) || *segments[1].ByteRange != (ByteRange{Offset: 1856, Length: 1200}) {
+		t.Fatalf("byte ranges = %+v, %+v", segments[0].ByteRange, segments[1].ByteRange)
+	}
+	if segments[1].Duration != 1 || segments[1].Sequence != 2 {
+		t.Fatalf("last segment = %+v, want duration 1, sequence 2", segments[1])
+	}
+	data, err := parser.FetchSegment(context.Background(), segments[1])
+	if err != nil {
+		t.Fatalf("FetchSegment error: %v", err)
+	}
+	if !bytes.Equal(data, bytes.Repeat([]byte{'b'}, 1200)) {
+		t.Fatalf("FetchSegment returned the wrong %d bytes", len(data))
+	}
+}
+
+func TestParseSidxRejectsZeroDuration(t *testing.T) {
+	sidx := buildSidx(1000, 1200)
+	// Zero the second reference's subsegment_duration (header 8, fixed
+	// fields 24, one 12-byte reference, then the 4-byte size).
+	binary.BigEndian.PutUint32(sidx[8+24+12+4:], 0)
+
+	if _, err := parseSidx(sidx, 0); err == nil {
+		t.Fatal("expected an error for a zero subsegment duration")
+	}
+}
+
+func TestGetVariantSegmentsSinceDASHMultiPeriod(t *testing.T) {
+	server := serveMPDFixture(t, "multi_period.mpd", nil)
+	ast := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+	parser := newTestParserAt(ast.Add(90 * time.Second))
+
+	// At 90s the second period is live, 30s in; the third has not started.
+	segments, err := parser.GetVariantSegmentsSince(context.Background(), server.URL+"/manifest.mpd", VariantSelection{}, 0, 3)
+	if err != nil {
+		t.Fatalf("GetVariantSegmentsSince error: %v", err)
+	}
+	want := []string{
+		server.URL + "/p2/video_00005.m4s",
+		server.URL + "/p2/video_00006.m4s",
+		server.URL + "/p2/video_00007.m4s",
+	}
+	if got := segmentURLs(segments); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("segment URLs = %v, want %v", got, want)
+	}
+	if want := ast.Add(84 * time.Second); !segments[2].ProgramDateTime.Equal(want) {
+		t.Fatalf("ProgramDateTime = %v, want %v", segments[2].ProgramDateTime, want)
+	}
+	if init := segments[2].Init; init == nil || init.URL != server.URL+"/p2/video_init.mp4" {
+		t.Fatalf("Init = %+v, want %s/p2/video_init.mp4", init, server.URL)
+	}
+
+	parser.now = func() time.Time { return ast.Add(30 * time.Second) }
+	segment, err := parser.GetLatestSegment(context.Background(), server.URL+"/manifest.mpd")
+	if err != nil {
+		t.Fatalf("GetLatestSegment error: %v", err)
+	}
+	if segment.URL != server.URL+"/p1/video_7.m4s" {
+		t.Fatalf("segment URL = %s, want %s/p1/video_7.m4s", segment.URL, server.URL)
+	}
+}
+
+func TestFetchSegmentByteRangeIgnoredByServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	data, err := newTestParser().FetchSegment(context.Background(), &Segment{
+		URL:       server.URL + "/video.mp4",
+		ByteRange: &ByteRange{Offset: 2, Length: 3},
+	})
+	if err != nil {
+		t.Fatalf("FetchSegment error: %v", err)
+	}
+	if string(data) != "234" {
+		t.Fatalf("FetchSegment = %q, want %q", data, "234")
+	}
+}
@@ -0,0 +1,166 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Eyevinn/hls-m3u8/m3u8"
+)
+
+// VariantSelectionMode identifies how a rendition is chosen from an HLS
+// master playlist or a DASH MPD.
+type VariantSelectionMode string
+
+const (
+	VariantLowest          VariantSelectionMode = "lowest"
+	VariantHighest         VariantSelectionMode = "highest"
+	VariantClosestToHeight VariantSelectionMode = "closest_to_height"
+	VariantBandwidth       VariantSelectionMode = "bandwidth"
+)
+
+// VariantSelection describes which rendition to monitor. The zero value
+// selects the lowest-bandwidth rendition.
+type VariantSelection struct {
+	Mode      VariantSelectionMode
+	Height    int
+	Bandwidth int64
+}
+
+// ParseVariantSelection parses a variant_selection setting. Accepted forms
+// are "lowest", "highest", "closest_to_height:N", "bandwidth:N" and a bare
+// bandwidth "N" (bits per second). An empty string selects "lowest".
+func ParseVariantSelection(s string) (VariantSelection, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return VariantSelection{Mode: VariantLowest}, nil
+	}
+	mode, arg, hasArg := strings.Cut(s, ":")
+	switch VariantSelectionMode(mode) {
+	case VariantLowest, VariantHighest:
+		if hasArg {
+			return VariantSelection{}, fmt.Errorf("variant selection %q takes no argument", mode)
+		}
+		return VariantSelection{Mode: VariantSelectionMode(mode)}, nil
+	case VariantClosestToHeight:
+		height, err := strconv.Atoi(arg)
+		if !hasArg || err != nil || height <= 0 {
+			return VariantSelection{}, fmt.Errorf("closest_to_height requires a positive height, got %q", arg)
+		}
+		return VariantSelection{Mode: VariantClosestToHeight, Height: height}, nil
+	case VariantBandwidth:
+		bandwidth, err := strconv.ParseInt(arg, 10, 64)
+		if !hasArg || err != nil || bandwidth <= 0 {
+			return VariantSelection{}, fmt.Errorf("bandwidth requires a positive value, got %q", arg)
+		}
+		return VariantSelection{Mode: VariantBandwidth, Bandwidth: bandwidth}, nil
+	}
+	if bandwidth, err := strconv.ParseInt(s, 10, 64); err == nil && bandwidth > 0 {
+		return VariantSelection{Mode: VariantBandwidth, Bandwidth: bandwidth}, nil
+	}
+	return VariantSelection{}, fmt.Errorf("unknown variant selection %q", s)
+}
+
+// String returns the setting form accepted by ParseVariantSelection.
+func (s VariantSelection) String() string {
+	switch s.Mode {
+	case VariantHighest:
+		return string(VariantHighest)
+	case VariantClosestToHeight:
+		return fmt.Sprintf("%s:%d", VariantClosestToHeight, s.Height)
+	case VariantBandwidth:
+		return fmt.Sprintf("%s:%d", VariantBandwidth, s.Bandwidth)
+	default:
+		return string(VariantLowest)
+	}
+}
+
+// variantCandidate is the subset of an HLS variant or DASH representation
+// that selection looks at. Height is 0 when the manifest does not say.
+type variantCandidate struct {
+	Bandwidth int64
+	Height    int
+}
+
+// pick returns the index of the chosen candidate. candidates must be
+// non-empty. Exact ties keep the earlier entry so selection is stable
+// across manifest refreshes.
+func (s VariantSelection) pick(candidates []variantCandidate) int {
+	best := 0
+	for i := 1; i < len(candidates); i++ {
+		if s.better(candidates[i], candidates[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// better reports whether a should be preferred over b.
+func (s VariantSelection) better(a, b variantCandidate) bool {
+	switch s.Mode {
+	case VariantHighest:
+		return a.Bandwidth > b.Bandwidth
+	case VariantClosestToHeight:
+		// Candidates without a known height only win over each other.
+		if (a.Height > 0) != (b.Height > 0) {
+			return a.Height > 0
+		}
+		da, db := absInt(a.Height-s.Height), absInt(b.Height-s.Height)
+		if da != db {
+			return da < db
+		}
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		return a.Bandwidth < b.Bandwidth
+	case VariantBandwidth:
+		da, db := absInt64(a.Bandwidth-s.Bandwidth), absInt64(b.Bandwidth-s.Bandwidth)
+		if da != db {
+			return da < db
+		}
+		return a.Bandwidth < b.Bandwidth
+	default:
+		return a.Bandwidth < b.Bandwidth
+	}
+}
+
+// selectHLSVariant returns the master playlist variant matching the selection.
+func selectHLSVariant(variants []*m3u8.Variant, selection VariantSelection) *m3u8.Variant {
+	candidates := make([]variantCandidate, len(variants))
+	for i, v := range variants {
+		candidates[i] = variantCandidate{
+			Bandwidth: int64(v.Bandwidth),
+			Height:    parseResolutionHeight(v.Resolution),
+		}
+	}
+	return variants[selection.pick(candidates)]
+}
+
+// parseResolutionHeight extracts the height from an HLS RESOLUTION
+// attribute ("1280x720"). It returns 0 when the attribute is absent or
+// malformed.
+func parseResolutionHeight(resolution string) int {
+	_, h, ok := strings.Cut(strings.ToLower(resolution), "x")
+	if !ok {
+		return 0
+	}
+	height, err := strconv.Atoi(h)
+	if err != nil || height < 0 {
+		return 0
+	}
+	return height
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
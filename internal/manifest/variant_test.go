@@ -0,0 +1,43 @@
+package manifest
+
+import "testing"
+
+func TestParseVariantSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    VariantSelection
+		wantErr bool
+	}{
+		{input: "", want: VariantSelection{Mode: VariantLowest}},
+		{input: "lowest", want: VariantSelection{Mode: VariantLowest}},
+		{input: "highest", want: VariantSelection{Mode: VariantHighest}},
+		{input: "closest_to_height:720", want: VariantSelection{Mode: VariantClosestToHeight, Height: 720}},
+		{input: "bandwidth:2500000", want: VariantSelection{Mode: VariantBandwidth, Bandwidth: 2500000}},
+		{input: "2500000", want: VariantSelection{Mode: VariantBandwidth, Bandwidth: 2500000}},
+		{input: "highest:1", wantErr: true},
+		{input: "closest_to_height", wantErr: true},
+		{input: "closest_to_height:0", wantErr: true},
+		{input: "bandwidth:abc", wantErr: true},
+		{input: "-5", wantErr: true},
+		{input: "best", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVariantSelection(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseVariantSelection(%q) expected error, got %+v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseVariantSelection(%q) error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVariantSelection(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+		if tt.input != "" && tt.input != "2500000" && got.String() != tt.input {
+			t.Errorf("String() = %q, want %q", got.String(), tt.input)
+		}
+	}
+}
@@ -5,11 +5,14 @@
 package model
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/types"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 // MonitorStatus represents the status of a monitor.
@@ -50,14 +53,83 @@ const (
 	StreamStatusEnded     StreamStatus = "ended"
 )
 
+// MaxMonitorVariants bounds how many renditions a single monitor may
+// analyze, since every variant costs a segment download and an ffmpeg
+// pass per check interval.
+const MaxMonitorVariants = 4
+
+// Slate reference images travel to the worker inside the CONFIG_JSON
+// environment variable, which Linux caps at 128 KiB, so both their number
+// and size are bounded. Frames are compared at 64x36, so small images
+// lose nothing.
+const (
+	MaxSlateReferences     = 4
+	MaxSlateReferenceBytes = 16 * 1024
+)
+
+// SlateReference is a still image registered on a monitor, such as colour
+// bars or a "we'll be right back" slate, that alert.slate matches against.
+type SlateReference struct {
+	Name string `json:"name"`
+	// Image is a base64-encoded PNG or JPEG.
+	Image string `json:"image"`
+}
+
+// ImageData returns the encoded PNG or JPEG bytes of the reference image.
+func (r SlateReference) ImageData() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(r.Image)
+	if err != nil {
+		return nil, fmt.Errorf("slate reference %q: image is not valid base64: %w", r.Name, err)
+	}
+	if len(data) > MaxSlateReferenceBytes {
+		return nil, fmt.Errorf("slate reference %q: image exceeds %d bytes", r.Name, MaxSlateReferenceBytes)
+	}
+	return data, nil
+}
+
+// MaxToneFrequencyHz bounds tone_frequency_hz safely below the Nyquist
+// frequency of the 16 kHz audio tone detection decodes.
+const MaxToneFrequencyHz = 7000
+
 // MonitorConfig holds the monitoring configuration.
 type MonitorConfig struct {
-	CheckIntervalSec       int        `json:"check_interval_sec"`
-	BlackoutThresholdSec   int        `json:"blackout_threshold_sec"`
-	SilenceThresholdSec    int        `json:"silence_threshold_sec"`
-	SilenceDBThreshold     float64    `json:"silence_db_threshold"`
-	ScheduledStartTime     *time.Time `json:"scheduled_start_time,omitempty"`
-	StartDelayToleranceSec int        `json:"start_delay_tolerance_sec"`
+	CheckIntervalSec        int              `json:"check_interval_sec"`
+	BlackoutThresholdSec    int              `json:"blackout_threshold_sec"`
+	SilenceThresholdSec     int              `json:"silence_threshold_sec"`
+	SilenceDBThreshold      float64          `json:"silence_db_threshold"`
+	SilenceMinDurationSec   float64          `json:"silence_min_duration_sec"`
+	SilenceRatioThreshold   float64          `json:"silence_ratio_threshold"`
+	BlackMinDurationSec     float64          `json:"black_min_duration_sec"`
+	BlackPixelThreshold     float64          `json:"black_pixel_threshold"`
+	BlackRatioThreshold     float64          `json:"black_ratio_threshold"`
+	FreezeThresholdSec      int              `json:"freeze_threshold_sec"`
+	FreezeNoiseDB           float64          `json:"freeze_noise_db"`
+	FreezeMinDurationSec    float64          `json:"freeze_min_duration_sec"`
+	FreezeRatioThreshold    float64          `json:"freeze_ratio_threshold"`
+	LoudnessMinLUFS         float64          `json:"loudness_min_lufs"`
+	LoudnessMaxLUFS         float64          `json:"loudness_max_lufs"`
+	LoudnessThresholdSec    int              `json:"loudness_threshold_sec"`
+	MinVideoHeight          int              `json:"min_video_height"`
+	MinBitrateKbps          int              `json:"min_bitrate_kbps"`
+	QualityDegradedSegments int              `json:"quality_degraded_segments"`
+	CatchupMaxSegments      int              `json:"catchup_max_segments"`
+	AVDesyncMaxOffsetMs     int              `json:"av_desync_max_offset_ms"`
+	AVDesyncThresholdSec    int              `json:"av_desync_threshold_sec"`
+	SlateReferences         []SlateReference `json:"slate_references,omitempty"`
+	SlateMatchThreshold     float64          `json:"slate_match_threshold"`
+	SlateMaxMotion          float64          `json:"slate_max_motion"`
+	SlateThresholdSec       int              `json:"slate_threshold_sec"`
+	ToneFrequencyHz         int              `json:"tone_frequency_hz"`
+	CaptionsRequired        bool             `json:"captions_required"`
+	CaptionsThresholdSec    int              `json:"captions_threshold_sec"`
+	LatencyMaxSec           int              `json:"latency_max_sec"`
+	LatencyThresholdSec     int              `json:"latency_threshold_sec"`
+	LowLatency              bool             `json:"low_latency"`
+	CookiesSecret           string           `json:"cookies_secret,omitempty"`
+	VariantSelection        string           `json:"variant_selection"`
+	Variants                []string         `json:"variants,omitempty"`
+	ScheduledStartTime      *time.Time       `json:"scheduled_start_time,omitempty"`
+	StartDelayToleranceSec  int              `json:"start_delay_tolerance_sec"`
 }
 
 // Validate validates that config values are within acceptable ranges.
@@ -71,23 +143,187 @@ func (c MonitorConfig) Validate() error {
 	if c.SilenceThresholdSec < 0 {
 		return fmt.Errorf("silence_threshold_sec must be non-negative")
 	}
-	if c.SilenceDBThreshold > 0 {
-		return fmt.Errorf("silence_db_threshold must be non-positive (in dB)")
+	// The detection tunables below have no "disabled" value; 0 means "use
+	// the default" and is replaced by DefaultUnsetTunables before this runs.
+	if c.SilenceDBThreshold >= 0 {
+		return fmt.Errorf("silence_db_threshold must be negative (in dB)")
+	}
+	if c.SilenceMinDurationSec <= 0 {
+		return fmt.Errorf("silence_min_duration_sec must be greater than 0")
+	}
+	if c.SilenceRatioThreshold <= 0 || c.SilenceRatioThreshold > 1 {
+		return fmt.Errorf("silence_ratio_threshold must be greater than 0 and at most 1")
+	}
+	if c.BlackMinDurationSec <= 0 {
+		return fmt.Errorf("black_min_duration_sec must be greater than 0")
+	}
+	if c.BlackPixelThreshold <= 0 || c.BlackPixelThreshold > 1 {
+		return fmt.Errorf("black_pixel_threshold must be greater than 0 and at most 1")
+	}
+	if c.BlackRatioThreshold <= 0 || c.BlackRatioThreshold > 1 {
+		return fmt.Errorf("black_ratio_threshold must be greater than 0 and at most 1")
+	}
+	if c.FreezeThresholdSec < 0 {
+		return fmt.Errorf("freeze_threshold_sec must be non-negative")
+	}
+	if c.FreezeNoiseDB >= 0 {
+		return fmt.Errorf("freeze_noise_db must be negative (in dB)")
+	}
+	if c.FreezeMinDurationSec <= 0 {
+		return fmt.Errorf("freeze_min_duration_sec must be greater than 0")
+	}
+	if c.FreezeRatioThreshold <= 0 || c.FreezeRatioThreshold > 1 {
+		return fmt.Errorf("freeze_ratio_threshold must be greater than 0 and at most 1")
+	}
+	if c.LoudnessMinLUFS > 0 || c.LoudnessMaxLUFS > 0 {
+		return fmt.Errorf("loudness_min_lufs and loudness_max_lufs must be non-positive (in LUFS)")
+	}
+	if c.LoudnessMinLUFS != 0 && c.LoudnessMaxLUFS != 0 && c.LoudnessMinLUFS >= c.LoudnessMaxLUFS {
+		return fmt.Errorf("loudness_min_lufs must be less than loudness_max_lufs")
+	}
+	if c.LoudnessThresholdSec < 0 {
+		return fmt.Errorf("loudness_threshold_sec must be non-negative")
+	}
+	if c.MinVideoHeight < 0 {
+		return fmt.Errorf("min_video_height must be non-negative")
+	}
+	if c.MinBitrateKbps < 0 {
+		return fmt.Errorf("min_bitrate_kbps must be non-negative")
+	}
+	if c.QualityDegradedSegments < 0 {
+		return fmt.Errorf("quality_degraded_segments must be non-negative")
+	}
+	if c.CatchupMaxSegments < 0 {
+		return fmt.Errorf("catchup_max_segments must be non-negative")
+	}
+	if c.AVDesyncMaxOffsetMs < 0 {
+		return fmt.Errorf("av_desync_max_offset_ms must be non-negative")
+	}
+	if c.AVDesyncThresholdSec < 0 {
+		return fmt.Errorf("av_desync_threshold_sec must be non-negative")
+	}
+	if len(c.SlateReferences) > MaxSlateReferences {
+		return fmt.Errorf("slate_references must contain at most %d entries", MaxSlateReferences)
+	}
+	slateNames := make(map[string]bool, len(c.SlateReferences))
+	for _, ref := range c.SlateReferences {
+		if strings.TrimSpace(ref.Name) == "" {
+			return fmt.Errorf("slate_references entries must have a name")
+		}
+		if slateNames[ref.Name] {
+			return fmt.Errorf("slate_references name %q is duplicated", ref.Name)
+		}
+		slateNames[ref.Name] = true
+		if _, err := ref.ImageData(); err != nil {
+			return fmt.Errorf("slate_references: %w", err)
+		}
+	}
+	if c.SlateMatchThreshold <= 0 || c.SlateMatchThreshold > 1 {
+		return fmt.Errorf("slate_match_threshold must be greater than 0 and at most 1")
+	}
+	if c.SlateMaxMotion < 0 || c.SlateMaxMotion > 1 {
+		return fmt.Errorf("slate_max_motion must be between 0 and 1")
+	}
+	if c.SlateThresholdSec < 0 {
+		return fmt.Errorf("slate_threshold_sec must be non-negative")
+	}
+	if c.ToneFrequencyHz < 0 || c.ToneFrequencyHz > MaxToneFrequencyHz {
+		return fmt.Errorf("tone_frequency_hz must be between 0 and %d", MaxToneFrequencyHz)
+	}
+	if c.CaptionsThresholdSec < 0 {
+		return fmt.Errorf("captions_threshold_sec must be non-negative")
+	}
+	if c.LatencyMaxSec < 0 {
+		return fmt.Errorf("latency_max_sec must be non-negative")
+	}
+	if c.LatencyThresholdSec < 0 {
+		return fmt.Errorf("latency_threshold_sec must be non-negative")
+	}
+	if len(c.Variants) > MaxMonitorVariants {
+		return fmt.Errorf("variants must contain at most %d entries", MaxMonitorVariants)
+	}
+	for _, v := range c.Variants {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("variants must not contain empty entries")
+		}
 	}
 	if c.StartDelayToleranceSec < 0 {
 		return fmt.Errorf("start_delay_tolerance_sec must be non-negative")
 	}
+	if c.CookiesSecret != "" {
+		if errs := k8svalidation.IsDNS1123Subdomain(c.CookiesSecret); len(errs) > 0 {
+			return fmt.Errorf("cookies_secret: %s", strings.Join(errs, "; "))
+		}
+	}
 	return nil
 }
 
+// DefaultUnsetTunables replaces detection tunables that are 0 with their
+// defaults. These tunables have no "disabled" value, so 0 means "use the
+// default" both for clients and for objects created before a tunable
+// existed.
+func (c *MonitorConfig) DefaultUnsetTunables() {
+	defaults := DefaultMonitorConfig()
+	if c.SilenceDBThreshold == 0 {
+		c.SilenceDBThreshold = defaults.SilenceDBThreshold
+	}
+	if c.SilenceMinDurationSec == 0 {
+		c.SilenceMinDurationSec = defaults.SilenceMinDurationSec
+	}
+	if c.SilenceRatioThreshold == 0 {
+		c.SilenceRatioThreshold = defaults.SilenceRatioThreshold
+	}
+	if c.BlackMinDurationSec == 0 {
+		c.BlackMinDurationSec = defaults.BlackMinDurationSec
+	}
+	if c.BlackPixelThreshold == 0 {
+		c.BlackPixelThreshold = defaults.BlackPixelThreshold
+	}
+	if c.BlackRatioThreshold == 0 {
+		c.BlackRatioThreshold = defaults.BlackRatioThreshold
+	}
+	if c.FreezeNoiseDB == 0 {
+		c.FreezeNoiseDB = defaults.FreezeNoiseDB
+	}
+	if c.FreezeMinDurationSec == 0 {
+		c.FreezeMinDurationSec = defaults.FreezeMinDurationSec
+	}
+	if c.FreezeRatioThreshold == 0 {
+		c.FreezeRatioThreshold = defaults.FreezeRatioThreshold
+	}
+	if c.SlateMatchThreshold == 0 {
+		c.SlateMatchThreshold = defaults.SlateMatchThreshold
+	}
+}
+
 // DefaultMonitorConfig returns the default monitor configuration.
 func DefaultMonitorConfig() MonitorConfig {
 	return MonitorConfig{
-		CheckIntervalSec:       10,
-		BlackoutThresholdSec:   30,
-		SilenceThresholdSec:    30,
-		SilenceDBThreshold:     -50,
-		StartDelayToleranceSec: 300,
+		CheckIntervalSec:        10,
+		BlackoutThresholdSec:    30,
+		SilenceThresholdSec:     30,
+		SilenceDBThreshold:      -50,
+		SilenceMinDurationSec:   0.5,
+		SilenceRatioThreshold:   0.9,
+		BlackMinDurationSec:     0.1,
+		BlackPixelThreshold:     0.10,
+		BlackRatioThreshold:     0.9,
+		FreezeThresholdSec:      30,
+		FreezeNoiseDB:           -60,
+		FreezeMinDurationSec:    0.5,
+		FreezeRatioThreshold:    0.9,
+		LoudnessMinLUFS:         -30,
+		LoudnessMaxLUFS:         -8,
+		LoudnessThresholdSec:    60,
+		QualityDegradedSegments: 3,
+		CatchupMaxSegments:      10,
+		AVDesyncThresholdSec:    30,
+		SlateMatchThreshold:     0.9,
+		SlateThresholdSec:       30,
+		CaptionsThresholdSec:    30,
+		LatencyThresholdSec:     30,
+		VariantSelection:        "lowest",
+		StartDelayToleranceSec:  300,
 	}
 }
 
@@ -96,6 +332,7 @@ type Monitor struct {
 	ID          string          `json:"id"`
 	UID         types.UID       `json:"-"`
 	StreamURL   string          `json:"stream_url"`
+	VideoID     string          `json:"video_id,omitempty"`
 	CallbackURL string          `json:"callback_url"`
 	Config      MonitorConfig   `json:"config"`
 	Metadata    json.RawMessage `json:"metadata,omitempty"`
@@ -120,14 +357,31 @@ type Monitor struct {
 
 // MonitorStats represents monitoring statistics.
 type MonitorStats struct {
-	MonitorID      string       `json:"monitor_id"`
-	TotalSegments  int          `json:"total_segments"`
-	BlackoutEvents int          `json:"blackout_events"`
-	SilenceEvents  int          `json:"silence_events"`
-	LastCheckAt    *time.Time   `json:"last_check_at,omitempty"`
-	VideoHealth    HealthStatus `json:"video_health"`
-	AudioHealth    HealthStatus `json:"audio_health"`
-	StreamStatus   StreamStatus `json:"stream_status"`
+	MonitorID             string       `json:"monitor_id"`
+	TotalSegments         int          `json:"total_segments"`
+	SkippedSegments       int          `json:"skipped_segments"`
+	BlackoutEvents        int          `json:"blackout_events"`
+	SilenceEvents         int          `json:"silence_events"`
+	ChannelSilenceEvents  int          `json:"channel_silence_events"`
+	QualityDegradedEvents int          `json:"quality_degraded_events"`
+	FreezeEvents          int          `json:"freeze_events"`
+	LoudnessEvents        int          `json:"loudness_events"`
+	AVDesyncEvents        int          `json:"av_desync_events"`
+	SlateEvents           int          `json:"slate_events"`
+	ToneEvents            int          `json:"tone_events"`
+	CaptionsEvents        int          `json:"captions_events"`
+	LatencyEvents         int          `json:"latency_events"`
+	PlaylistAnomalyEvents int          `json:"playlist_anomaly_events"`
+	VideoWidth            int          `json:"video_width"`
+	VideoHeight           int          `json:"video_height"`
+	FrameRate             float64      `json:"frame_rate"`
+	BitrateKbps           float64      `json:"bitrate_kbps"`
+	AVOffsetMs            float64      `json:"av_offset_ms"`
+	LatencySec            float64      `json:"latency_sec"`
+	LastCheckAt           *time.Time   `json:"last_check_at,omitempty"`
+	VideoHealth           HealthStatus `json:"video_health"`
+	AudioHealth           HealthStatus `json:"audio_health"`
+	StreamStatus          StreamStatus `json:"stream_status"`
 }
 
 // MonitorWithStats combines monitor and its stats for API responses.
@@ -22,17 +22,39 @@ import (
 type EventType string
 
 const (
-	EventStreamStarted          EventType = "stream.started"
-	EventStreamEnded            EventType = "stream.ended"
-	EventStreamDelayed          EventType = "stream.delayed"
-	EventStreamSuspended        EventType = "stream.suspended"
-	EventStreamResumed          EventType = "stream.resumed"
-	EventAlertBlackout          EventType = "alert.blackout"
-	EventAlertBlackoutRecovered EventType = "alert.blackout_recovered"
-	EventAlertSilence           EventType = "alert.silence"
-	EventAlertSilenceRecovered  EventType = "alert.silence_recovered"
-	EventAlertSegmentError      EventType = "alert.segment_error"
-	EventMonitorError           EventType = "monitor.error"
+	EventStreamStarted                EventType = "stream.started"
+	EventStreamEnded                  EventType = "stream.ended"
+	EventStreamDelayed                EventType = "stream.delayed"
+	EventStreamSuspended              EventType = "stream.suspended"
+	EventStreamResumed                EventType = "stream.resumed"
+	EventStreamUnavailable            EventType = "stream.unavailable"
+	EventAlertBlackout                EventType = "alert.blackout"
+	EventAlertBlackoutRecovered       EventType = "alert.blackout_recovered"
+	EventAlertSilence                 EventType = "alert.silence"
+	EventAlertSilenceRecovered        EventType = "alert.silence_recovered"
+	EventAlertChannelSilence          EventType = "alert.channel_silence"
+	EventAlertChannelSilenceRecovered EventType = "alert.channel_silence_recovered"
+	EventAlertFrozen                  EventType = "alert.frozen"
+	EventAlertFrozenRecovered         EventType = "alert.frozen_recovered"
+	EventAlertLoudness                EventType = "alert.loudness"
+	EventAlertLoudnessRecovered       EventType = "alert.loudness_recovered"
+	EventAlertQualityDegraded         EventType = "alert.quality_degraded"
+	EventAlertQualityRecovered        EventType = "alert.quality_recovered"
+	EventAlertAVDesync                EventType = "alert.av_desync"
+	EventAlertAVDesyncRecovered       EventType = "alert.av_desync_recovered"
+	EventAlertSlate                   EventType = "alert.slate"
+	EventAlertSlateRecovered          EventType = "alert.slate_recovered"
+	EventAlertTone                    EventType = "alert.tone"
+	EventAlertToneRecovered           EventType = "alert.tone_recovered"
+	EventAlertCaptionsMissing         EventType = "alert.captions_missing"
+	EventAlertCaptionsRecovered       EventType = "alert.captions_recovered"
+	EventAlertHighLatency             EventType = "alert.high_latency"
+	EventAlertHighLatencyRecovered    EventType = "alert.high_latency_recovered"
+	EventAlertPlaylistAnomaly         EventType = "alert.playlist_anomaly"
+	EventAlertEncryptionUnsupported   EventType = "alert.encryption_unsupported"
+	EventAlertAuthExpired             EventType = "alert.auth_expired"
+	EventAlertSegmentError            EventType = "alert.segment_error"
+	EventMonitorError                 EventType = "monitor.error"
 )
 
 // Payload represents a webhook payload.
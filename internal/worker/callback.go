@@ -31,12 +31,40 @@ func NewCallbackClient(baseURL, internalAPIKey string) *CallbackClient {
 
 // StatusUpdate contains fields for updating monitor status.
 type StatusUpdate struct {
-	StreamStatus   string `json:"stream_status,omitempty"`
-	VideoHealth    string `json:"video_health,omitempty"`
-	AudioHealth    string `json:"audio_health,omitempty"`
-	TotalSegments  int    `json:"total_segments,omitempty"`
-	BlackoutEvents int    `json:"blackout_events,omitempty"`
-	SilenceEvents  int    `json:"silence_events,omitempty"`
+	StreamStatus          string  `json:"stream_status,omitempty"`
+	VideoHealth           string  `json:"video_health,omitempty"`
+	AudioHealth           string  `json:"audio_health,omitempty"`
+	TotalSegments         int     `json:"total_segments,omitempty"`
+	SkippedSegments       int     `json:"skipped_segments,omitempty"`
+	BlackoutEvents        int     `json:"blackout_events,omitempty"`
+	SilenceEvents         int     `json:"silence_events,omitempty"`
+	ChannelSilenceEvents  int     `json:"channel_silence_events,omitempty"`
+	QualityDegradedEvents int     `json:"quality_degraded_events,omitempty"`
+	FreezeEvents          int     `json:"freeze_events,omitempty"`
+	LoudnessEvents        int     `json:"loudness_events,omitempty"`
+	AVDesyncEvents        int     `json:"av_desync_events,omitempty"`
+	SlateEvents           int     `json:"slate_events,omitempty"`
+	ToneEvents            int     `json:"tone_events,omitempty"`
+	CaptionsEvents        int     `json:"captions_events,omitempty"`
+	LatencyEvents         int     `json:"latency_events,omitempty"`
+	PlaylistAnomalyEvents int     `json:"playlist_anomaly_events,omitempty"`
+	VideoWidth            int     `json:"video_width,omitempty"`
+	VideoHeight           int     `json:"video_height,omitempty"`
+	FrameRate             float64 `json:"frame_rate,omitempty"`
+	BitrateKbps           float64 `json:"bitrate_kbps,omitempty"`
+	// AVOffsetMs and LatencySec are nil until measured; a measured 0 is
+	// still sent so the gateway does not keep a stale value.
+	AVOffsetMs *float64 `json:"av_offset_ms,omitempty"`
+	LatencySec *float64 `json:"latency_sec,omitempty"`
+}
+
+// hasStatistics reports whether any counter or measurement in u is set.
+func (u *StatusUpdate) hasStatistics() bool {
+	events := u.BlackoutEvents + u.SilenceEvents + u.ChannelSilenceEvents + u.FreezeEvents + u.LoudnessEvents +
+		u.QualityDegradedEvents + u.AVDesyncEvents + u.SlateEvents + u.ToneEvents +
+		u.CaptionsEvents + u.LatencyEvents + u.PlaylistAnomalyEvents
+	return u.TotalSegments > 0 || u.SkippedSegments > 0 || events > 0 ||
+		u.VideoHeight > 0 || u.AVOffsetMs != nil || u.LatencySec != nil
 }
 
 // StatusRequest is the request body for status update.
@@ -48,9 +76,26 @@ type StatusRequest struct {
 		Audio string `json:"audio"`
 	} `json:"health,omitempty"`
 	Statistics *struct {
-		TotalSegmentsAnalyzed int `json:"total_segments_analyzed,omitempty"`
-		BlackoutEvents        int `json:"blackout_events,omitempty"`
-		SilenceEvents         int `json:"silence_events,omitempty"`
+		TotalSegmentsAnalyzed int      `json:"total_segments_analyzed,omitempty"`
+		TotalSegmentsSkipped  int      `json:"total_segments_skipped,omitempty"`
+		BlackoutEvents        int      `json:"blackout_events,omitempty"`
+		SilenceEvents         int      `json:"silence_events,omitempty"`
+		ChannelSilenceEvents  int      `json:"channel_silence_events,omitempty"`
+		QualityDegradedEvents int      `json:"quality_degraded_events,omitempty"`
+		FreezeEvents          int      `json:"freeze_events,omitempty"`
+		LoudnessEvents        int      `json:"loudness_events,omitempty"`
+		AVDesyncEvents        int      `json:"av_desync_events,omitempty"`
+		SlateEvents           int      `json:"slate_events,omitempty"`
+		ToneEvents            int      `json:"tone_events,omitempty"`
+		CaptionsEvents        int      `json:"captions_events,omitempty"`
+		LatencyEvents         int      `json:"latency_events,omitempty"`
+		PlaylistAnomalyEvents int      `json:"playlist_anomaly_events,omitempty"`
+		VideoWidth            int      `json:"video_width,omitempty"`
+		VideoHeight           int      `json:"video_height,omitempty"`
+		FrameRate             float64  `json:"frame_rate,omitempty"`
+		BitrateKbps           float64  `json:"bitrate_kbps,omitempty"`
+		AVOffsetMs            *float64 `json:"av_offset_ms,omitempty"`
+		LatencySec            *float64 `json:"latency_sec,omitempty"`
 	} `json:"statistics,omitempty"`
 }
 
@@ -79,15 +124,49 @@ func (c *CallbackClient) ReportStatus(ctx context.Context, monitorID string, sta
 				Audio: update.AudioHealth,
 			}
 		}
-		if update.TotalSegments > 0 || update.BlackoutEvents > 0 || update.SilenceEvents > 0 {
+		if update.hasStatistics() {
 			req.Statistics = &struct {
-				TotalSegmentsAnalyzed int `json:"total_segments_analyzed,omitempty"`
-				BlackoutEvents        int `json:"blackout_events,omitempty"`
-				SilenceEvents         int `json:"silence_events,omitempty"`
+				TotalSegmentsAnalyzed int      `json:"total_segments_analyzed,omitempty"`
+				TotalSegmentsSkipped  int      `json:"total_segments_skipped,omitempty"`
+				BlackoutEvents        int      `json:"blackout_events,omitempty"`
+				SilenceEvents         int      `json:"silence_events,omitempty"`
+				ChannelSilenceEvents  int      `json:"channel_silence_events,omitempty"`
+				QualityDegradedEvents int      `json:"quality_degraded_events,omitempty"`
+				FreezeEvents          int      `json:"freeze_events,omitempty"`
+				LoudnessEvents        int      `json:"loudness_events,omitempty"`
+				AVDesyncEvents        int      `json:"av_desync_events,omitempty"`
+				SlateEvents           int      `json:"slate_events,omitempty"`
+				ToneEvents            int      `json:"tone_events,omitempty"`
+				CaptionsEvents        int      `json:"captions_events,omitempty"`
+				LatencyEvents         int      `json:"latency_events,omitempty"`
+				PlaylistAnomalyEvents int      `json:"playlist_anomaly_events,omitempty"`
+				VideoWidth            int      `json:"video_width,omitempty"`
+				VideoHeight           int      `json:"video_height,omitempty"`
+				FrameRate             float64  `json:"frame_rate,omitempty"`
+				BitrateKbps           float64  `json:"bitrate_kbps,omitempty"`
+				AVOffsetMs            *float64 `json:"av_offset_ms,omitempty"`
+				LatencySec            *float64 `json:"latency_sec,omitempty"`
 			}{
 				TotalSegmentsAnalyzed: update.TotalSegments,
+				TotalSegmentsSkipped:  update.SkippedSegments,
 				BlackoutEvents:        update.BlackoutEvents,
 				SilenceEvents:         update.SilenceEvents,
+				ChannelSilenceEvents:  update.ChannelSilenceEvents,
+				QualityDegradedEvents: update.QualityDegradedEvents,
+				FreezeEvents:          update.FreezeEvents,
+				LoudnessEvents:        update.LoudnessEvents,
+				AVDesyncEvents:        update.AVDesyncEvents,
+				SlateEvents:           update.SlateEvents,
+				ToneEvents:            update.ToneEvents,
+				CaptionsEvents:        update.CaptionsEvents,
+				LatencyEvents:         update.LatencyEvents,
+				PlaylistAnomalyEvents: update.PlaylistAnomalyEvents,
+				VideoWidth:            update.VideoWidth,
+				VideoHeight:           update.VideoHeight,
+				FrameRate:             update.FrameRate,
+				BitrateKbps:           update.BitrateKbps,
+				AVOffsetMs:            update.AVOffsetMs,
+				LatencySec:            update.LatencySec,
 			}
 		}
 	}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/xpadev-net/youtube-stream-tracker/internal/log"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtube"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/ytdlp"
+)
+
+// fallbackYtDlpClient asks primary first and falls back to fallback when
+// primary fails to reach or read the player endpoint, so the native player
+// client can be used with yt-dlp as a safety net.
+type fallbackYtDlpClient struct {
+	primary  YtDlpClient
+	fallback YtDlpClient
+}
+
+func (c *fallbackYtDlpClient) IsStreamLive(ctx context.Context, streamURL string) (bool, *ytdlp.StreamInfo, error) {
+	isLive, info, err := c.primary.IsStreamLive(ctx, streamURL)
+	if err == nil || !shouldFallBack(ctx, err) {
+		return isLive, info, err
+	}
+	log.Warn("stream status lookup failed, falling back to yt-dlp", zap.Error(err))
+	return c.fallback.IsStreamLive(ctx, streamURL)
+}
+
+func (c *fallbackYtDlpClient) GetManifestURL(ctx context.Context, streamURL string) (string, error) {
+	manifestURL, err := c.primary.GetManifestURL(ctx, streamURL)
+	if err == nil || !shouldFallBack(ctx, err) {
+		return manifestURL, err
+	}
+	log.Warn("manifest URL lookup failed, falling back to yt-dlp", zap.Error(err))
+	return c.fallback.GetManifestURL(ctx, streamURL)
+}
+
+// shouldFallBack reports whether err from primary is a transport or parse
+// failure that yt-dlp may get past. A playability error is YouTube's answer
+// for the video, which yt-dlp would get too, and a cancelled context would
+// stop yt-dlp as well.
+func shouldFallBack(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var playability *youtube.PlayabilityError
+	return !errors.As(err, &playability)
+}
@@ -3,18 +3,24 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/xpadev-net/youtube-stream-tracker/internal/config"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/cookies"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/evidence"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ffmpeg"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/log"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/manifest"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/webhook"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtube"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ytdlp"
 )
 
@@ -32,6 +38,15 @@ const (
 // a stream.suspended alert is fired.
 const suspensionAlertThreshold = 10 * time.Second
 
+// evidenceCaptureTimeout bounds extracting and uploading alert evidence.
+// Capture runs on the detection path and delays the alert webhook, so a
+// slow sink loses the evidence rather than holding up the alert.
+const evidenceCaptureTimeout = 5 * time.Second
+
+// minPartWindowSec is the shortest run of LL-HLS parts analyzed at once:
+// the 400ms window of ebur128's momentary loudness.
+const minPartWindowSec = 0.4
+
 // WebhookSender provides webhook delivery.
 type WebhookSender interface {
 	Send(ctx context.Context, url string, payload *webhook.Payload) *webhook.SendResult
@@ -39,18 +54,21 @@ type WebhookSender interface {
 
 // ManifestParser provides manifest parsing operations.
 type ManifestParser interface {
-	GetLatestSegment(ctx context.Context, manifestURL string) (*manifest.Segment, error)
+	GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error)
+	GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error)
 	IsEndList(ctx context.Context, manifestURL string) (bool, error)
-	FetchSegment(ctx context.Context, segmentURL string) ([]byte, error)
+	FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error)
 }
 
 // SegmentAnalyzer provides segment analysis operations.
 type SegmentAnalyzer interface {
 	EnsureTmpDir() error
 	CleanupMonitor(monitorID string) error
-	SaveSegment(monitorID string, data []byte) (string, error)
+	SaveSegment(monitorID string, init, data []byte) (string, error)
 	CleanupSegment(segmentPath string) error
 	AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error)
+	ExtractThumbnail(ctx context.Context, segmentPath string) ([]byte, error)
+	ExtractAudioClip(ctx context.Context, segmentPath string, duration time.Duration) ([]byte, error)
 }
 
 // CallbackReporter provides gateway internal API operations.
@@ -65,40 +83,210 @@ type YtDlpClient interface {
 	GetManifestURL(ctx context.Context, streamURL string) (string, error)
 }
 
-// Worker monitors a single YouTube stream.
-type Worker struct {
-	cfg            *config.WorkerConfig
-	ytdlpClient    YtDlpClient
-	manifestParser ManifestParser
-	analyzer       SegmentAnalyzer
-	webhookSender  WebhookSender
-	callbackClient CallbackReporter
+// loudnessSample is one segment's contribution to the loudness window.
+type loudnessSample struct {
+	lufs     float64
+	truePeak float64
+	duration float64
+}
+
+// channelSilenceState tracks a single audio channel that has gone silent
+// while the rest of the mix still carries audio.
+type channelSilenceState struct {
+	start     time.Time
+	duration  float64
+	alertSent bool
+}
+
+// partWindow is a run of consecutive LL-HLS parts sharing one
+// initialization section, gathered until it is long enough to analyze.
+type partWindow struct {
+	initKey  string
+	init     []byte
+	data     []byte
+	parts    []*manifest.Segment
+	duration float64
+}
+
+// variantMonitor holds the segment tracking and detection state of one
+// monitored rendition. Fields are guarded by the owning Worker's mu.
+type variantMonitor struct {
+	index     int
+	selection manifest.VariantSelection
 
-	// State
-	mu                  sync.Mutex
-	state               State
-	streamStatus        model.StreamStatus
-	currentManifestURL  string
 	lastSegmentSequence uint64
 	lastSegmentURL      string
-	segmentErrorStart   *time.Time
-	segmentErrorSent    bool
-	lastLiveCheck       time.Time
 	lastSegmentInfo     *manifest.Segment
-	lastNewSegmentTime  time.Time
-	suspendedAlertSent  bool
-	manifestURLChanged  bool
+	// lastPartIndex is the LL-HLS part of lastSegmentSequence analyzed
+	// last, or -1 when the whole segment was.
+	lastPartIndex int
+	// partWindow holds the LL-HLS parts fetched but not yet analyzed.
+	partWindow *partWindow
+	// partsCountedSequence is the segment whose parts were last counted
+	// in totalSegments.
+	partsCountedSequence uint64
+	partsCounted         bool
+
+	// analysisSegmentPath is the temp file of the segment currently being
+	// processed; alert evidence is extracted from it before cleanup.
+	analysisSegmentPath string
+
+	// Init segment cache: the rendition's initialization section, fetched
+	// again only when its URL or byte range changes.
+	initSegmentKey  string
+	initSegmentData []byte
 
 	// Analysis state
 	blackoutStart      *time.Time
 	silenceStart       *time.Time
-	totalSegments      int
-	blackoutEvents     int
-	silenceEvents      int
+	freezeStart        *time.Time
 	blackoutAlertSent  bool
 	silenceAlertSent   bool
+	freezeAlertSent    bool
 	consecutiveBlack   float64
 	consecutiveSilence float64
+	consecutiveFreeze  float64
+
+	// Per-channel silence state, keyed by channel index.
+	channelSilence map[int]*channelSilenceState
+
+	// Loudness state: a rolling window of per-segment measurements
+	// covering the last LoudnessThreshold of measurable audio.
+	loudnessWindow    []loudnessSample
+	loudnessStart     *time.Time
+	loudnessAlertSent bool
+
+	// Video quality state: the latest segment's properties and the run of
+	// consecutive segments below the configured floor.
+	lastQuality          *ffmpeg.QualityResult
+	qualityDegradedCount int
+	qualityStart         *time.Time
+	qualityAlertSent     bool
+
+	// A/V sync state: the latest segment's measurement and how long the
+	// offset has stayed beyond AVDesyncMaxOffsetMs.
+	lastAVSync        *ffmpeg.AVSyncResult
+	avDesyncStart     *time.Time
+	avDesyncDuration  float64
+	avDesyncAlertSent bool
+
+	// Slate state: how long segments have looked like a slate or test
+	// card, and the reference that matched when the run started.
+	slateStart     *time.Time
+	slateDuration  float64
+	slateAlertSent bool
+	slateReference string
+
+	// Tone state, tracked like silence.
+	toneStart       *time.Time
+	toneAlertSent   bool
+	consecutiveTone float64
+
+	// Caption state: how long video segments have lacked closed captions.
+	captionsMissingStart    *time.Time
+	captionsMissingDuration float64
+	captionsAlertSent       bool
+
+	// Latency state: how far the newest segment lagged behind wall-clock
+	// time at the latest poll, and how long it has stayed above LatencyMax.
+	// lastLatency is nil until a manifest carries segment timestamps.
+	lastLatency      *float64
+	latencyStart     *time.Time
+	latencyDuration  float64
+	latencyAlertSent bool
+
+	// playlistValidator checks each refresh of the HLS media playlist.
+	playlistValidator manifest.PlaylistValidator
+
+	// unsupportedEncryption is the encryption method and key format last
+	// reported by alert.encryption_unsupported, empty while segments can
+	// be fetched.
+	unsupportedEncryption string
+}
+
+// newVariantMonitors builds one variantMonitor per distinct selection,
+// falling back to the single configured VariantSelection.
+func newVariantMonitors(cfg *config.WorkerConfig) []*variantMonitor {
+	selections := cfg.Variants
+	if len(selections) == 0 {
+		selections = []manifest.VariantSelection{cfg.VariantSelection}
+	}
+	seen := make(map[string]bool, len(selections))
+	variants := make([]*variantMonitor, 0, len(selections))
+	for _, selection := range selections {
+		if seen[selection.String()] {
+			continue
+		}
+		seen[selection.String()] = true
+		variants = append(variants, &variantMonitor{index: len(variants), selection: selection, lastPartIndex: -1})
+	}
+	return variants
+}
+
+// payload describes the variant for webhook data. Callers must hold w.mu.
+func (v *variantMonitor) payload() map[string]interface{} {
+	data := map[string]interface{}{
+		"index":     v.index,
+		"selection": v.selection.String(),
+	}
+	if v.lastSegmentInfo != nil {
+		if v.lastSegmentInfo.Bandwidth > 0 {
+			data["bandwidth"] = v.lastSegmentInfo.Bandwidth
+		}
+		if v.lastSegmentInfo.Height > 0 {
+			data["height"] = v.lastSegmentInfo.Height
+		}
+	}
+	return data
+}
+
+// Worker monitors a single YouTube stream.
+type Worker struct {
+	cfg            *config.WorkerConfig
+	ytdlpClient    YtDlpClient
+	manifestParser ManifestParser
+	analyzer       SegmentAnalyzer
+	webhookSender  WebhookSender
+	callbackClient CallbackReporter
+
+	// State
+	mu                 sync.Mutex
+	state              State
+	streamStatus       model.StreamStatus
+	currentManifestURL string
+	segmentErrorStart  *time.Time
+	segmentErrorSent   bool
+	lastLiveCheck      time.Time
+	lastNewSegmentTime time.Time
+	suspendedAlertSent bool
+	manifestURLChanged bool
+	evidenceSink       evidence.Sink
+
+	// authExpiredSent is set once alert.auth_expired has been sent for the
+	// current run of rejected sessions.
+	authExpiredSent bool
+
+	// variants holds per-rendition segment and detection state, in
+	// configuration order. It always has at least one entry.
+	variants []*variantMonitor
+
+	// Event counters, summed over all variants. skippedSegments counts
+	// segments that fell outside the catch-up backlog and were never
+	// analyzed.
+	totalSegments         int
+	skippedSegments       int
+	blackoutEvents        int
+	silenceEvents         int
+	channelSilenceEvents  int
+	freezeEvents          int
+	loudnessEvents        int
+	qualityDegradedEvents int
+	avDesyncEvents        int
+	slateEvents           int
+	toneEvents            int
+	captionsEvents        int
+	latencyEvents         int
+	playlistAnomalyEvents int
 
 	// Shutdown state
 	shutdownRequested bool
@@ -124,13 +312,56 @@ func NewWorkerWithDeps(
 	callbackClient CallbackReporter,
 ) *Worker {
 	if ytdlpClient == nil {
-		ytdlpClient = ytdlp.NewClient(cfg.YtDlpPath, cfg.StreamlinkPath, cfg.HTTPProxy, cfg.HTTPSProxy)
+		client := ytdlp.NewClient(cfg.YtDlpPath, cfg.StreamlinkPath, cfg.HTTPProxy, cfg.HTTPSProxy)
+		if cfg.CookiesFile != "" {
+			client.SetCookiesFile(cfg.CookiesFile, "/tmp/worker")
+		}
+		ytdlpClient = client
+		// The player endpoint is called without a session, so monitors
+		// with cookies go straight to yt-dlp.
+		if cfg.YouTubeNativeClient && cfg.CookiesFile == "" {
+			ytdlpClient = &fallbackYtDlpClient{
+				primary:  youtube.NewClient(cfg.YouTubeBaseURL, cfg.YouTubeClientVersion, cfg.HTTPProxy, cfg.HTTPSProxy, cfg.ManifestFetchTimeout),
+				fallback: ytdlpClient,
+			}
+		}
 	}
+	variants := newVariantMonitors(cfg)
 	if manifestParser == nil {
-		manifestParser = manifest.NewParserWithLimit(cfg.ManifestFetchTimeout, cfg.SegmentMaxBytes)
+		parser := manifest.NewParserWithLimit(cfg.ManifestFetchTimeout, cfg.SegmentMaxBytes)
+		// IsEndList follows the parser's own selection; use the first
+		// monitored variant so end-of-stream is read from a playlist we poll.
+		parser.SetVariantSelection(variants[0].selection)
+		if cfg.CookiesFile != "" {
+			jar, err := cookies.NewFileJar(cfg.CookiesFile)
+			if err != nil {
+				log.Warn("manifest requests will be sent without cookies",
+					zap.String("cookies_file", cfg.CookiesFile),
+					zap.Error(err),
+				)
+			} else {
+				parser.SetCookieJar(jar)
+			}
+		}
+		manifestParser = parser
 	}
 	if analyzer == nil {
-		analyzer = ffmpeg.NewAnalyzer(cfg.FFmpegPath, cfg.FFprobePath, "/tmp/segments", cfg.SilenceDBThreshold)
+		analyzer = ffmpeg.NewAnalyzer(cfg.FFmpegPath, cfg.FFprobePath, "/tmp/segments", ffmpeg.DetectionParams{
+			SilenceDBThreshold:    cfg.SilenceDBThreshold,
+			SilenceMinDuration:    cfg.SilenceMinDuration,
+			SilenceRatioThreshold: cfg.SilenceRatioThreshold,
+			BlackMinDuration:      cfg.BlackMinDuration,
+			BlackPixelThreshold:   cfg.BlackPixelThreshold,
+			BlackRatioThreshold:   cfg.BlackRatioThreshold,
+			FreezeNoiseDB:         cfg.FreezeNoiseDB,
+			FreezeMinDuration:     cfg.FreezeMinDuration,
+			FreezeRatioThreshold:  cfg.FreezeRatioThreshold,
+			SlateReferences:       cfg.SlateReferences,
+			SlateMatchThreshold:   cfg.SlateMatchThreshold,
+			SlateMaxMotion:        cfg.SlateMaxMotion,
+			ToneFrequency:         float64(cfg.ToneFrequencyHz),
+			MeasureAVSync:         cfg.AVDesyncMaxOffsetMs > 0 && cfg.AVDesyncThreshold > 0,
+		})
 	}
 	if webhookSender == nil {
 		webhookSender = webhook.NewSender(cfg.WebhookSigningKey)
@@ -138,6 +369,19 @@ func NewWorkerWithDeps(
 	if callbackClient == nil {
 		callbackClient = NewCallbackClient(cfg.CallbackURL, cfg.InternalAPIKey)
 	}
+	evidenceSink, err := evidence.NewSink(evidence.Config{
+		Sink:              cfg.EvidenceSink,
+		BaseURL:           cfg.EvidenceBaseURL,
+		LocalDir:          cfg.EvidenceLocalDir,
+		S3Endpoint:        cfg.EvidenceS3Endpoint,
+		S3Bucket:          cfg.EvidenceS3Bucket,
+		S3Region:          cfg.EvidenceS3Region,
+		S3AccessKeyID:     cfg.EvidenceS3AccessKeyID,
+		S3SecretAccessKey: cfg.EvidenceS3SecretAccessKey,
+	})
+	if err != nil {
+		log.Warn("evidence capture disabled", zap.Error(err))
+	}
 	return &Worker{
 		cfg:            cfg,
 		ytdlpClient:    ytdlpClient,
@@ -145,6 +389,8 @@ func NewWorkerWithDeps(
 		analyzer:       analyzer,
 		webhookSender:  webhookSender,
 		callbackClient: callbackClient,
+		evidenceSink:   evidenceSink,
+		variants:       variants,
 		state:          StateWaiting,
 		streamStatus:   model.StreamStatusUnknown,
 		shutdownCh:     make(chan struct{}),
@@ -223,6 +469,9 @@ func (w *Worker) waitingMode(ctx context.Context) error {
 	// Check if delayed alert should be sent
 	delayAlertSent := false
 	firstCheck := true
+	// transientFailures counts consecutive rate-limit or bot-check
+	// failures, which back the polling off exponentially.
+	transientFailures := 0
 
 	for {
 		if w.getState() == StateError {
@@ -246,10 +495,50 @@ func (w *Worker) waitingMode(ctx context.Context) error {
 		firstCheck = false
 
 		isLive, info, err := w.ytdlpClient.IsStreamLive(ctx, w.cfg.StreamURL)
+		var unavailable *ytdlp.UnavailableError
+		if errors.As(err, &unavailable) {
+			// An expired session keeps polling with backoff so a rotated
+			// Secret is picked up.
+			authExpired := w.processAuthError(ctx, err)
+			if w.getState() == StateError {
+				w.reportStatus(ctx, model.StatusError, nil)
+				return fmt.Errorf("webhook delivery failed")
+			}
+			if unavailable.Reason.Permanent() && !authExpired {
+				log.Warn("stream unavailable, ending monitor",
+					zap.String("reason", string(unavailable.Reason)),
+					zap.String("message", unavailable.Message),
+				)
+				w.sendWebhook(ctx, webhook.EventStreamUnavailable, map[string]interface{}{
+					"reason":  string(unavailable.Reason),
+					"message": unavailable.Message,
+				})
+				if w.getState() == StateError {
+					w.reportStatus(ctx, model.StatusError, nil)
+					return fmt.Errorf("webhook delivery failed")
+				}
+				w.setState(StateCompleted)
+				w.reportStatus(ctx, model.StatusCompleted, nil)
+				return nil
+			}
+			transientFailures++
+			backoff := waitingModeBackoff(interval, transientFailures)
+			log.Warn("stream temporarily unavailable, backing off",
+				zap.String("reason", string(unavailable.Reason)),
+				zap.Duration("retry_in", backoff),
+			)
+			ticker.Reset(backoff)
+			continue
+		}
 		if err != nil {
 			log.Warn("failed to check stream status", zap.Error(err))
 			continue
 		}
+		w.resetAuthExpired()
+		if transientFailures > 0 {
+			transientFailures = 0
+			ticker.Reset(interval)
+		}
 
 		// Update stream status
 		if isLive {
@@ -332,6 +621,21 @@ func (w *Worker) waitingMode(ctx context.Context) error {
 	}
 }
 
+// maxWaitingModeBackoff caps the waiting mode polling interval while YouTube
+// is rate limiting or asking for a bot check.
+const maxWaitingModeBackoff = 10 * time.Minute
+
+// waitingModeBackoff returns the polling interval after failures consecutive
+// transient failures: interval doubled per failure, up to
+// maxWaitingModeBackoff.
+func waitingModeBackoff(interval time.Duration, failures int) time.Duration {
+	backoff := interval
+	for i := 0; i < failures && backoff < maxWaitingModeBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxWaitingModeBackoff)
+}
+
 // monitoringMode performs segment analysis.
 func (w *Worker) monitoringMode(ctx context.Context) error {
 	log.Info("entering monitoring mode")
@@ -340,8 +644,10 @@ func (w *Worker) monitoringMode(ctx context.Context) error {
 	// Get initial manifest URL
 	manifestURL, err := w.ytdlpClient.GetManifestURL(ctx, w.cfg.StreamURL)
 	if err != nil {
+		w.processAuthError(ctx, err)
 		return fmt.Errorf("get manifest URL: %w", err)
 	}
+	w.resetAuthExpired()
 	w.mu.Lock()
 	w.currentManifestURL = manifestURL
 	w.mu.Unlock()
@@ -379,7 +685,12 @@ func (w *Worker) monitoringMode(ctx context.Context) error {
 				newURL, err := w.ytdlpClient.GetManifestURL(ctx, w.cfg.StreamURL)
 				if err != nil {
 					log.Warn("failed to refresh manifest URL", zap.Error(err))
+					w.processAuthError(ctx, err)
+					if w.getState() == StateError {
+						return fmt.Errorf("webhook delivery failed")
+					}
 				} else {
+					w.resetAuthExpired()
 					w.mu.Lock()
 					if w.currentManifestURL != newURL {
 						w.manifestURLChanged = true
@@ -410,7 +721,7 @@ func (w *Worker) monitoringMode(ctx context.Context) error {
 		}
 
 		elapsed := time.Since(start)
-		if remaining := w.cfg.AnalysisInterval - elapsed; remaining > 0 {
+		if remaining := w.analysisInterval() - elapsed; remaining > 0 {
 			timer := time.NewTimer(remaining)
 			select {
 			case <-w.shutdownCh:
@@ -423,6 +734,23 @@ func (w *Worker) monitoringMode(ctx context.Context) error {
 	}
 }
 
+// analysisInterval returns the pause between analysis cycles. In
+// low-latency mode, once the first variant's playlist lists partial
+// segments, cycles follow the part target duration instead; with blocking
+// reloads most of that time is spent waiting on the playlist request.
+func (w *Worker) analysisInterval() time.Duration {
+	if !w.cfg.LowLatency {
+		return w.cfg.AnalysisInterval
+	}
+	w.mu.Lock()
+	info := w.variants[0].lastSegmentInfo
+	w.mu.Unlock()
+	if info == nil || info.Playlist == nil || info.Playlist.PartTargetDuration <= 0 {
+		return w.cfg.AnalysisInterval
+	}
+	return min(time.Duration(info.Playlist.PartTargetDuration*float64(time.Second)), w.cfg.AnalysisInterval)
+}
+
 // checkSuspension sends a stream.suspended webhook when no new segments
 // have appeared for longer than suspensionAlertThreshold.
 func (w *Worker) checkSuspension(ctx context.Context) {
@@ -438,7 +766,9 @@ func (w *Worker) checkSuspension(ctx context.Context) {
 	}
 }
 
-// analyzeLatestSegment fetches and analyzes the latest segment.
+// analyzeLatestSegment fetches and analyzes the latest segment of every
+// monitored variant. A failure on one variant does not stop the others;
+// the first error is returned once all variants have been tried.
 func (w *Worker) analyzeLatestSegment(ctx context.Context) error {
 	w.mu.Lock()
 	manifestURL := w.currentManifestURL
@@ -465,29 +795,40 @@ func (w *Worker) analyzeLatestSegment(ctx context.Context) error {
 		return nil
 	}
 
-	segment, err := w.manifestParser.GetLatestSegment(ctx, manifestURL)
-	if err != nil {
-		return fmt.Errorf("get latest segment: %w", err)
-	}
 	w.mu.Lock()
-	w.lastSegmentInfo = segment
 	isRebaseline := w.manifestURLChanged
 	w.manifestURLChanged = false
 	w.mu.Unlock()
 
-	// Skip if we already processed this segment.
-	// When EXT-X-MEDIA-SEQUENCE is absent, SeqNo defaults to 0 and the
-	// calculated Sequence may stay constant across polls even as the
-	// playlist slides forward. Fall back to URL comparison so that a
-	// segment with the same Sequence but a different URL is still processed.
-	if segment.Sequence < w.lastSegmentSequence {
-		w.checkSuspension(ctx)
-		if w.getState() == StateError {
-			return fmt.Errorf("webhook delivery failed")
+	var (
+		analyzed bool
+		firstErr error
+	)
+	for _, v := range w.variants {
+		if w.isShutdownRequested() {
+			log.Info("shutdown requested, skip remaining variants")
+			return nil
+		}
+		ok, err := w.analyzeVariantSegments(ctx, manifestURL, v, isRebaseline)
+		analyzed = analyzed || ok
+		if err != nil {
+			if w.getState() == StateError {
+				return err
+			}
+			log.Warn("variant segment analysis failed",
+				zap.String("variant", v.selection.String()),
+				zap.Error(err),
+			)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("variant %s: %w", v.selection, err)
+			}
 		}
-		return nil
 	}
-	if segment.Sequence == w.lastSegmentSequence && segment.URL == w.lastSegmentURL {
+
+	if !analyzed {
+		if firstErr != nil {
+			return firstErr
+		}
 		w.checkSuspension(ctx)
 		if w.getState() == StateError {
 			return fmt.Errorf("webhook delivery failed")
@@ -495,201 +836,1380 @@ func (w *Worker) analyzeLatestSegment(ctx context.Context) error {
 		return nil
 	}
 
-	log.Debug("analyzing segment",
-		zap.Uint64("sequence", segment.Sequence),
-		zap.Float64("duration", segment.Duration),
-	)
+	// Report status update
+	w.reportStatusUpdate(ctx)
 
-	// Download segment
-	data, err := w.manifestParser.FetchSegment(ctx, segment.URL)
-	if err != nil {
-		return fmt.Errorf("fetch segment: %w", err)
-	}
-	if w.isShutdownRequested() {
-		log.Info("shutdown requested, skip analyzing segment")
-		return nil
+	return firstErr
+}
+
+// analyzeVariantSegments analyzes, oldest first, every segment of one
+// variant published since the last analyzed one, up to CatchupMaxSegments.
+// Older segments beyond that backlog are counted as skipped. It reports
+// whether any new segment was analyzed.
+func (w *Worker) analyzeVariantSegments(ctx context.Context, manifestURL string, v *variantMonitor, isRebaseline bool) (bool, error) {
+	w.mu.Lock()
+	lastSequence := v.lastSegmentSequence
+	lastURL := v.lastSegmentURL
+	hasParts := v.lastSegmentInfo != nil && v.lastSegmentInfo.Playlist != nil && v.lastSegmentInfo.Playlist.PartTargetDuration > 0
+	w.mu.Unlock()
+
+	// In low-latency mode the partial segments are analyzed instead, once
+	// the playlist is known to have them; the first poll and a manifest URL
+	// change take their baseline from full segments.
+	if w.cfg.LowLatency && hasParts && !isRebaseline {
+		analyzed, err := w.analyzeVariantParts(ctx, manifestURL, v)
+		if !errors.Is(err, manifest.ErrNoPartialSegments) {
+			return analyzed, err
+		}
 	}
 
-	// Save segment to temp file
-	segmentPath, err := w.analyzer.SaveSegment(w.cfg.MonitorID, data)
-	if err != nil {
-		return fmt.Errorf("save segment: %w", err)
+	// Parts gathered but not yet analyzed are covered again by their full
+	// segment.
+	w.mu.Lock()
+	v.partWindow = nil
+	w.mu.Unlock()
+
+	// The first poll and a manifest URL change only establish a baseline,
+	// so there is no backlog to catch up on.
+	baseline := lastURL == "" || isRebaseline
+	limit := w.cfg.CatchupMaxSegments
+	if baseline || limit < 1 {
+		limit = 1
 	}
-	defer func() {
-		if err := w.analyzer.CleanupSegment(segmentPath); err != nil {
-			log.Warn("failed to cleanup segment file", zap.Error(err))
-		}
-	}()
 
-	// Analyze segment; allow in-flight ffmpeg work to finish even if shutdown is requested.
-	analysisCtx := context.WithoutCancel(ctx)
-	result, err := w.analyzer.AnalyzeSegment(analysisCtx, segmentPath)
+	segments, err := w.manifestParser.GetVariantSegmentsSince(ctx, manifestURL, v.selection, lastSequence, limit)
 	if err != nil {
-		return fmt.Errorf("analyze segment: %w", err)
+		return false, fmt.Errorf("get segments: %w", err)
+	}
+	fetchedAt := time.Now()
+	if len(segments) == 0 {
+		return false, fmt.Errorf("get segments: manifest returned no segments")
 	}
-
-	// Update state
 	w.mu.Lock()
-	w.lastSegmentSequence = segment.Sequence
-	w.lastSegmentURL = segment.URL
-	w.totalSegments++
-	if isRebaseline {
-		// Manifest URL changed — re-baseline segment tracking without
-		// triggering a false stream.resumed or resetting the suspension timer.
-		w.mu.Unlock()
-	} else {
-		wasSuspended := w.suspendedAlertSent
-		w.lastNewSegmentTime = time.Now()
-		w.suspendedAlertSent = false
-		w.mu.Unlock()
+	v.lastSegmentInfo = segments[len(segments)-1]
+	w.mu.Unlock()
+	w.processPlaylistConformance(ctx, v, playlistSegments(segments), fetchedAt, isRebaseline)
 
-		if wasSuspended {
-			w.sendWebhook(ctx, webhook.EventStreamResumed, nil)
-			if w.getState() == StateError {
-				return fmt.Errorf("webhook delivery failed")
-			}
+	// Skip segments we already processed.
+	// When EXT-X-MEDIA-SEQUENCE is absent, SeqNo defaults to 0 and the
+	// calculated Sequence may stay constant across polls even as the
+	// playlist slides forward. Fall back to URL comparison so that a
+	// segment with the same Sequence but a different URL is still processed.
+	var pending []*manifest.Segment
+	for _, segment := range segments {
+		if segment.Sequence < lastSequence {
+			continue
+		}
+		if segment.Sequence == lastSequence && segment.URL == lastURL {
+			continue
 		}
+		pending = append(pending, segment)
+	}
+	if len(pending) == 0 {
+		return false, nil
 	}
 
-	// Process results
-	w.processBlackDetection(ctx, result.Black, segment.Duration)
-	w.processSilenceDetection(ctx, result.Silence, segment.Duration)
-
-	// Report status update
-	w.reportStatusUpdate(ctx)
-
-	return nil
-}
+	if !baseline && pending[0].Sequence > lastSequence+1 {
+		skipped := int(pending[0].Sequence - lastSequence - 1)
+		log.Warn("segments fell outside catch-up backlog",
+			zap.String("variant", v.selection.String()),
+			zap.Uint64("last_sequence", lastSequence),
+			zap.Uint64("next_sequence", pending[0].Sequence),
+			zap.Int("skipped", skipped),
+		)
+		w.mu.Lock()
+		w.skippedSegments += skipped
+		w.mu.Unlock()
+	}
 
-// processBlackDetection handles blackout detection results.
-func (w *Worker) processBlackDetection(ctx context.Context, result *ffmpeg.BlackDetectResult, segmentDuration float64) {
-	var (
-		sendEvent bool
-		eventType webhook.EventType
-		data      map[string]interface{}
-	)
+	var newDuration float64
+	for _, segment := range pending {
+		newDuration += segment.Duration
+	}
+	w.processLatency(ctx, v, segments[len(segments)-1], fetchedAt, newDuration)
 
-	w.mu.Lock()
-	if result.FullyBlack {
-		w.consecutiveBlack += segmentDuration
-		if w.blackoutStart == nil {
-			now := time.Now()
-			w.blackoutStart = &now
-		}
-		if !w.blackoutAlertSent && w.consecutiveBlack >= w.cfg.BlackoutThreshold.Seconds() {
-			w.blackoutEvents++
-			w.blackoutAlertSent = true
-			startTime := *w.blackoutStart
-			duration := w.consecutiveBlack
-			thresholdSec := int(w.cfg.BlackoutThreshold.Seconds())
-			segmentInfo := w.segmentInfoPayload()
-			sendEvent = true
-			eventType = webhook.EventAlertBlackout
-			data = map[string]interface{}{
-				"duration_sec":  duration,
-				"started_at":    startTime.Format(time.RFC3339),
-				"threshold_sec": thresholdSec,
-			}
-			if segmentInfo != nil {
-				data["segment_info"] = segmentInfo
-			}
+	analyzed := false
+	for _, segment := range pending {
+		if w.isShutdownRequested() {
+			log.Info("shutdown requested, skip remaining segments")
+			return analyzed, nil
 		}
-	} else {
-		if w.blackoutAlertSent && w.blackoutStart != nil {
-			startTime := *w.blackoutStart
-			totalDuration := w.consecutiveBlack
-			w.blackoutAlertSent = false
-			sendEvent = true
-			eventType = webhook.EventAlertBlackoutRecovered
-			data = map[string]interface{}{
-				"total_duration_sec": totalDuration,
-				"started_at":         startTime.Format(time.RFC3339),
-				"recovered_at":       time.Now().Format(time.RFC3339),
-			}
+		ok, err := w.analyzeVariantSegment(ctx, v, segment, isRebaseline)
+		analyzed = analyzed || ok
+		if err != nil {
+			return analyzed, err
 		}
-		w.consecutiveBlack = 0
-		w.blackoutStart = nil
-	}
-	w.mu.Unlock()
-
-	if sendEvent {
-		w.sendWebhook(ctx, eventType, data)
 	}
+	return analyzed, nil
 }
 
-// processSilenceDetection handles silence detection results.
-func (w *Worker) processSilenceDetection(ctx context.Context, result *ffmpeg.SilenceDetectResult, segmentDuration float64) {
-	var (
-		sendEvent bool
-		eventType webhook.EventType
-		data      map[string]interface{}
-	)
+// analyzeVariantParts analyzes, oldest first, the LL-HLS partial segments
+// of one variant published since the last analyzed part, up to
+// CatchupMaxSegments parts, so that detection runs every few parts rather
+// than once per full segment. Playlist conformance and latency are checked
+// on every refresh, as for full segments. It returns
+// manifest.ErrNoPartialSegments when the playlist no longer lists parts.
+func (w *Worker) analyzeVariantParts(ctx context.Context, manifestURL string, v *variantMonitor) (bool, error) {
+	w.mu.Lock()
+	lastSequence := v.lastSegmentSequence
+	lastPart := v.lastPartIndex
+	w.mu.Unlock()
 
+	parts, err := w.manifestParser.GetVariantPartsSince(ctx, manifestURL, v.selection, lastSequence, lastPart, max(w.cfg.CatchupMaxSegments, 1))
+	if errors.Is(err, manifest.ErrNoPartialSegments) {
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("get partial segments: %w", err)
+	}
+	fetchedAt := time.Now()
+	if len(parts) == 0 {
+		return false, nil
+	}
 	w.mu.Lock()
-	if result.FullySilent {
-		w.consecutiveSilence += segmentDuration
+	v.lastSegmentInfo = parts[len(parts)-1]
+	w.mu.Unlock()
+	// The full segments of the same refresh are checked as usual; without
+	// them (a playlist that only lists parts) there is nothing to check.
+	if info := parts[len(parts)-1].Playlist; info != nil && len(info.Segments) > 0 {
+		w.processPlaylistConformance(ctx, v, info.Segments, fetchedAt, false)
+	}
 
-		if w.silenceStart == nil {
-			now := time.Now()
-			w.silenceStart = &now
+	// Skip the latest part returned on its own when nothing newer is listed.
+	var pending []*manifest.Segment
+	for _, part := range parts {
+		if part.Sequence < lastSequence || (part.Sequence == lastSequence && (lastPart < 0 || part.PartIndex <= lastPart)) {
+			continue
 		}
+		pending = append(pending, part)
+	}
 
-		if !w.silenceAlertSent && w.consecutiveSilence >= w.cfg.SilenceThreshold.Seconds() {
-			w.silenceEvents++
-			w.silenceAlertSent = true
-			startTime := *w.silenceStart
-			duration := w.consecutiveSilence
-			thresholdSec := int(w.cfg.SilenceThreshold.Seconds())
-			segmentInfo := w.segmentInfoPayload()
-			sendEvent = true
-			eventType = webhook.EventAlertSilence
-			data = map[string]interface{}{
-				"duration_sec":  duration,
-				"started_at":    startTime.Format(time.RFC3339),
-				"threshold_sec": thresholdSec,
-			}
-			if segmentInfo != nil {
-				data["segment_info"] = segmentInfo
-			}
+	var newDuration float64
+	for _, part := range pending {
+		newDuration += part.Duration
+	}
+	w.processLatency(ctx, v, parts[len(parts)-1], fetchedAt, newDuration)
+
+	analyzed := false
+	for _, part := range pending {
+		if w.isShutdownRequested() {
+			log.Info("shutdown requested, skip remaining partial segments")
+			return analyzed, nil
 		}
-	} else {
-		if w.silenceAlertSent && w.silenceStart != nil {
-			startTime := *w.silenceStart
-			totalDuration := w.consecutiveSilence
-			w.silenceAlertSent = false
-			sendEvent = true
-			eventType = webhook.EventAlertSilenceRecovered
-			data = map[string]interface{}{
-				"total_duration_sec": totalDuration,
-				"started_at":         startTime.Format(time.RFC3339),
-				"recovered_at":       time.Now().Format(time.RFC3339),
-			}
+		ok, err := w.analyzeVariantPart(ctx, v, part)
+		analyzed = analyzed || ok
+		if err != nil {
+			return analyzed, err
 		}
-		w.consecutiveSilence = 0
-		w.silenceStart = nil
 	}
-	w.mu.Unlock()
+	return analyzed, nil
+}
 
-	if sendEvent {
-		w.sendWebhook(ctx, eventType, data)
+// fetchInitSegment returns the initialization section segment needs, or
+// nil when it is self-contained. The section is cached per variant and only
+// downloaded again when the manifest points at a different one.
+func (w *Worker) fetchInitSegment(ctx context.Context, v *variantMonitor, segment *manifest.Segment) ([]byte, error) {
+	if segment.Init == nil {
+		return nil, nil
 	}
-}
+	key := initSectionKey(segment)
 
-// handleSegmentError handles segment fetch/analysis errors.
-func (w *Worker) handleSegmentError(ctx context.Context, err error) bool {
 	w.mu.Lock()
-	if w.state == StateCompleted {
+	if v.initSegmentKey == key {
+		data := v.initSegmentData
 		w.mu.Unlock()
-		return true
+		return data, nil
 	}
+	w.mu.Unlock()
 
-	if w.segmentErrorStart == nil {
-		now := time.Now()
-		w.segmentErrorStart = &now
-		w.segmentErrorSent = false
+	data, err := w.manifestParser.FetchSegment(ctx, segment.Init)
+	if err != nil {
+		return nil, err
 	}
+	w.mu.Lock()
+	v.initSegmentKey = key
+	v.initSegmentData = data
+	w.mu.Unlock()
+	return data, nil
+}
 
-	if time.Since(*w.segmentErrorStart) >= 60*time.Second && !w.segmentErrorSent {
+// initSectionKey identifies the initialization section segment needs, or
+// returns "" when it is self-contained.
+func initSectionKey(segment *manifest.Segment) string {
+	if segment.Init == nil {
+		return ""
+	}
+	key := segment.Init.URL
+	if r := segment.Init.ByteRange; r != nil {
+		key = fmt.Sprintf("%s@%d+%d", key, r.Offset, r.Length)
+	}
+	return key
+}
+
+// advanceSegment records segment as the variant's latest processed one and
+// resets the suspension timer, sending stream.resumed if the stream had
+// been reported suspended.
+func (w *Worker) advanceSegment(ctx context.Context, v *variantMonitor, segment *manifest.Segment, isRebaseline bool) error {
+	w.mu.Lock()
+	v.lastSegmentSequence = segment.Sequence
+	v.lastSegmentURL = segment.URL
+	v.lastPartIndex = -1
+	if segment.Partial {
+		v.lastPartIndex = segment.PartIndex
+	}
+	if isRebaseline {
+		// Manifest URL changed — re-baseline segment tracking without
+		// triggering a false stream.resumed or resetting the suspension timer.
+		w.mu.Unlock()
+		return nil
+	}
+	wasSuspended := w.suspendedAlertSent
+	w.lastNewSegmentTime = time.Now()
+	w.suspendedAlertSent = false
+	w.mu.Unlock()
+
+	if wasSuspended {
+		w.sendWebhook(ctx, webhook.EventStreamResumed, nil)
+		if w.getState() == StateError {
+			return fmt.Errorf("webhook delivery failed")
+		}
+	}
+	return nil
+}
+
+// processUnsupportedEncryption sends alert.encryption_unsupported when a
+// variant's segments turn out to use encryption that cannot be decrypted.
+// It is sent once per method and key format until a segment can be
+// fetched again.
+func (w *Worker) processUnsupportedEncryption(ctx context.Context, v *variantMonitor, segment *manifest.Segment, encErr *manifest.UnsupportedEncryptionError) {
+	scheme := encErr.Error()
+	w.mu.Lock()
+	if v.unsupportedEncryption == scheme {
+		w.mu.Unlock()
+		return
+	}
+	v.unsupportedEncryption = scheme
+	w.mu.Unlock()
+
+	log.Warn("segment encryption not supported",
+		zap.String("variant", v.selection.String()),
+		zap.Uint64("sequence", segment.Sequence),
+		zap.Error(encErr),
+	)
+	data := map[string]interface{}{
+		"method":   encErr.Method,
+		"sequence": segment.Sequence,
+	}
+	if encErr.KeyFormat != "" {
+		data["key_format"] = encErr.KeyFormat
+	}
+	if encErr.MissingIV {
+		data["init_section_missing_iv"] = true
+	}
+	w.sendVariantWebhook(ctx, v, webhook.EventAlertEncryptionUnsupported, data)
+}
+
+// analyzeVariantSegment downloads and analyzes one segment of a variant.
+// It reports whether the segment was analyzed.
+func (w *Worker) analyzeVariantSegment(ctx context.Context, v *variantMonitor, segment *manifest.Segment, isRebaseline bool) (bool, error) {
+	initData, data, err := w.fetchVariantSegment(ctx, v, segment, isRebaseline)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if w.isShutdownRequested() {
+		log.Info("shutdown requested, skip analyzing segment")
+		return false, nil
+	}
+	return w.analyzeMedia(ctx, v, initData, data, segment.Duration, func() error {
+		w.mu.Lock()
+		w.countSegment(v, segment)
+		w.mu.Unlock()
+		return w.advanceSegment(ctx, v, segment, isRebaseline)
+	})
+}
+
+// analyzeVariantPart adds an LL-HLS part to the variant's part window and
+// analyzes the window once it spans partWindowDuration. A part with a
+// different initialization section cannot be appended, so the window
+// gathered so far is analyzed on its own first.
+func (w *Worker) analyzeVariantPart(ctx context.Context, v *variantMonitor, part *manifest.Segment) (bool, error) {
+	initData, data, err := w.fetchVariantSegment(ctx, v, part, false)
+	if err != nil || data == nil {
+		return false, err
+	}
+	// The part's data is held in the window from here on, so move past it
+	// now rather than fetching it again next poll.
+	if err := w.advanceSegment(ctx, v, part, false); err != nil {
+		return false, err
+	}
+
+	key := initSectionKey(part)
+	var ready []*partWindow
+	w.mu.Lock()
+	if v.partWindow != nil && v.partWindow.initKey != key {
+		ready = append(ready, v.partWindow)
+		v.partWindow = nil
+	}
+	if v.partWindow == nil {
+		v.partWindow = &partWindow{initKey: key, init: initData}
+	}
+	window := v.partWindow
+	window.data = append(window.data, data...)
+	window.parts = append(window.parts, part)
+	window.duration += part.Duration
+	if window.duration >= w.partWindowDuration() {
+		ready = append(ready, window)
+		v.partWindow = nil
+	}
+	w.mu.Unlock()
+
+	analyzed := false
+	for _, window := range ready {
+		ok, err := w.analyzeMedia(ctx, v, window.init, window.data, window.duration, func() error {
+			w.mu.Lock()
+			for _, part := range window.parts {
+				w.countSegment(v, part)
+			}
+			w.mu.Unlock()
+			return nil
+		})
+		analyzed = analyzed || ok
+		if err != nil {
+			return analyzed, err
+		}
+	}
+	return analyzed, nil
+}
+
+// partWindowDuration returns how much media to gather from LL-HLS parts
+// before analyzing it: parts are often shorter than the minimum durations
+// of the detectors, which would then never fire.
+func (w *Worker) partWindowDuration() float64 {
+	return max(minPartWindowSec, w.cfg.SilenceMinDuration, w.cfg.BlackMinDuration, w.cfg.FreezeMinDuration)
+}
+
+// countSegment adds segment to totalSegments. LL-HLS parts count once for
+// the segment they belong to. Callers must hold w.mu.
+func (w *Worker) countSegment(v *variantMonitor, segment *manifest.Segment) {
+	if segment.Partial {
+		if v.partsCounted && v.partsCountedSequence == segment.Sequence {
+			return
+		}
+		v.partsCounted = true
+		v.partsCountedSequence = segment.Sequence
+	}
+	w.totalSegments++
+}
+
+// fetchVariantSegment downloads segment and the initialization section it
+// needs. A segment whose encryption, or whose initialization section's
+// encryption, is not supported is moved past and yields nil data.
+func (w *Worker) fetchVariantSegment(ctx context.Context, v *variantMonitor, segment *manifest.Segment, isRebaseline bool) ([]byte, []byte, error) {
+	log.Debug("fetching segment",
+		zap.String("variant", v.selection.String()),
+		zap.Uint64("sequence", segment.Sequence),
+		zap.Float64("duration", segment.Duration),
+	)
+
+	var unsupported *manifest.UnsupportedEncryptionError
+	initData, err := w.fetchInitSegment(ctx, v, segment)
+	if err != nil && !errors.As(err, &unsupported) {
+		return nil, nil, fmt.Errorf("fetch init segment: %w", err)
+	}
+	var data []byte
+	if err == nil {
+		data, err = w.manifestParser.FetchSegment(ctx, segment)
+	}
+	if errors.As(err, &unsupported) {
+		// The segment cannot be analyzed, but it is still a new segment:
+		// move past it so the stream is not reported as suspended.
+		w.processUnsupportedEncryption(ctx, v, segment, unsupported)
+		return nil, nil, w.advanceSegment(ctx, v, segment, isRebaseline)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch segment: %w", err)
+	}
+	w.mu.Lock()
+	v.unsupportedEncryption = ""
+	w.mu.Unlock()
+	return initData, data, nil
+}
+
+// analyzeMedia analyzes duration seconds of a variant's media and feeds
+// the results to the detectors. commit runs once the analysis succeeded,
+// before any alert is processed. It reports whether the media was
+// analyzed.
+func (w *Worker) analyzeMedia(ctx context.Context, v *variantMonitor, initData, data []byte, duration float64, commit func() error) (bool, error) {
+	// Save segment to temp file
+	segmentPath, err := w.analyzer.SaveSegment(w.cfg.MonitorID, initData, data)
+	if err != nil {
+		return false, fmt.Errorf("save segment: %w", err)
+	}
+	w.mu.Lock()
+	v.analysisSegmentPath = segmentPath
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		v.analysisSegmentPath = ""
+		w.mu.Unlock()
+		if err := w.analyzer.CleanupSegment(segmentPath); err != nil {
+			log.Warn("failed to cleanup segment file", zap.Error(err))
+		}
+	}()
+
+	// Analyze segment; allow in-flight ffmpeg work to finish even if shutdown is requested.
+	analysisCtx := context.WithoutCancel(ctx)
+	result, err := w.analyzer.AnalyzeSegment(analysisCtx, segmentPath)
+	if err != nil {
+		return false, fmt.Errorf("analyze segment: %w", err)
+	}
+	if err := commit(); err != nil {
+		return false, err
+	}
+
+	// Process results
+	w.processBlackDetection(ctx, v, result.Black, duration)
+	w.processSilenceDetection(ctx, v, result.Silence, duration)
+	w.processChannelSilence(ctx, v, result.Silence, duration)
+	// A black screen is also a still one; leave it to the blackout alert
+	// instead of raising alert.frozen alongside it. It still ends a freeze
+	// run, so frozen time before and after a blackout is not added up.
+	if result.Black != nil && result.Black.FullyBlack {
+		w.processFreezeDetection(ctx, v, &ffmpeg.FreezeDetectResult{}, duration)
+	} else {
+		w.processFreezeDetection(ctx, v, result.Freeze, duration)
+	}
+	w.processLoudness(ctx, v, result.Loudness, duration)
+	w.processQuality(ctx, v, result.Quality)
+	w.processAVSync(ctx, v, result.AVSync, duration)
+	w.processSlate(ctx, v, result.Still, duration)
+	w.processToneDetection(ctx, v, result.Tone, duration)
+	w.processCaptions(ctx, v, result.Captions, duration)
+
+	return true, nil
+}
+
+// processBlackDetection handles blackout detection results.
+func (w *Worker) processBlackDetection(ctx context.Context, v *variantMonitor, result *ffmpeg.BlackDetectResult, segmentDuration float64) {
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if result.FullyBlack {
+		v.consecutiveBlack += segmentDuration
+		if v.blackoutStart == nil {
+			now := time.Now()
+			v.blackoutStart = &now
+		}
+		if !v.blackoutAlertSent && v.consecutiveBlack >= w.cfg.BlackoutThreshold.Seconds() {
+			w.blackoutEvents++
+			v.blackoutAlertSent = true
+			startTime := *v.blackoutStart
+			duration := v.consecutiveBlack
+			thresholdSec := int(w.cfg.BlackoutThreshold.Seconds())
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertBlackout
+			data = map[string]interface{}{
+				"duration_sec":  duration,
+				"started_at":    startTime.Format(time.RFC3339),
+				"threshold_sec": thresholdSec,
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.blackoutAlertSent && v.blackoutStart != nil {
+			startTime := *v.blackoutStart
+			totalDuration := v.consecutiveBlack
+			v.blackoutAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertBlackoutRecovered
+			data = map[string]interface{}{
+				"total_duration_sec": totalDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.consecutiveBlack = 0
+		v.blackoutStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent && eventType == webhook.EventAlertBlackout {
+		if ev := w.captureEvidence(ctx, v, eventType); ev != nil {
+			data["evidence"] = ev
+		}
+	}
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processSilenceDetection handles silence detection results.
+func (w *Worker) processSilenceDetection(ctx context.Context, v *variantMonitor, result *ffmpeg.SilenceDetectResult, segmentDuration float64) {
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if result.FullySilent {
+		v.consecutiveSilence += segmentDuration
+
+		if v.silenceStart == nil {
+			now := time.Now()
+			v.silenceStart = &now
+		}
+
+		if !v.silenceAlertSent && v.consecutiveSilence >= w.cfg.SilenceThreshold.Seconds() {
+			w.silenceEvents++
+			v.silenceAlertSent = true
+			startTime := *v.silenceStart
+			duration := v.consecutiveSilence
+			thresholdSec := int(w.cfg.SilenceThreshold.Seconds())
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertSilence
+			data = map[string]interface{}{
+				"duration_sec":  duration,
+				"started_at":    startTime.Format(time.RFC3339),
+				"threshold_sec": thresholdSec,
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.silenceAlertSent && v.silenceStart != nil {
+			startTime := *v.silenceStart
+			totalDuration := v.consecutiveSilence
+			v.silenceAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertSilenceRecovered
+			data = map[string]interface{}{
+				"total_duration_sec": totalDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.consecutiveSilence = 0
+		v.silenceStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent && eventType == webhook.EventAlertSilence {
+		if ev := w.captureEvidence(ctx, v, eventType); ev != nil {
+			data["evidence"] = ev
+		}
+	}
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// captureEvidence extracts a keyframe thumbnail (and, if configured, an
+// audio clip) from the segment being processed and stores them in the
+// evidence sink. It returns the artifact URLs for the webhook payload, or
+// nil when capture is disabled or nothing could be stored. Failures are
+// logged and never block the alert.
+func (w *Worker) captureEvidence(ctx context.Context, v *variantMonitor, eventType webhook.EventType) map[string]interface{} {
+	w.mu.Lock()
+	segmentPath := v.analysisSegmentPath
+	w.mu.Unlock()
+	if w.evidenceSink == nil || segmentPath == "" {
+		return nil
+	}
+
+	captureCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), evidenceCaptureTimeout)
+	defer cancel()
+
+	keyPrefix := fmt.Sprintf("%s/%s-%d", w.cfg.MonitorID, eventType, time.Now().UnixMilli())
+	if len(w.variants) > 1 {
+		keyPrefix = fmt.Sprintf("%s-v%d", keyPrefix, v.index)
+	}
+	result := map[string]interface{}{}
+
+	if thumbnail, err := w.analyzer.ExtractThumbnail(captureCtx, segmentPath); err != nil {
+		log.Warn("failed to extract evidence thumbnail", zap.Error(err))
+	} else if url, err := w.evidenceSink.Put(captureCtx, keyPrefix+".jpg", "image/jpeg", thumbnail); err != nil {
+		log.Warn("failed to store evidence thumbnail", zap.Error(err))
+	} else {
+		result["thumbnail_url"] = url
+	}
+
+	if w.cfg.EvidenceAudioClip > 0 {
+		if clip, err := w.analyzer.ExtractAudioClip(captureCtx, segmentPath, w.cfg.EvidenceAudioClip); err != nil {
+			log.Warn("failed to extract evidence audio clip", zap.Error(err))
+		} else if url, err := w.evidenceSink.Put(captureCtx, keyPrefix+".aac", "audio/aac", clip); err != nil {
+			log.Warn("failed to store evidence audio clip", zap.Error(err))
+		} else {
+			result["audio_url"] = url
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// processChannelSilence handles per-channel silence results. A channel is
+// only tracked while the mixdown still carries audio; when the whole mix is
+// silent, processSilenceDetection already covers it and channel state is
+// left as is.
+func (w *Worker) processChannelSilence(ctx context.Context, v *variantMonitor, result *ffmpeg.SilenceDetectResult, segmentDuration float64) {
+	if result == nil || result.FullySilent {
+		return
+	}
+
+	type event struct {
+		eventType webhook.EventType
+		data      map[string]interface{}
+	}
+	var events []event
+
+	w.mu.Lock()
+	channelCount := len(result.Channels)
+	silent := make(map[int]ffmpeg.ChannelSilenceResult)
+	for _, ch := range result.Channels {
+		if ch.FullySilent {
+			silent[ch.Channel] = ch
+		}
+	}
+	if v.channelSilence == nil {
+		v.channelSilence = make(map[int]*channelSilenceState)
+	}
+
+	channels := make([]int, 0, len(silent)+len(v.channelSilence))
+	for ch := range silent {
+		channels = append(channels, ch)
+	}
+	for ch := range v.channelSilence {
+		if _, ok := silent[ch]; !ok {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Ints(channels)
+
+	for _, ch := range channels {
+		state := v.channelSilence[ch]
+		chResult, isSilent := silent[ch]
+		if isSilent {
+			if state == nil {
+				state = &channelSilenceState{start: time.Now()}
+				v.channelSilence[ch] = state
+			}
+			state.duration += segmentDuration
+			if !state.alertSent && state.duration >= w.cfg.SilenceThreshold.Seconds() {
+				w.channelSilenceEvents++
+				state.alertSent = true
+				data := map[string]interface{}{
+					"channel":       ch,
+					"channel_name":  channelName(ch, channelCount),
+					"channel_count": channelCount,
+					"silence_ratio": chResult.SilenceRatio,
+					"duration_sec":  state.duration,
+					"started_at":    state.start.Format(time.RFC3339),
+					"threshold_sec": int(w.cfg.SilenceThreshold.Seconds()),
+				}
+				if segmentInfo := v.segmentInfoPayload(); segmentInfo != nil {
+					data["segment_info"] = segmentInfo
+				}
+				events = append(events, event{webhook.EventAlertChannelSilence, data})
+			}
+			continue
+		}
+
+		if state.alertSent {
+			events = append(events, event{webhook.EventAlertChannelSilenceRecovered, map[string]interface{}{
+				"channel":            ch,
+				"channel_name":       channelName(ch, channelCount),
+				"total_duration_sec": state.duration,
+				"started_at":         state.start.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}})
+		}
+		delete(v.channelSilence, ch)
+	}
+	w.mu.Unlock()
+
+	for _, e := range events {
+		w.sendVariantWebhook(ctx, v, e.eventType, e.data)
+	}
+}
+
+// channelName returns a human-readable name for an audio channel.
+func channelName(channel, channelCount int) string {
+	if channelCount == 2 {
+		if channel == 0 {
+			return "left"
+		}
+		return "right"
+	}
+	return fmt.Sprintf("channel %d", channel)
+}
+
+// processFreezeDetection handles frozen frame detection results.
+func (w *Worker) processFreezeDetection(ctx context.Context, v *variantMonitor, result *ffmpeg.FreezeDetectResult, segmentDuration float64) {
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if result.FullyFrozen {
+		v.consecutiveFreeze += segmentDuration
+		if v.freezeStart == nil {
+			now := time.Now()
+			v.freezeStart = &now
+		}
+		if !v.freezeAlertSent && v.consecutiveFreeze >= w.cfg.FreezeThreshold.Seconds() {
+			w.freezeEvents++
+			v.freezeAlertSent = true
+			startTime := *v.freezeStart
+			duration := v.consecutiveFreeze
+			thresholdSec := int(w.cfg.FreezeThreshold.Seconds())
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertFrozen
+			data = map[string]interface{}{
+				"duration_sec":  duration,
+				"started_at":    startTime.Format(time.RFC3339),
+				"threshold_sec": thresholdSec,
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.freezeAlertSent && v.freezeStart != nil {
+			startTime := *v.freezeStart
+			totalDuration := v.consecutiveFreeze
+			v.freezeAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertFrozenRecovered
+			data = map[string]interface{}{
+				"total_duration_sec": totalDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.consecutiveFreeze = 0
+		v.freezeStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processLoudness handles loudness measurement results. Segments are
+// collected into a rolling window spanning LoudnessThreshold; once the
+// window is full, its combined loudness is compared against the configured
+// LUFS band. Segments without a measurable level (silence, no audio) are
+// left out of the window, since silence is already covered by
+// processSilenceDetection.
+func (w *Worker) processLoudness(ctx context.Context, v *variantMonitor, result *ffmpeg.LoudnessResult, segmentDuration float64) {
+	if result == nil || !result.Measured || segmentDuration <= 0 {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	v.loudnessWindow = append(v.loudnessWindow, loudnessSample{
+		lufs:     result.IntegratedLUFS,
+		truePeak: result.TruePeakDBFS,
+		duration: segmentDuration,
+	})
+	windowSec := w.cfg.LoudnessThreshold.Seconds()
+	// Drop the oldest samples while the remainder still covers the window.
+	for len(v.loudnessWindow) > 1 && loudnessWindowDuration(v.loudnessWindow[1:]) >= windowSec {
+		v.loudnessWindow = v.loudnessWindow[1:]
+	}
+
+	if loudnessWindowDuration(v.loudnessWindow) >= windowSec {
+		lufs, truePeak := loudnessWindowLevel(v.loudnessWindow)
+		outOfRange := lufs < w.cfg.LoudnessMinLUFS || lufs > w.cfg.LoudnessMaxLUFS
+		if outOfRange {
+			if v.loudnessStart == nil {
+				now := time.Now()
+				v.loudnessStart = &now
+			}
+			if !v.loudnessAlertSent {
+				w.loudnessEvents++
+				v.loudnessAlertSent = true
+				direction := "too_quiet"
+				if lufs > w.cfg.LoudnessMaxLUFS {
+					direction = "too_loud"
+				}
+				segmentInfo := v.segmentInfoPayload()
+				sendEvent = true
+				eventType = webhook.EventAlertLoudness
+				data = map[string]interface{}{
+					"direction":     direction,
+					"loudness_lufs": lufs,
+					"min_lufs":      w.cfg.LoudnessMinLUFS,
+					"max_lufs":      w.cfg.LoudnessMaxLUFS,
+					"started_at":    v.loudnessStart.Format(time.RFC3339),
+					"threshold_sec": int(windowSec),
+				}
+				if !math.IsInf(truePeak, -1) {
+					data["true_peak_dbfs"] = truePeak
+				}
+				if segmentInfo != nil {
+					data["segment_info"] = segmentInfo
+				}
+			}
+		} else {
+			if v.loudnessAlertSent && v.loudnessStart != nil {
+				startTime := *v.loudnessStart
+				v.loudnessAlertSent = false
+				sendEvent = true
+				eventType = webhook.EventAlertLoudnessRecovered
+				data = map[string]interface{}{
+					"loudness_lufs":      lufs,
+					"total_duration_sec": time.Since(startTime).Seconds(),
+					"started_at":         startTime.Format(time.RFC3339),
+					"recovered_at":       time.Now().Format(time.RFC3339),
+				}
+			}
+			v.loudnessStart = nil
+		}
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processQuality handles per-segment video quality results. A segment is
+// degraded when its height or bitrate falls below the configured floor;
+// the alert fires once QualityDegradedSegments consecutive segments are
+// degraded, so a single short or low-bitrate segment does not trigger it.
+func (w *Worker) processQuality(ctx context.Context, v *variantMonitor, result *ffmpeg.QualityResult) {
+	if result == nil {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	v.lastQuality = result
+
+	var reasons []string
+	if w.cfg.MinVideoHeight > 0 && result.Height > 0 && result.Height < w.cfg.MinVideoHeight {
+		reasons = append(reasons, "resolution")
+	}
+	if w.cfg.MinBitrateKbps > 0 && result.BitrateKbps > 0 && result.BitrateKbps < float64(w.cfg.MinBitrateKbps) {
+		reasons = append(reasons, "bitrate")
+	}
+
+	if len(reasons) > 0 {
+		v.qualityDegradedCount++
+		if v.qualityStart == nil {
+			now := time.Now()
+			v.qualityStart = &now
+		}
+		required := w.cfg.QualityDegradedSegments
+		if required <= 0 {
+			required = 1
+		}
+		if v.qualityDegradedCount >= required && !v.qualityAlertSent {
+			w.qualityDegradedEvents++
+			v.qualityAlertSent = true
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertQualityDegraded
+			data = map[string]interface{}{
+				"reasons":              reasons,
+				"width":                result.Width,
+				"height":               result.Height,
+				"frame_rate":           result.FrameRate,
+				"bitrate_kbps":         result.BitrateKbps,
+				"min_video_height":     w.cfg.MinVideoHeight,
+				"min_bitrate_kbps":     w.cfg.MinBitrateKbps,
+				"consecutive_segments": v.qualityDegradedCount,
+				"started_at":           v.qualityStart.Format(time.RFC3339),
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.qualityAlertSent && v.qualityStart != nil {
+			startTime := *v.qualityStart
+			v.qualityAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertQualityRecovered
+			data = map[string]interface{}{
+				"width":              result.Width,
+				"height":             result.Height,
+				"frame_rate":         result.FrameRate,
+				"bitrate_kbps":       result.BitrateKbps,
+				"total_duration_sec": time.Since(startTime).Seconds(),
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.qualityDegradedCount = 0
+		v.qualityStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processAVSync tracks the audio/video offset across segments and alerts
+// once it has stayed beyond AVDesyncMaxOffsetMs for AVDesyncThreshold.
+// Segments without both streams are ignored. When AVDesyncMaxOffsetMs is 0
+// the analyzer does not measure the offset, so nothing is recorded.
+func (w *Worker) processAVSync(ctx context.Context, v *variantMonitor, result *ffmpeg.AVSyncResult, segmentDuration float64) {
+	if result == nil || !result.Measured {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	v.lastAVSync = result
+	maxOffset := float64(w.cfg.AVDesyncMaxOffsetMs)
+	if maxOffset <= 0 {
+		w.mu.Unlock()
+		return
+	}
+
+	if math.Abs(result.OffsetMs) > maxOffset {
+		v.avDesyncDuration += segmentDuration
+		if v.avDesyncStart == nil {
+			now := time.Now()
+			v.avDesyncStart = &now
+		}
+		if !v.avDesyncAlertSent && v.avDesyncDuration >= w.cfg.AVDesyncThreshold.Seconds() {
+			w.avDesyncEvents++
+			v.avDesyncAlertSent = true
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertAVDesync
+			data = map[string]interface{}{
+				"offset_ms":       result.OffsetMs,
+				"start_offset_ms": result.StartOffsetMs,
+				"end_offset_ms":   result.EndOffsetMs,
+				"max_offset_ms":   w.cfg.AVDesyncMaxOffsetMs,
+				"duration_sec":    v.avDesyncDuration,
+				"started_at":      v.avDesyncStart.Format(time.RFC3339),
+				"threshold_sec":   int(w.cfg.AVDesyncThreshold.Seconds()),
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.avDesyncAlertSent && v.avDesyncStart != nil {
+			startTime := *v.avDesyncStart
+			v.avDesyncAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertAVDesyncRecovered
+			data = map[string]interface{}{
+				"offset_ms":          result.OffsetMs,
+				"total_duration_sec": v.avDesyncDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.avDesyncDuration = 0
+		v.avDesyncStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// playlistSegments returns every segment listed in the playlist refresh
+// that segments came from, which may be more than the catch-up limit let
+// through, or segments itself when the refresh did not record them (DASH).
+func playlistSegments(segments []*manifest.Segment) []*manifest.Segment {
+	if info := segments[len(segments)-1].Playlist; info != nil && len(info.Segments) > 0 {
+		return info.Segments
+	}
+	return segments
+}
+
+// processPlaylistConformance runs the HLS conformance checks on a playlist
+// refresh and sends one alert.playlist_anomaly per broken rule. A manifest
+// URL change starts a new baseline.
+func (w *Worker) processPlaylistConformance(ctx context.Context, v *variantMonitor, segments []*manifest.Segment, fetchedAt time.Time, isRebaseline bool) {
+	w.mu.Lock()
+	if isRebaseline {
+		v.playlistValidator = manifest.PlaylistValidator{}
+	}
+	violations := v.playlistValidator.Check(segments, fetchedAt)
+	w.playlistAnomalyEvents += len(violations)
+	w.mu.Unlock()
+
+	for _, violation := range violations {
+		log.Warn("playlist anomaly",
+			zap.String("variant", v.selection.String()),
+			zap.String("rule", string(violation.Rule)),
+			zap.String("detail", violation.Detail),
+		)
+		data := map[string]interface{}{
+			"rule":     string(violation.Rule),
+			"detail":   violation.Detail,
+			"sequence": violation.Sequence,
+		}
+		if info := segments[len(segments)-1].Playlist; info != nil {
+			data["target_duration"] = info.TargetDuration
+			data["media_sequence"] = info.MediaSequence
+		}
+		w.sendVariantWebhook(ctx, v, webhook.EventAlertPlaylistAnomaly, data)
+	}
+}
+
+// processLatency measures how far the newest segment lags behind wall-clock
+// time: the time from the end of its media, per ProgramDateTime, to the
+// manifest fetch. It alerts once latency has stayed above LatencyMax for
+// LatencyThreshold, counting elapsed as the media time of the new segments.
+// Segments without a ProgramDateTime are ignored. Detection is disabled
+// when LatencyMax is 0, but the latency is still recorded for status.
+func (w *Worker) processLatency(ctx context.Context, v *variantMonitor, segment *manifest.Segment, fetchedAt time.Time, elapsed float64) {
+	if segment == nil || segment.ProgramDateTime.IsZero() {
+		return
+	}
+	segmentEnd := segment.ProgramDateTime.Add(time.Duration(segment.Duration * float64(time.Second)))
+	latency := fetchedAt.Sub(segmentEnd).Seconds()
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	v.lastLatency = &latency
+	if w.cfg.LatencyMax <= 0 {
+		w.mu.Unlock()
+		return
+	}
+
+	if latency > w.cfg.LatencyMax.Seconds() {
+		v.latencyDuration += elapsed
+		if v.latencyStart == nil {
+			now := time.Now()
+			v.latencyStart = &now
+		}
+		if !v.latencyAlertSent && v.latencyDuration >= w.cfg.LatencyThreshold.Seconds() {
+			w.latencyEvents++
+			v.latencyAlertSent = true
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertHighLatency
+			data = map[string]interface{}{
+				"latency_sec":       latency,
+				"max_latency_sec":   int(w.cfg.LatencyMax.Seconds()),
+				"program_date_time": segment.ProgramDateTime.Format(time.RFC3339Nano),
+				"duration_sec":      v.latencyDuration,
+				"started_at":        v.latencyStart.Format(time.RFC3339),
+				"threshold_sec":     int(w.cfg.LatencyThreshold.Seconds()),
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.latencyAlertSent && v.latencyStart != nil {
+			startTime := *v.latencyStart
+			v.latencyAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertHighLatencyRecovered
+			data = map[string]interface{}{
+				"latency_sec":        latency,
+				"total_duration_sec": v.latencyDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.latencyDuration = 0
+		v.latencyStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processSlate handles still-image detection results. A segment counts as
+// a slate when it matches a registered reference image or, if enabled, shows
+// almost no motion. Segments without a result (detection disabled, no
+// video) are ignored.
+func (w *Worker) processSlate(ctx context.Context, v *variantMonitor, result *ffmpeg.StillImageResult, segmentDuration float64) {
+	if result == nil {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if result.IsSlate() {
+		v.slateDuration += segmentDuration
+		if v.slateStart == nil {
+			now := time.Now()
+			v.slateStart = &now
+		}
+		if result.MatchedReference != "" {
+			v.slateReference = result.MatchedReference
+		}
+		if !v.slateAlertSent && v.slateDuration >= w.cfg.SlateThreshold.Seconds() {
+			w.slateEvents++
+			v.slateAlertSent = true
+			reason := "low_motion"
+			if v.slateReference != "" {
+				reason = "reference"
+			}
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertSlate
+			data = map[string]interface{}{
+				"reason":        reason,
+				"motion":        result.Motion,
+				"duration_sec":  v.slateDuration,
+				"started_at":    v.slateStart.Format(time.RFC3339),
+				"threshold_sec": int(w.cfg.SlateThreshold.Seconds()),
+			}
+			if v.slateReference != "" {
+				data["reference"] = v.slateReference
+				data["similarity"] = result.Similarity
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.slateAlertSent && v.slateStart != nil {
+			startTime := *v.slateStart
+			v.slateAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertSlateRecovered
+			data = map[string]interface{}{
+				"total_duration_sec": v.slateDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+			if v.slateReference != "" {
+				data["reference"] = v.slateReference
+			}
+		}
+		v.slateDuration = 0
+		v.slateStart = nil
+		v.slateReference = ""
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processToneDetection handles test-tone detection results. A segment that
+// is mostly a steady tone at ToneFrequencyHz is treated like a silent one:
+// the alert fires once the tone has lasted SilenceThreshold. Segments
+// without a result (detection disabled, no audio) are ignored.
+func (w *Worker) processToneDetection(ctx context.Context, v *variantMonitor, result *ffmpeg.ToneDetectResult, segmentDuration float64) {
+	if result == nil {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if result.FullyTone {
+		v.consecutiveTone += segmentDuration
+
+		if v.toneStart == nil {
+			now := time.Now()
+			v.toneStart = &now
+		}
+
+		if !v.toneAlertSent && v.consecutiveTone >= w.cfg.SilenceThreshold.Seconds() {
+			w.toneEvents++
+			v.toneAlertSent = true
+			startTime := *v.toneStart
+			duration := v.consecutiveTone
+			thresholdSec := int(w.cfg.SilenceThreshold.Seconds())
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertTone
+			data = map[string]interface{}{
+				"frequency_hz":  result.Frequency,
+				"purity":        result.Purity,
+				"duration_sec":  duration,
+				"started_at":    startTime.Format(time.RFC3339),
+				"threshold_sec": thresholdSec,
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.toneAlertSent && v.toneStart != nil {
+			startTime := *v.toneStart
+			totalDuration := v.consecutiveTone
+			v.toneAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertToneRecovered
+			data = map[string]interface{}{
+				"frequency_hz":       result.Frequency,
+				"total_duration_sec": totalDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.consecutiveTone = 0
+		v.toneStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// processCaptions handles closed-caption presence. When CaptionsRequired is
+// set, an alert fires once video segments have carried no captions for
+// CaptionsThreshold. Segments without video are ignored.
+func (w *Worker) processCaptions(ctx context.Context, v *variantMonitor, result *ffmpeg.CaptionResult, segmentDuration float64) {
+	if !w.cfg.CaptionsRequired || result == nil {
+		return
+	}
+
+	var (
+		sendEvent bool
+		eventType webhook.EventType
+		data      map[string]interface{}
+	)
+
+	w.mu.Lock()
+	if !result.Present {
+		v.captionsMissingDuration += segmentDuration
+		if v.captionsMissingStart == nil {
+			now := time.Now()
+			v.captionsMissingStart = &now
+		}
+		if !v.captionsAlertSent && v.captionsMissingDuration >= w.cfg.CaptionsThreshold.Seconds() {
+			w.captionsEvents++
+			v.captionsAlertSent = true
+			segmentInfo := v.segmentInfoPayload()
+			sendEvent = true
+			eventType = webhook.EventAlertCaptionsMissing
+			data = map[string]interface{}{
+				"duration_sec":  v.captionsMissingDuration,
+				"started_at":    v.captionsMissingStart.Format(time.RFC3339),
+				"threshold_sec": int(w.cfg.CaptionsThreshold.Seconds()),
+			}
+			if segmentInfo != nil {
+				data["segment_info"] = segmentInfo
+			}
+		}
+	} else {
+		if v.captionsAlertSent && v.captionsMissingStart != nil {
+			startTime := *v.captionsMissingStart
+			v.captionsAlertSent = false
+			sendEvent = true
+			eventType = webhook.EventAlertCaptionsRecovered
+			data = map[string]interface{}{
+				"total_duration_sec": v.captionsMissingDuration,
+				"started_at":         startTime.Format(time.RFC3339),
+				"recovered_at":       time.Now().Format(time.RFC3339),
+			}
+		}
+		v.captionsMissingDuration = 0
+		v.captionsMissingStart = nil
+	}
+	w.mu.Unlock()
+
+	if sendEvent {
+		w.sendVariantWebhook(ctx, v, eventType, data)
+	}
+}
+
+// loudnessWindowDuration returns the total duration covered by samples.
+func loudnessWindowDuration(samples []loudnessSample) float64 {
+	var total float64
+	for _, s := range samples {
+		total += s.duration
+	}
+	return total
+}
+
+// loudnessWindowLevel combines per-segment integrated loudness values into
+// a single duration-weighted level (averaged in the power domain, as
+// loudness in LUFS is logarithmic) and returns it with the highest true
+// peak seen in the window.
+func loudnessWindowLevel(samples []loudnessSample) (float64, float64) {
+	var energy, total float64
+	truePeak := math.Inf(-1)
+	for _, s := range samples {
+		energy += s.duration * math.Pow(10, s.lufs/10)
+		total += s.duration
+		if s.truePeak > truePeak {
+			truePeak = s.truePeak
+		}
+	}
+	if total <= 0 || energy <= 0 {
+		return math.Inf(-1), truePeak
+	}
+	return 10 * math.Log10(energy/total), truePeak
+}
+
+// handleSegmentError handles segment fetch/analysis errors.
+func (w *Worker) handleSegmentError(ctx context.Context, err error) bool {
+	w.mu.Lock()
+	if w.state == StateCompleted {
+		w.mu.Unlock()
+		return true
+	}
+
+	if w.segmentErrorStart == nil {
+		now := time.Now()
+		w.segmentErrorStart = &now
+		w.segmentErrorSent = false
+	}
+
+	if time.Since(*w.segmentErrorStart) >= 60*time.Second && !w.segmentErrorSent {
 		w.segmentErrorSent = true
 		w.mu.Unlock()
 
@@ -728,6 +2248,18 @@ func (w *Worker) clearSegmentError() {
 	w.mu.Unlock()
 }
 
+// sendVariantWebhook sends a webhook about one monitored variant, adding
+// which variant it concerns to the payload data.
+func (w *Worker) sendVariantWebhook(ctx context.Context, v *variantMonitor, eventType webhook.EventType, data map[string]interface{}) {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	w.mu.Lock()
+	data["variant"] = v.payload()
+	w.mu.Unlock()
+	w.sendWebhook(ctx, eventType, data)
+}
+
 // sendWebhook sends a webhook notification.
 func (w *Worker) sendWebhook(ctx context.Context, eventType webhook.EventType, data map[string]interface{}) {
 	if data == nil {
@@ -789,13 +2321,13 @@ func (w *Worker) shouldCheckLive() bool {
 	return true
 }
 
-func (w *Worker) segmentInfoPayload() map[string]interface{} {
-	if w.lastSegmentInfo == nil {
+func (v *variantMonitor) segmentInfoPayload() map[string]interface{} {
+	if v.lastSegmentInfo == nil {
 		return nil
 	}
 	return map[string]interface{}{
-		"sequence": w.lastSegmentInfo.Sequence,
-		"duration": w.lastSegmentInfo.Duration,
+		"sequence": v.lastSegmentInfo.Sequence,
+		"duration": v.lastSegmentInfo.Duration,
 	}
 }
 
@@ -806,8 +2338,13 @@ func (w *Worker) checkLiveStatus(ctx context.Context) error {
 	isLive, _, err := w.ytdlpClient.IsStreamLive(ctx, w.cfg.StreamURL)
 	if err != nil {
 		log.Warn("failed to check stream status", zap.Error(err))
+		w.processAuthError(ctx, err)
+		if w.getState() == StateError {
+			return fmt.Errorf("webhook delivery failed")
+		}
 		return nil
 	}
+	w.resetAuthExpired()
 	if !isLive {
 		w.sendWebhook(ctx, webhook.EventStreamEnded, map[string]interface{}{
 			"reason": "stream_no_longer_live",
@@ -820,6 +2357,42 @@ func (w *Worker) checkLiveStatus(ctx context.Context) error {
 	return nil
 }
 
+// processAuthError sends alert.auth_expired when err shows that YouTube
+// turned the configured session cookies away with a members-only, age or
+// bot check, and reports whether it did. The alert is sent once until a
+// later check succeeds and calls resetAuthExpired.
+func (w *Worker) processAuthError(ctx context.Context, err error) bool {
+	var unavailable *ytdlp.UnavailableError
+	if w.cfg.CookiesFile == "" || !errors.As(err, &unavailable) || !unavailable.Reason.NeedsSession() {
+		return false
+	}
+	w.mu.Lock()
+	alreadySent := w.authExpiredSent
+	w.authExpiredSent = true
+	w.mu.Unlock()
+	if alreadySent {
+		return true
+	}
+
+	log.Warn("session cookies rejected",
+		zap.String("reason", string(unavailable.Reason)),
+		zap.String("message", unavailable.Message),
+	)
+	w.sendWebhook(ctx, webhook.EventAlertAuthExpired, map[string]interface{}{
+		"reason":  string(unavailable.Reason),
+		"message": unavailable.Message,
+	})
+	return true
+}
+
+// resetAuthExpired records that the session worked again, so that a later
+// rejection sends alert.auth_expired anew.
+func (w *Worker) resetAuthExpired() {
+	w.mu.Lock()
+	w.authExpiredSent = false
+	w.mu.Unlock()
+}
+
 // reportStatus reports the current status to the gateway.
 func (w *Worker) reportStatus(ctx context.Context, status model.MonitorStatus, stats *StatusUpdate) {
 	if err := w.callbackClient.ReportStatus(ctx, w.cfg.MonitorID, status, stats); err != nil {
@@ -831,12 +2404,39 @@ func (w *Worker) reportStatus(ctx context.Context, status model.MonitorStatus, s
 func (w *Worker) reportStatusUpdate(ctx context.Context) {
 	w.mu.Lock()
 	stats := &StatusUpdate{
-		StreamStatus:   string(w.streamStatus),
-		VideoHealth:    w.getVideoHealth(),
-		AudioHealth:    w.getAudioHealth(),
-		TotalSegments:  w.totalSegments,
-		BlackoutEvents: w.blackoutEvents,
-		SilenceEvents:  w.silenceEvents,
+		StreamStatus:          string(w.streamStatus),
+		VideoHealth:           w.getVideoHealth(),
+		AudioHealth:           w.getAudioHealth(),
+		TotalSegments:         w.totalSegments,
+		SkippedSegments:       w.skippedSegments,
+		BlackoutEvents:        w.blackoutEvents,
+		SilenceEvents:         w.silenceEvents,
+		ChannelSilenceEvents:  w.channelSilenceEvents,
+		QualityDegradedEvents: w.qualityDegradedEvents,
+		FreezeEvents:          w.freezeEvents,
+		LoudnessEvents:        w.loudnessEvents,
+		AVDesyncEvents:        w.avDesyncEvents,
+		SlateEvents:           w.slateEvents,
+		ToneEvents:            w.toneEvents,
+		CaptionsEvents:        w.captionsEvents,
+		LatencyEvents:         w.latencyEvents,
+		PlaylistAnomalyEvents: w.playlistAnomalyEvents,
+	}
+	// Video properties, the A/V offset and latency are reported for the
+	// first monitored variant.
+	if q := w.variants[0].lastQuality; q != nil {
+		stats.VideoWidth = q.Width
+		stats.VideoHeight = q.Height
+		stats.FrameRate = q.FrameRate
+		stats.BitrateKbps = q.BitrateKbps
+	}
+	if s := w.variants[0].lastAVSync; s != nil {
+		offset := s.OffsetMs
+		stats.AVOffsetMs = &offset
+	}
+	if l := w.variants[0].lastLatency; l != nil {
+		latency := *l
+		stats.LatencySec = &latency
 	}
 	w.mu.Unlock()
 
@@ -845,20 +2445,26 @@ func (w *Worker) reportStatusUpdate(ctx context.Context) {
 	}
 }
 
-// getVideoHealth returns the current video health status. Callers must
-// hold w.mu (its only caller, reportStatusUpdate, does).
+// getVideoHealth returns the current video health status, which is a
+// warning when any variant has a video problem. Callers must hold w.mu
+// (its only caller, reportStatusUpdate, does).
 func (w *Worker) getVideoHealth() string {
-	if w.blackoutStart != nil {
-		return string(model.HealthWarning)
+	for _, v := range w.variants {
+		if v.blackoutStart != nil || v.freezeStart != nil || v.qualityAlertSent || v.slateAlertSent || v.captionsAlertSent {
+			return string(model.HealthWarning)
+		}
 	}
 	return string(model.HealthOK)
 }
 
-// getAudioHealth returns the current audio health status. Callers must
-// hold w.mu (its only caller, reportStatusUpdate, does).
+// getAudioHealth returns the current audio health status, which is a
+// warning when any variant has an audio problem. Callers must hold w.mu
+// (its only caller, reportStatusUpdate, does).
 func (w *Worker) getAudioHealth() string {
-	if w.silenceStart != nil {
-		return string(model.HealthWarning)
+	for _, v := range w.variants {
+		if v.silenceStart != nil || v.loudnessStart != nil || len(v.channelSilence) > 0 || v.avDesyncAlertSent || v.toneStart != nil {
+			return string(model.HealthWarning)
+		}
 	}
 	return string(model.HealthOK)
 }
@@ -2,17 +2,23 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/xpadev-net/youtube-stream-tracker/internal/config"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/evidence"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ffmpeg"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/manifest"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/model"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/webhook"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtube"
 	"github.com/xpadev-net/youtube-stream-tracker/internal/ytdlp"
 )
 
@@ -30,6 +36,41 @@ func (s *stubYtDlpClient) GetManifestURL(ctx context.Context, streamURL string)
 	return "https://example.com/manifest.m3u8", nil
 }
 
+func TestFallbackYtDlpClient(t *testing.T) {
+	fallback := &stubYtDlpClient{isLive: true, info: &ytdlp.StreamInfo{LiveStatus: "is_live", IsLive: true}}
+	client := &fallbackYtDlpClient{
+		primary:  &stubYtDlpClient{err: errors.New("player endpoint unavailable")},
+		fallback: fallback,
+	}
+	isLive, info, err := client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v=test")
+	if err != nil || !isLive || info != fallback.info {
+		t.Fatalf("IsStreamLive = %v, %v, %v, want the fallback result", isLive, info, err)
+	}
+
+	primary := &stubYtDlpClient{isLive: false, info: &ytdlp.StreamInfo{LiveStatus: "is_upcoming"}}
+	client.primary = primary
+	isLive, info, err = client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v=test")
+	if err != nil || isLive || info != primary.info {
+		t.Fatalf("IsStreamLive = %v, %v, %v, want the primary result", isLive, info, err)
+	}
+
+	// YouTube's refusal is returned as is, with yt-dlp's classification.
+	client.primary = &stubYtDlpClient{err: &youtube.PlayabilityError{Status: "LOGIN_REQUIRED", Reason: "This video is private"}}
+	_, info, err = client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v=test")
+	var unavailable *ytdlp.UnavailableError
+	if info != nil || !errors.As(err, &unavailable) || unavailable.Reason != ytdlp.ReasonPrivate {
+		t.Fatalf("IsStreamLive = %v, %v, want the private playability error", info, err)
+	}
+
+	// A cancelled lookup is not retried with yt-dlp.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.primary = &stubYtDlpClient{err: ctx.Err()}
+	if _, info, err = client.IsStreamLive(ctx, "https://www.youtube.com/watch?v=test"); info != nil || !errors.Is(err, context.Canceled) {
+		t.Fatalf("IsStreamLive = %v, %v, want context.Canceled", info, err)
+	}
+}
+
 type captureWebhookSender struct {
 	calls []*webhook.Payload
 	urls  []string
@@ -185,6 +226,163 @@ func TestWaitingModeSendsStreamEnded(t *testing.T) {
 	}
 }
 
+func TestWaitingModeSendsStreamUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestWorkerConfig()
+	cfg.CallbackURL = server.URL
+	cfg.WaitingModeInitialInterval = time.Millisecond
+	ytdlpClient := &stubYtDlpClient{
+		err: fmt.Errorf("yt-dlp failed: %w", &ytdlp.UnavailableError{Reason: ytdlp.ReasonPrivate, Message: "Private video"}),
+	}
+	sender := &captureWebhookSender{}
+	worker := NewWorkerWithDeps(cfg, ytdlpClient, nil, nil, sender, NewCallbackClient(server.URL, cfg.InternalAPIKey))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := worker.waitingMode(ctx); err != nil {
+		t.Fatalf("waitingMode returned error: %v", err)
+	}
+	if worker.getState() != StateCompleted {
+		t.Fatalf("state = %v, want %v", worker.getState(), StateCompleted)
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	payload := sender.calls[0]
+	if payload.EventType != webhook.EventStreamUnavailable {
+		t.Fatalf("event_type = %v, want %v", payload.EventType, webhook.EventStreamUnavailable)
+	}
+	if payload.Data["reason"] != "private" || payload.Data["message"] != "Private video" {
+		t.Fatalf("data = %v, want reason private", payload.Data)
+	}
+}
+
+// flakyYtDlpClient fails with errs in turn, then reports the stream live.
+type flakyYtDlpClient struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyYtDlpClient) IsStreamLive(ctx context.Context, streamURL string) (bool, *ytdlp.StreamInfo, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return false, nil, f.errs[f.calls-1]
+	}
+	return true, &ytdlp.StreamInfo{LiveStatus: "is_live", IsLive: true, Title: "Members premiere"}, nil
+}
+
+func (f *flakyYtDlpClient) GetManifestURL(ctx context.Context, streamURL string) (string, error) {
+	return "https://example.com/manifest.m3u8", nil
+}
+
+func TestWaitingModeSendsAuthExpiredOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestWorkerConfig()
+	cfg.CallbackURL = server.URL
+	cfg.WaitingModeInitialInterval = time.Millisecond
+	cfg.CookiesFile = "/var/run/stream-monitor/cookies/cookies.txt"
+	membersOnly := fmt.Errorf("yt-dlp failed: %w", &ytdlp.UnavailableError{Reason: ytdlp.ReasonMembersOnly, Message: "Join this channel"})
+	ytdlpClient := &flakyYtDlpClient{errs: []error{membersOnly, membersOnly}}
+	sender := &captureWebhookSender{}
+	worker := NewWorkerWithDeps(cfg, ytdlpClient, nil, nil, sender, NewCallbackClient(server.URL, cfg.InternalAPIKey))
+
+	if err := worker.waitingMode(context.Background()); err != nil {
+		t.Fatalf("waitingMode returned error: %v", err)
+	}
+	if worker.getState() != StateMonitoring {
+		t.Fatalf("state = %v, want %v once the session works again", worker.getState(), StateMonitoring)
+	}
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected auth_expired and stream.started, got %d webhook calls", len(sender.calls))
+	}
+	if payload := sender.calls[0]; payload.EventType != webhook.EventAlertAuthExpired || payload.Data["reason"] != "members_only" {
+		t.Fatalf("first webhook = %v %v, want auth_expired for members_only", payload.EventType, payload.Data)
+	}
+	if sender.calls[1].EventType != webhook.EventStreamStarted {
+		t.Fatalf("second webhook = %v, want %v", sender.calls[1].EventType, webhook.EventStreamStarted)
+	}
+}
+
+func TestCheckLiveStatusSendsAuthExpiredOnce(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CookiesFile = "/var/run/stream-monitor/cookies/cookies.txt"
+	botCheck := fmt.Errorf("yt-dlp failed: %w", &ytdlp.UnavailableError{Reason: ytdlp.ReasonBotCheck, Message: "Sign in to confirm you're not a bot"})
+	ytdlpClient := &flakyYtDlpClient{errs: []error{botCheck, botCheck}}
+	sender := &captureWebhookSender{}
+	worker := NewWorkerWithDeps(cfg, ytdlpClient, nil, nil, sender, &spyCallbackClient{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		worker.lastLiveCheck = time.Time{}
+		if err := worker.checkLiveStatus(ctx); err != nil {
+			t.Fatalf("checkLiveStatus returned error: %v", err)
+		}
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if payload := sender.calls[0]; payload.EventType != webhook.EventAlertAuthExpired || payload.Data["reason"] != "bot_check" {
+		t.Fatalf("webhook = %v %v, want auth_expired for bot_check", payload.EventType, payload.Data)
+	}
+	if worker.authExpiredSent {
+		t.Fatalf("authExpiredSent still set after a successful check")
+	}
+	if worker.getState() == StateCompleted {
+		t.Fatalf("monitor ended on a rejected session")
+	}
+}
+
+func TestReportStatusSendsZeroMeasurements(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode status request: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	zero := 0.0
+	client := NewCallbackClient(server.URL, "internal-key")
+	update := &StatusUpdate{TotalSegments: 3, AVOffsetMs: &zero, LatencySec: &zero}
+	if err := client.ReportStatus(context.Background(), "mon-test", model.StatusMonitoring, update); err != nil {
+		t.Fatalf("ReportStatus error: %v", err)
+	}
+	stats, ok := body["statistics"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("statistics missing from request: %v", body)
+	}
+	if stats["av_offset_ms"] != 0.0 || stats["latency_sec"] != 0.0 {
+		t.Fatalf("statistics = %v, want av_offset_ms and latency_sec of 0", stats)
+	}
+}
+
+func TestWaitingModeBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 60 * time.Second},
+		{3, 240 * time.Second},
+		{5, maxWaitingModeBackoff},
+		{100, maxWaitingModeBackoff},
+	}
+	for _, tt := range tests {
+		if got := waitingModeBackoff(30*time.Second, tt.failures); got != tt.want {
+			t.Fatalf("waitingModeBackoff(30s, %d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
 func TestWebhookFailureDeletesJob(t *testing.T) {
 	cfg := &config.WorkerConfig{
 		MonitorID:                  "mon-test",
@@ -227,20 +425,24 @@ type configurableManifestParser struct {
 	sequence uint64
 }
 
-func (c *configurableManifestParser) GetLatestSegment(ctx context.Context, manifestURL string) (*manifest.Segment, error) {
-	return &manifest.Segment{
+func (c *configurableManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	return []*manifest.Segment{{
 		URL:       "http://example.com/segment.ts",
 		Duration:  1.0,
 		Sequence:  c.sequence,
 		MediaType: "hls",
-	}, nil
+	}}, nil
+}
+
+func (c *configurableManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	return nil, manifest.ErrNoPartialSegments
 }
 
 func (c *configurableManifestParser) IsEndList(ctx context.Context, manifestURL string) (bool, error) {
 	return false, nil
 }
 
-func (c *configurableManifestParser) FetchSegment(ctx context.Context, segmentURL string) ([]byte, error) {
+func (c *configurableManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
 	return []byte("data"), nil
 }
 
@@ -250,41 +452,49 @@ type sequenceManifestParser struct {
 	callIdx  int
 }
 
-func (s *sequenceManifestParser) GetLatestSegment(ctx context.Context, manifestURL string) (*manifest.Segment, error) {
+func (s *sequenceManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.callIdx >= len(s.segments) {
-		return s.segments[len(s.segments)-1], nil
+		return s.segments[len(s.segments)-1:], nil
 	}
 	seg := s.segments[s.callIdx]
 	s.callIdx++
-	return seg, nil
+	return []*manifest.Segment{seg}, nil
+}
+
+func (s *sequenceManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	return nil, manifest.ErrNoPartialSegments
 }
 
 func (s *sequenceManifestParser) IsEndList(ctx context.Context, manifestURL string) (bool, error) {
 	return false, nil
 }
 
-func (s *sequenceManifestParser) FetchSegment(ctx context.Context, segmentURL string) ([]byte, error) {
+func (s *sequenceManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
 	return []byte("data"), nil
 }
 
 type stubManifestParser struct{}
 
-func (s *stubManifestParser) GetLatestSegment(ctx context.Context, manifestURL string) (*manifest.Segment, error) {
-	return &manifest.Segment{
+func (s *stubManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	return []*manifest.Segment{{
 		URL:       "http://example.com/segment.ts",
 		Duration:  1.0,
 		Sequence:  1,
 		MediaType: "hls",
-	}, nil
+	}}, nil
+}
+
+func (s *stubManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	return nil, manifest.ErrNoPartialSegments
 }
 
 func (s *stubManifestParser) IsEndList(ctx context.Context, manifestURL string) (bool, error) {
 	return false, nil
 }
 
-func (s *stubManifestParser) FetchSegment(ctx context.Context, segmentURL string) ([]byte, error) {
+func (s *stubManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
 	return []byte("data"), nil
 }
 
@@ -298,7 +508,7 @@ func (s *stubAnalyzer) CleanupMonitor(monitorID string) error {
 	return nil
 }
 
-func (s *stubAnalyzer) SaveSegment(monitorID string, data []byte) (string, error) {
+func (s *stubAnalyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
 	return "/tmp/segment.ts", nil
 }
 
@@ -306,10 +516,19 @@ func (s *stubAnalyzer) CleanupSegment(segmentPath string) error {
 	return nil
 }
 
+func (s *stubAnalyzer) ExtractThumbnail(ctx context.Context, segmentPath string) ([]byte, error) {
+	return []byte("jpeg"), nil
+}
+
+func (s *stubAnalyzer) ExtractAudioClip(ctx context.Context, segmentPath string, duration time.Duration) ([]byte, error) {
+	return []byte("aac"), nil
+}
+
 func (s *stubAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
 	return &ffmpeg.AnalysisResult{
 		Black:   &ffmpeg.BlackDetectResult{},
 		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{},
 	}, nil
 }
 
@@ -327,7 +546,7 @@ func (d *delayedAnalyzer) CleanupMonitor(monitorID string) error {
 	return nil
 }
 
-func (d *delayedAnalyzer) SaveSegment(monitorID string, data []byte) (string, error) {
+func (d *delayedAnalyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
 	return "/tmp/segment.ts", nil
 }
 
@@ -335,6 +554,14 @@ func (d *delayedAnalyzer) CleanupSegment(segmentPath string) error {
 	return nil
 }
 
+func (d *delayedAnalyzer) ExtractThumbnail(ctx context.Context, segmentPath string) ([]byte, error) {
+	return []byte("jpeg"), nil
+}
+
+func (d *delayedAnalyzer) ExtractAudioClip(ctx context.Context, segmentPath string, duration time.Duration) ([]byte, error) {
+	return []byte("aac"), nil
+}
+
 func (d *delayedAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
 	time.Sleep(d.delay)
 	d.doneOnce.Do(func() {
@@ -345,6 +572,7 @@ func (d *delayedAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string
 	return &ffmpeg.AnalysisResult{
 		Black:   &ffmpeg.BlackDetectResult{},
 		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{},
 	}, nil
 }
 
@@ -444,6 +672,10 @@ func newTestWorkerForDetection(sender *captureWebhookSender) *Worker {
 		BlackoutThreshold:          30 * time.Second,
 		SilenceThreshold:           30 * time.Second,
 		SilenceDBThreshold:         -50,
+		FreezeThreshold:            30 * time.Second,
+		LoudnessMinLUFS:            -30,
+		LoudnessMaxLUFS:            -8,
+		LoudnessThreshold:          30 * time.Second,
 		DelayThreshold:             1 * time.Second,
 		FFmpegPath:                 "ffmpeg",
 		FFprobePath:                "ffprobe",
@@ -460,8 +692,8 @@ func TestStreamSuspensionAlert(t *testing.T) {
 	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &stubAnalyzer{}, sender, &spyCallbackClient{})
 
 	// Simulate that sequence 5 was already processed 11 seconds ago.
-	w.lastSegmentSequence = 5
-	w.lastSegmentURL = "http://example.com/segment.ts"
+	w.variants[0].lastSegmentSequence = 5
+	w.variants[0].lastSegmentURL = "http://example.com/segment.ts"
 	w.currentManifestURL = "https://example.com/manifest.m3u8"
 	w.lastNewSegmentTime = time.Now().Add(-11 * time.Second)
 
@@ -493,7 +725,7 @@ func TestStreamResumedAlert(t *testing.T) {
 	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &stubAnalyzer{}, sender, &spyCallbackClient{})
 
 	// Simulate suspended state: sequence 5 was processed, suspension alert was sent.
-	w.lastSegmentSequence = 5
+	w.variants[0].lastSegmentSequence = 5
 	w.currentManifestURL = "https://example.com/manifest.m3u8"
 	w.lastNewSegmentTime = time.Now().Add(-20 * time.Second)
 	w.suspendedAlertSent = true
@@ -528,8 +760,8 @@ func TestNoFalsePositiveSuspension(t *testing.T) {
 	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &stubAnalyzer{}, sender, &spyCallbackClient{})
 
 	// Same segment but only 5 seconds have passed — should NOT trigger.
-	w.lastSegmentSequence = 5
-	w.lastSegmentURL = "http://example.com/segment.ts"
+	w.variants[0].lastSegmentSequence = 5
+	w.variants[0].lastSegmentURL = "http://example.com/segment.ts"
 	w.currentManifestURL = "https://example.com/manifest.m3u8"
 	w.lastNewSegmentTime = time.Now().Add(-5 * time.Second)
 
@@ -551,8 +783,8 @@ func TestNoFalseResumeOnManifestURLChange(t *testing.T) {
 	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &stubAnalyzer{}, sender, &spyCallbackClient{})
 
 	// Simulate suspended state: sequence 5 was processed, suspension alert was sent.
-	w.lastSegmentSequence = 5
-	w.lastSegmentURL = "http://old-cdn.example.com/segment.ts"
+	w.variants[0].lastSegmentSequence = 5
+	w.variants[0].lastSegmentURL = "http://old-cdn.example.com/segment.ts"
 	w.currentManifestURL = "https://example.com/manifest.m3u8"
 	w.lastNewSegmentTime = time.Now().Add(-20 * time.Second)
 	w.suspendedAlertSent = true
@@ -577,8 +809,8 @@ func TestNoFalseResumeOnManifestURLChange(t *testing.T) {
 	}
 
 	// Segment tracking should have been updated to the new URL.
-	if w.lastSegmentSequence != 6 {
-		t.Fatalf("lastSegmentSequence = %d, want 6", w.lastSegmentSequence)
+	if w.variants[0].lastSegmentSequence != 6 {
+		t.Fatalf("lastSegmentSequence = %d, want 6", w.variants[0].lastSegmentSequence)
 	}
 }
 
@@ -589,8 +821,8 @@ func TestGenuineResumeAfterManifestURLChange(t *testing.T) {
 	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &stubAnalyzer{}, sender, &spyCallbackClient{})
 
 	// Simulate suspended state after a manifest URL re-baseline already happened.
-	w.lastSegmentSequence = 5
-	w.lastSegmentURL = "http://example.com/segment.ts"
+	w.variants[0].lastSegmentSequence = 5
+	w.variants[0].lastSegmentURL = "http://example.com/segment.ts"
 	w.currentManifestURL = "https://example.com/manifest.m3u8"
 	w.lastNewSegmentTime = time.Now().Add(-20 * time.Second)
 	w.suspendedAlertSent = true
@@ -625,7 +857,7 @@ func TestProcessBlackDetection_AlertAfterThreshold(t *testing.T) {
 	worker.cfg.BlackoutThreshold = 1 * time.Second
 
 	result := &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0}
-	worker.processBlackDetection(context.Background(), result, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 1 {
 		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
@@ -639,7 +871,7 @@ func TestProcessBlackDetection_AlertAfterThreshold(t *testing.T) {
 	if thr, ok := sender.calls[0].Data["threshold_sec"].(int); !ok || thr != 1 {
 		t.Fatalf("threshold_sec = %v, want 1", sender.calls[0].Data["threshold_sec"])
 	}
-	if !worker.blackoutAlertSent {
+	if !worker.variants[0].blackoutAlertSent {
 		t.Fatalf("expected blackoutAlertSent to be true")
 	}
 	if worker.blackoutEvents != 1 {
@@ -653,29 +885,29 @@ func TestProcessBlackDetection_NoAlertBelowThreshold(t *testing.T) {
 	worker.cfg.BlackoutThreshold = 5 * time.Second // segment is 2s, must stay below threshold
 
 	result := &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0}
-	worker.processBlackDetection(context.Background(), result, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 0 {
 		t.Fatalf("expected 0 webhook calls below threshold, got %d", len(sender.calls))
 	}
-	if worker.blackoutAlertSent {
+	if worker.variants[0].blackoutAlertSent {
 		t.Fatalf("expected blackoutAlertSent to be false below threshold")
 	}
-	if worker.consecutiveBlack != 2.0 {
-		t.Fatalf("consecutiveBlack = %f, want 2.0", worker.consecutiveBlack)
+	if worker.variants[0].consecutiveBlack != 2.0 {
+		t.Fatalf("consecutiveBlack = %f, want 2.0", worker.variants[0].consecutiveBlack)
 	}
 
 	// Verify recovery resets state without firing an alert
 	clearResult := &ffmpeg.BlackDetectResult{FullyBlack: false}
-	worker.processBlackDetection(context.Background(), clearResult, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], clearResult, 2.0)
 
 	if len(sender.calls) != 0 {
 		t.Fatalf("expected 0 webhook calls after sub-threshold recovery, got %d", len(sender.calls))
 	}
-	if worker.consecutiveBlack != 0 {
-		t.Fatalf("consecutiveBlack = %f, want 0 after recovery", worker.consecutiveBlack)
+	if worker.variants[0].consecutiveBlack != 0 {
+		t.Fatalf("consecutiveBlack = %f, want 0 after recovery", worker.variants[0].consecutiveBlack)
 	}
-	if worker.blackoutStart != nil {
+	if worker.variants[0].blackoutStart != nil {
 		t.Fatalf("expected blackoutStart to be nil after recovery")
 	}
 }
@@ -686,14 +918,14 @@ func TestProcessBlackDetection_NoDuplicateAlert(t *testing.T) {
 	worker.cfg.BlackoutThreshold = 1 * time.Second
 
 	result := &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0}
-	worker.processBlackDetection(context.Background(), result, 2.0)
-	worker.processBlackDetection(context.Background(), result, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], result, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 1 {
 		t.Fatalf("expected 1 webhook call (no duplicate), got %d", len(sender.calls))
 	}
-	if worker.consecutiveBlack != 4.0 {
-		t.Fatalf("consecutiveBlack = %f, want 4.0", worker.consecutiveBlack)
+	if worker.variants[0].consecutiveBlack != 4.0 {
+		t.Fatalf("consecutiveBlack = %f, want 4.0", worker.variants[0].consecutiveBlack)
 	}
 }
 
@@ -703,7 +935,7 @@ func TestProcessSilenceDetection_AlertAfterThreshold(t *testing.T) {
 	worker.cfg.SilenceThreshold = 1 * time.Second
 
 	result := &ffmpeg.SilenceDetectResult{FullySilent: true, SilenceRatio: 1.0}
-	worker.processSilenceDetection(context.Background(), result, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 1 {
 		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
@@ -717,7 +949,7 @@ func TestProcessSilenceDetection_AlertAfterThreshold(t *testing.T) {
 	if thr, ok := sender.calls[0].Data["threshold_sec"].(int); !ok || thr != 1 {
 		t.Fatalf("threshold_sec = %v, want 1", sender.calls[0].Data["threshold_sec"])
 	}
-	if !worker.silenceAlertSent {
+	if !worker.variants[0].silenceAlertSent {
 		t.Fatalf("expected silenceAlertSent to be true")
 	}
 	if worker.silenceEvents != 1 {
@@ -731,29 +963,29 @@ func TestProcessSilenceDetection_NoAlertBelowThreshold(t *testing.T) {
 	worker.cfg.SilenceThreshold = 5 * time.Second // segment is 2s, must stay below threshold
 
 	result := &ffmpeg.SilenceDetectResult{FullySilent: true, SilenceRatio: 1.0}
-	worker.processSilenceDetection(context.Background(), result, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 0 {
 		t.Fatalf("expected 0 webhook calls below threshold, got %d", len(sender.calls))
 	}
-	if worker.silenceAlertSent {
+	if worker.variants[0].silenceAlertSent {
 		t.Fatalf("expected silenceAlertSent to be false below threshold")
 	}
-	if worker.consecutiveSilence != 2.0 {
-		t.Fatalf("consecutiveSilence = %f, want 2.0", worker.consecutiveSilence)
+	if worker.variants[0].consecutiveSilence != 2.0 {
+		t.Fatalf("consecutiveSilence = %f, want 2.0", worker.variants[0].consecutiveSilence)
 	}
 
 	// Verify recovery resets state without firing an alert
 	clearResult := &ffmpeg.SilenceDetectResult{FullySilent: false}
-	worker.processSilenceDetection(context.Background(), clearResult, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], clearResult, 2.0)
 
 	if len(sender.calls) != 0 {
 		t.Fatalf("expected 0 webhook calls after sub-threshold recovery, got %d", len(sender.calls))
 	}
-	if worker.consecutiveSilence != 0 {
-		t.Fatalf("consecutiveSilence = %f, want 0 after recovery", worker.consecutiveSilence)
+	if worker.variants[0].consecutiveSilence != 0 {
+		t.Fatalf("consecutiveSilence = %f, want 0 after recovery", worker.variants[0].consecutiveSilence)
 	}
-	if worker.silenceStart != nil {
+	if worker.variants[0].silenceStart != nil {
 		t.Fatalf("expected silenceStart to be nil after recovery")
 	}
 }
@@ -764,14 +996,14 @@ func TestProcessSilenceDetection_NoDuplicateAlert(t *testing.T) {
 	worker.cfg.SilenceThreshold = 1 * time.Second
 
 	result := &ffmpeg.SilenceDetectResult{FullySilent: true, SilenceRatio: 1.0}
-	worker.processSilenceDetection(context.Background(), result, 2.0)
-	worker.processSilenceDetection(context.Background(), result, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], result, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], result, 2.0)
 
 	if len(sender.calls) != 1 {
 		t.Fatalf("expected 1 webhook call (no duplicate), got %d", len(sender.calls))
 	}
-	if worker.consecutiveSilence != 4.0 {
-		t.Fatalf("consecutiveSilence = %f, want 4.0", worker.consecutiveSilence)
+	if worker.variants[0].consecutiveSilence != 4.0 {
+		t.Fatalf("consecutiveSilence = %f, want 4.0", worker.variants[0].consecutiveSilence)
 	}
 }
 
@@ -782,11 +1014,11 @@ func TestProcessSilenceDetection_RecoveryUnchanged(t *testing.T) {
 
 	// First: trigger silence alert (threshold=1s, segment=2s)
 	silentResult := &ffmpeg.SilenceDetectResult{FullySilent: true, SilenceRatio: 1.0}
-	worker.processSilenceDetection(context.Background(), silentResult, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], silentResult, 2.0)
 
 	// Then: recovery (non-silent segment)
 	clearResult := &ffmpeg.SilenceDetectResult{FullySilent: false}
-	worker.processSilenceDetection(context.Background(), clearResult, 2.0)
+	worker.processSilenceDetection(context.Background(), worker.variants[0], clearResult, 2.0)
 
 	if len(sender.calls) != 2 {
 		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
@@ -797,13 +1029,13 @@ func TestProcessSilenceDetection_RecoveryUnchanged(t *testing.T) {
 	if sender.calls[1].EventType != webhook.EventAlertSilenceRecovered {
 		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertSilenceRecovered)
 	}
-	if worker.silenceAlertSent {
+	if worker.variants[0].silenceAlertSent {
 		t.Fatalf("expected silenceAlertSent to be false after recovery")
 	}
-	if worker.consecutiveSilence != 0 {
-		t.Fatalf("consecutiveSilence = %f, want 0", worker.consecutiveSilence)
+	if worker.variants[0].consecutiveSilence != 0 {
+		t.Fatalf("consecutiveSilence = %f, want 0", worker.variants[0].consecutiveSilence)
 	}
-	if worker.silenceStart != nil {
+	if worker.variants[0].silenceStart != nil {
 		t.Fatalf("expected silenceStart to be nil after recovery")
 	}
 }
@@ -815,11 +1047,11 @@ func TestProcessBlackDetection_RecoveryUnchanged(t *testing.T) {
 
 	// First: trigger blackout alert (threshold=1s, segment=2s)
 	blackResult := &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0}
-	worker.processBlackDetection(context.Background(), blackResult, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], blackResult, 2.0)
 
 	// Then: recovery (non-black segment)
 	clearResult := &ffmpeg.BlackDetectResult{FullyBlack: false}
-	worker.processBlackDetection(context.Background(), clearResult, 2.0)
+	worker.processBlackDetection(context.Background(), worker.variants[0], clearResult, 2.0)
 
 	if len(sender.calls) != 2 {
 		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
@@ -830,133 +1062,1551 @@ func TestProcessBlackDetection_RecoveryUnchanged(t *testing.T) {
 	if sender.calls[1].EventType != webhook.EventAlertBlackoutRecovered {
 		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertBlackoutRecovered)
 	}
-	if worker.blackoutAlertSent {
+	if worker.variants[0].blackoutAlertSent {
 		t.Fatalf("expected blackoutAlertSent to be false after recovery")
 	}
-	if worker.consecutiveBlack != 0 {
-		t.Fatalf("consecutiveBlack = %f, want 0", worker.consecutiveBlack)
+	if worker.variants[0].consecutiveBlack != 0 {
+		t.Fatalf("consecutiveBlack = %f, want 0", worker.variants[0].consecutiveBlack)
 	}
-	if worker.blackoutStart != nil {
+	if worker.variants[0].blackoutStart != nil {
 		t.Fatalf("expected blackoutStart to be nil after recovery")
 	}
 }
 
-func TestAnalyzeLatestSegment_SameSequenceDifferentURL(t *testing.T) {
-	// When EXT-X-MEDIA-SEQUENCE is absent, successive polls may return
-	// the same Sequence but a different URL (sliding window). The worker
-	// should still process the new segment.
-	parser := &sequenceManifestParser{
-		segments: []*manifest.Segment{
-			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
-			{URL: "http://example.com/segB.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
-		},
-	}
-	analyzer := &stubAnalyzer{}
+func TestProcessFreezeDetection_AlertAfterThreshold(t *testing.T) {
 	sender := &captureWebhookSender{}
-	spyCallback := &spyCallbackClient{}
-	cfg := &config.WorkerConfig{
-		MonitorID:                  "mon-dedup",
-		StreamURL:                  "https://www.youtube.com/watch?v=test",
-		CallbackURL:                "http://example.com",
-		InternalAPIKey:             "internal-key",
-		WebhookURL:                 "http://example.com",
-		WebhookSigningKey:          "signing-key",
-		WaitingModeInitialInterval: 1 * time.Millisecond,
-		WaitingModeDelayedInterval: 1 * time.Millisecond,
-		ManifestFetchTimeout:       1 * time.Second,
-		ManifestRefreshInterval:    1 * time.Second,
-		SegmentFetchTimeout:        1 * time.Second,
-		SegmentMaxBytes:            1024,
-		AnalysisInterval:           1 * time.Second,
-		BlackoutThreshold:          30 * time.Second,
-		SilenceThreshold:           30 * time.Second,
-		SilenceDBThreshold:         -50,
-		DelayThreshold:             1 * time.Second,
-		FFmpegPath:                 "ffmpeg",
-		FFprobePath:                "ffprobe",
-		YtDlpPath:                  "yt-dlp",
-		StreamlinkPath:             "streamlink",
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.FreezeThreshold = 3 * time.Second
+
+	result := &ffmpeg.FreezeDetectResult{FullyFrozen: true, FreezeRatio: 1.0}
+	worker.processFreezeDetection(context.Background(), worker.variants[0], result, 2.0)
+
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls below threshold, got %d", len(sender.calls))
 	}
-	worker := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, spyCallback)
-	worker.currentManifestURL = "http://example.com/playlist.m3u8"
 
-	ctx := context.Background()
+	worker.processFreezeDetection(context.Background(), worker.variants[0], result, 2.0)
 
-	// First call: processes segA (sequence=2)
-	if err := worker.analyzeLatestSegment(ctx); err != nil {
-		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
 	}
-	if worker.totalSegments != 1 {
-		t.Fatalf("totalSegments after first call = %d, want 1", worker.totalSegments)
+	if sender.calls[0].EventType != webhook.EventAlertFrozen {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertFrozen)
+	}
+	if dur, ok := sender.calls[0].Data["duration_sec"].(float64); !ok || dur != 4.0 {
+		t.Fatalf("duration_sec = %v, want 4.0", sender.calls[0].Data["duration_sec"])
+	}
+	if thr, ok := sender.calls[0].Data["threshold_sec"].(int); !ok || thr != 3 {
+		t.Fatalf("threshold_sec = %v, want 3", sender.calls[0].Data["threshold_sec"])
 	}
-	if worker.lastSegmentURL != "http://example.com/segA.ts" {
-		t.Fatalf("lastSegmentURL = %s, want segA.ts", worker.lastSegmentURL)
+	if worker.freezeEvents != 1 {
+		t.Fatalf("freezeEvents = %d, want 1", worker.freezeEvents)
 	}
+}
 
-	// Second call: same sequence=2, different URL → should still process
-	if err := worker.analyzeLatestSegment(ctx); err != nil {
-		t.Fatalf("second analyzeLatestSegment error: %v", err)
+func TestProcessFreezeDetection_Recovery(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.FreezeThreshold = 1 * time.Second
+
+	frozenResult := &ffmpeg.FreezeDetectResult{FullyFrozen: true, FreezeRatio: 1.0}
+	worker.processFreezeDetection(context.Background(), worker.variants[0], frozenResult, 2.0)
+
+	clearResult := &ffmpeg.FreezeDetectResult{FullyFrozen: false}
+	worker.processFreezeDetection(context.Background(), worker.variants[0], clearResult, 2.0)
+
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
 	}
-	if worker.totalSegments != 2 {
-		t.Fatalf("totalSegments after second call = %d, want 2", worker.totalSegments)
+	if sender.calls[0].EventType != webhook.EventAlertFrozen {
+		t.Fatalf("first event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertFrozen)
+	}
+	if sender.calls[1].EventType != webhook.EventAlertFrozenRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertFrozenRecovered)
+	}
+	if worker.variants[0].freezeAlertSent {
+		t.Fatalf("expected freezeAlertSent to be false after recovery")
+	}
+	if worker.variants[0].consecutiveFreeze != 0 {
+		t.Fatalf("consecutiveFreeze = %f, want 0", worker.variants[0].consecutiveFreeze)
 	}
-	if worker.lastSegmentURL != "http://example.com/segB.ts" {
-		t.Fatalf("lastSegmentURL = %s, want segB.ts", worker.lastSegmentURL)
+	if worker.variants[0].freezeStart != nil {
+		t.Fatalf("expected freezeStart to be nil after recovery")
 	}
 }
 
-func TestAnalyzeLatestSegment_SameSequenceSameURL(t *testing.T) {
-	// Same sequence and same URL should be skipped (normal dedup behavior).
-	parser := &sequenceManifestParser{
-		segments: []*manifest.Segment{
-			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
-			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
-		},
+func TestProcessBlackDetection_AttachesEvidence(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.analyzer = &stubAnalyzer{}
+	worker.evidenceSink = evidence.NewLocalSink(t.TempDir(), "https://evidence.example.com")
+	worker.variants[0].analysisSegmentPath = "/tmp/segment.ts"
+	worker.cfg.BlackoutThreshold = 1 * time.Second
+	worker.cfg.EvidenceAudioClip = 5 * time.Second
+
+	worker.processBlackDetection(context.Background(), worker.variants[0], &ffmpeg.BlackDetectResult{FullyBlack: true}, 2.0)
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
 	}
-	analyzer := &stubAnalyzer{}
+	ev, ok := sender.calls[0].Data["evidence"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected evidence in webhook data, got %v", sender.calls[0].Data["evidence"])
+	}
+	thumb, _ := ev["thumbnail_url"].(string)
+	if !strings.HasPrefix(thumb, "https://evidence.example.com/mon-test/alert.blackout-") || !strings.HasSuffix(thumb, ".jpg") {
+		t.Fatalf("thumbnail_url = %q", thumb)
+	}
+	audio, _ := ev["audio_url"].(string)
+	if !strings.HasSuffix(audio, ".aac") {
+		t.Fatalf("audio_url = %q", audio)
+	}
+}
+
+func TestProcessSilenceDetection_NoEvidenceWithoutSink(t *testing.T) {
 	sender := &captureWebhookSender{}
-	spyCallback := &spyCallbackClient{}
-	cfg := &config.WorkerConfig{
-		MonitorID:                  "mon-dedup2",
-		StreamURL:                  "https://www.youtube.com/watch?v=test",
-		CallbackURL:                "http://example.com",
-		InternalAPIKey:             "internal-key",
-		WebhookURL:                 "http://example.com",
-		WebhookSigningKey:          "signing-key",
-		WaitingModeInitialInterval: 1 * time.Millisecond,
-		WaitingModeDelayedInterval: 1 * time.Millisecond,
-		ManifestFetchTimeout:       1 * time.Second,
-		ManifestRefreshInterval:    1 * time.Second,
-		SegmentFetchTimeout:        1 * time.Second,
-		SegmentMaxBytes:            1024,
-		AnalysisInterval:           1 * time.Second,
-		BlackoutThreshold:          30 * time.Second,
-		SilenceThreshold:           30 * time.Second,
-		SilenceDBThreshold:         -50,
-		DelayThreshold:             1 * time.Second,
-		FFmpegPath:                 "ffmpeg",
-		FFprobePath:                "ffprobe",
-		YtDlpPath:                  "yt-dlp",
-		StreamlinkPath:             "streamlink",
+	worker := newTestWorkerForDetection(sender)
+	worker.analyzer = &stubAnalyzer{}
+	worker.variants[0].analysisSegmentPath = "/tmp/segment.ts"
+	worker.cfg.SilenceThreshold = 1 * time.Second
+
+	worker.processSilenceDetection(context.Background(), worker.variants[0], &ffmpeg.SilenceDetectResult{FullySilent: true}, 2.0)
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
 	}
-	worker := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, spyCallback)
-	worker.currentManifestURL = "http://example.com/playlist.m3u8"
+	if _, ok := sender.calls[0].Data["evidence"]; ok {
+		t.Fatalf("expected no evidence when no sink is configured")
+	}
+}
+
+// deadlineSink records the deadline of each Put and stores nothing.
+type deadlineSink struct {
+	deadlines []time.Duration
+}
 
+func (s *deadlineSink) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	deadline, _ := ctx.Deadline()
+	s.deadlines = append(s.deadlines, time.Until(deadline))
+	return "https://evidence.example.com/" + key, nil
+}
+
+func TestCaptureEvidence_OnlyBlackoutAndSilenceWithShortTimeout(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.analyzer = &stubAnalyzer{}
+	sink := &deadlineSink{}
+	worker.evidenceSink = sink
+	worker.variants[0].analysisSegmentPath = "/tmp/segment.ts"
+	worker.cfg.SilenceThreshold = 1 * time.Second
+	worker.cfg.SlateThreshold = 1 * time.Second
 	ctx := context.Background()
 
-	// First call: processes segA
-	if err := worker.analyzeLatestSegment(ctx); err != nil {
-		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	worker.processSlate(ctx, worker.variants[0], &ffmpeg.StillImageResult{Frames: 4, MatchedReference: "smpte-bars", Similarity: 0.97}, 2.0)
+	worker.processToneDetection(ctx, worker.variants[0], &ffmpeg.ToneDetectResult{HasTone: true, FullyTone: true, ToneRatio: 1}, 2.0)
+	worker.processSilenceDetection(ctx, worker.variants[0], &ffmpeg.SilenceDetectResult{FullySilent: true}, 2.0)
+	if len(sender.calls) != 3 {
+		t.Fatalf("expected slate, tone and silence alerts, got %d webhook calls", len(sender.calls))
 	}
-	if worker.totalSegments != 1 {
-		t.Fatalf("totalSegments after first call = %d, want 1", worker.totalSegments)
+
+	var withEvidence []webhook.EventType
+	for _, call := range sender.calls {
+		if _, ok := call.Data["evidence"]; ok {
+			withEvidence = append(withEvidence, call.EventType)
+		}
+	}
+	if fmt.Sprint(withEvidence) != fmt.Sprint([]webhook.EventType{webhook.EventAlertSilence}) {
+		t.Fatalf("events with evidence = %v, want only alert.silence", withEvidence)
 	}
+	if len(sink.deadlines) != 1 || sink.deadlines[0] <= 0 || sink.deadlines[0] > evidenceCaptureTimeout {
+		t.Fatalf("upload deadlines = %v, want one within %v", sink.deadlines, evidenceCaptureTimeout)
+	}
+}
 
-	// Second call: same sequence, same URL → should be skipped
-	if err := worker.analyzeLatestSegment(ctx); err != nil {
-		t.Fatalf("second analyzeLatestSegment error: %v", err)
+func TestProcessChannelSilence_AlertAndRecovery(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+
+	deadRight := &ffmpeg.SilenceDetectResult{
+		Channels: []ffmpeg.ChannelSilenceResult{
+			{Channel: 0},
+			{Channel: 1, SilenceDuration: 2.0, SilenceRatio: 1.0, FullySilent: true},
+		},
 	}
-	if worker.totalSegments != 1 {
+
+	worker.cfg.SilenceThreshold = 2 * time.Second
+
+	worker.processChannelSilence(context.Background(), worker.variants[0], deadRight, 1.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls below threshold, got %d", len(sender.calls))
+	}
+	worker.processChannelSilence(context.Background(), worker.variants[0], deadRight, 1.0)
+	worker.processChannelSilence(context.Background(), worker.variants[0], deadRight, 1.0)
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertChannelSilence {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertChannelSilence)
+	}
+	if worker.channelSilenceEvents != 1 || worker.silenceEvents != 0 {
+		t.Fatalf("channelSilenceEvents = %d, silenceEvents = %d, want 1 and 0", worker.channelSilenceEvents, worker.silenceEvents)
+	}
+	if name := sender.calls[0].Data["channel_name"]; name != "right" {
+		t.Fatalf("channel_name = %v, want right", name)
+	}
+	if ch := sender.calls[0].Data["channel"]; ch != 1 {
+		t.Fatalf("channel = %v, want 1", ch)
+	}
+
+	// Mono segment: no per-channel results, so the channel is considered recovered.
+	worker.processChannelSilence(context.Background(), worker.variants[0], &ffmpeg.SilenceDetectResult{}, 1.0)
+
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertChannelSilenceRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertChannelSilenceRecovered)
+	}
+	if len(worker.variants[0].channelSilence) != 0 {
+		t.Fatalf("expected channel silence state to be cleared, got %d entries", len(worker.variants[0].channelSilence))
+	}
+}
+
+func TestProcessChannelSilence_IgnoredWhenMixSilent(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+
+	allSilent := &ffmpeg.SilenceDetectResult{
+		FullySilent: true,
+		Channels: []ffmpeg.ChannelSilenceResult{
+			{Channel: 0, FullySilent: true},
+			{Channel: 1, FullySilent: true},
+		},
+	}
+	worker.cfg.SilenceThreshold = 2 * time.Second
+
+	for i := 0; i < 5; i++ {
+		worker.processChannelSilence(context.Background(), worker.variants[0], allSilent, 1.0)
+	}
+
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected no channel alerts while the whole mix is silent, got %d", len(sender.calls))
+	}
+}
+
+func TestProcessLoudness_AlertWhenWindowOutOfRange(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.LoudnessThreshold = 4 * time.Second
+
+	loud := &ffmpeg.LoudnessResult{Measured: true, IntegratedLUFS: -4, TruePeakDBFS: -0.5}
+	worker.processLoudness(context.Background(), worker.variants[0], loud, 2.0)
+
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the window is full, got %d", len(sender.calls))
+	}
+
+	worker.processLoudness(context.Background(), worker.variants[0], loud, 2.0)
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertLoudness {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertLoudness)
+	}
+	if dir := sender.calls[0].Data["direction"]; dir != "too_loud" {
+		t.Fatalf("direction = %v, want too_loud", dir)
+	}
+	if peak, ok := sender.calls[0].Data["true_peak_dbfs"].(float64); !ok || peak != -0.5 {
+		t.Fatalf("true_peak_dbfs = %v, want -0.5", sender.calls[0].Data["true_peak_dbfs"])
+	}
+
+	// Still loud: no duplicate alert.
+	worker.processLoudness(context.Background(), worker.variants[0], loud, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call (no duplicate), got %d", len(sender.calls))
+	}
+	if worker.loudnessEvents != 1 {
+		t.Fatalf("loudnessEvents = %d, want 1", worker.loudnessEvents)
+	}
+}
+
+func TestProcessLoudness_RecoveryAndSilenceIgnored(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.LoudnessThreshold = 2 * time.Second
+
+	quiet := &ffmpeg.LoudnessResult{Measured: true, IntegratedLUFS: -45, TruePeakDBFS: -30}
+	worker.processLoudness(context.Background(), worker.variants[0], quiet, 2.0)
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if dir := sender.calls[0].Data["direction"]; dir != "too_quiet" {
+		t.Fatalf("direction = %v, want too_quiet", dir)
+	}
+
+	// Unmeasurable (silent) segments do not affect the window.
+	worker.processLoudness(context.Background(), worker.variants[0], &ffmpeg.LoudnessResult{}, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected silent segment to be ignored, got %d calls", len(sender.calls))
+	}
+
+	normal := &ffmpeg.LoudnessResult{Measured: true, IntegratedLUFS: -14, TruePeakDBFS: -2}
+	worker.processLoudness(context.Background(), worker.variants[0], normal, 2.0)
+
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertLoudnessRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertLoudnessRecovered)
+	}
+	if worker.variants[0].loudnessAlertSent {
+		t.Fatalf("expected loudnessAlertSent to be false after recovery")
+	}
+	if worker.variants[0].loudnessStart != nil {
+		t.Fatalf("expected loudnessStart to be nil after recovery")
+	}
+}
+
+func TestProcessQuality_AlertAfterConsecutiveSegments(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.MinVideoHeight = 720
+	worker.cfg.MinBitrateKbps = 2000
+	worker.cfg.QualityDegradedSegments = 3
+
+	low := &ffmpeg.QualityResult{Width: 640, Height: 360, FrameRate: 30, BitrateKbps: 2500}
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the segment count is reached, got %d", len(sender.calls))
+	}
+
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertQualityDegraded {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertQualityDegraded)
+	}
+	reasons, _ := sender.calls[0].Data["reasons"].([]string)
+	if len(reasons) != 1 || reasons[0] != "resolution" {
+		t.Fatalf("reasons = %v, want [resolution]", sender.calls[0].Data["reasons"])
+	}
+
+	// Still degraded: no duplicate alert.
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call (no duplicate), got %d", len(sender.calls))
+	}
+	if worker.qualityDegradedEvents != 1 {
+		t.Fatalf("qualityDegradedEvents = %d, want 1", worker.qualityDegradedEvents)
+	}
+	if health := worker.getVideoHealth(); health != string(model.HealthWarning) {
+		t.Fatalf("video health = %s, want warning", health)
+	}
+
+	worker.processQuality(context.Background(), worker.variants[0], &ffmpeg.QualityResult{Width: 1280, Height: 720, FrameRate: 30, BitrateKbps: 4500})
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertQualityRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertQualityRecovered)
+	}
+	if worker.variants[0].qualityDegradedCount != 0 || worker.variants[0].qualityStart != nil {
+		t.Fatalf("expected quality state to reset after recovery")
+	}
+}
+
+func TestProcessQuality_IntermittentDropDoesNotAlert(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.MinBitrateKbps = 2000
+	worker.cfg.QualityDegradedSegments = 2
+
+	low := &ffmpeg.QualityResult{Height: 1080, BitrateKbps: 800}
+	ok := &ffmpeg.QualityResult{Height: 1080, BitrateKbps: 3000}
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	worker.processQuality(context.Background(), worker.variants[0], ok)
+	worker.processQuality(context.Background(), worker.variants[0], low)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected no alert for non-consecutive drops, got %d", len(sender.calls))
+	}
+	if worker.variants[0].lastQuality != low {
+		t.Fatalf("expected lastQuality to track the latest segment")
+	}
+}
+
+func TestAnalyzeLatestSegment_SameSequenceDifferentURL(t *testing.T) {
+	// When EXT-X-MEDIA-SEQUENCE is absent, successive polls may return
+	// the same Sequence but a different URL (sliding window). The worker
+	// should still process the new segment.
+	parser := &sequenceManifestParser{
+		segments: []*manifest.Segment{
+			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
+			{URL: "http://example.com/segB.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
+		},
+	}
+	analyzer := &stubAnalyzer{}
+	sender := &captureWebhookSender{}
+	spyCallback := &spyCallbackClient{}
+	cfg := &config.WorkerConfig{
+		MonitorID:                  "mon-dedup",
+		StreamURL:                  "https://www.youtube.com/watch?v=test",
+		CallbackURL:                "http://example.com",
+		InternalAPIKey:             "internal-key",
+		WebhookURL:                 "http://example.com",
+		WebhookSigningKey:          "signing-key",
+		WaitingModeInitialInterval: 1 * time.Millisecond,
+		WaitingModeDelayedInterval: 1 * time.Millisecond,
+		ManifestFetchTimeout:       1 * time.Second,
+		ManifestRefreshInterval:    1 * time.Second,
+		SegmentFetchTimeout:        1 * time.Second,
+		SegmentMaxBytes:            1024,
+		AnalysisInterval:           1 * time.Second,
+		BlackoutThreshold:          30 * time.Second,
+		SilenceThreshold:           30 * time.Second,
+		SilenceDBThreshold:         -50,
+		DelayThreshold:             1 * time.Second,
+		FFmpegPath:                 "ffmpeg",
+		FFprobePath:                "ffprobe",
+		YtDlpPath:                  "yt-dlp",
+		StreamlinkPath:             "streamlink",
+	}
+	worker := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, spyCallback)
+	worker.currentManifestURL = "http://example.com/playlist.m3u8"
+
+	ctx := context.Background()
+
+	// First call: processes segA (sequence=2)
+	if err := worker.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	if worker.totalSegments != 1 {
+		t.Fatalf("totalSegments after first call = %d, want 1", worker.totalSegments)
+	}
+	if worker.variants[0].lastSegmentURL != "http://example.com/segA.ts" {
+		t.Fatalf("lastSegmentURL = %s, want segA.ts", worker.variants[0].lastSegmentURL)
+	}
+
+	// Second call: same sequence=2, different URL → should still process
+	if err := worker.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	if worker.totalSegments != 2 {
+		t.Fatalf("totalSegments after second call = %d, want 2", worker.totalSegments)
+	}
+	if worker.variants[0].lastSegmentURL != "http://example.com/segB.ts" {
+		t.Fatalf("lastSegmentURL = %s, want segB.ts", worker.variants[0].lastSegmentURL)
+	}
+}
+
+func TestAnalyzeLatestSegment_SameSequenceSameURL(t *testing.T) {
+	// Same sequence and same URL should be skipped (normal dedup behavior).
+	parser := &sequenceManifestParser{
+		segments: []*manifest.Segment{
+			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
+			{URL: "http://example.com/segA.ts", Duration: 2.0, Sequence: 2, MediaType: "hls"},
+		},
+	}
+	analyzer := &stubAnalyzer{}
+	sender := &captureWebhookSender{}
+	spyCallback := &spyCallbackClient{}
+	cfg := &config.WorkerConfig{
+		MonitorID:                  "mon-dedup2",
+		StreamURL:                  "https://www.youtube.com/watch?v=test",
+		CallbackURL:                "http://example.com",
+		InternalAPIKey:             "internal-key",
+		WebhookURL:                 "http://example.com",
+		WebhookSigningKey:          "signing-key",
+		WaitingModeInitialInterval: 1 * time.Millisecond,
+		WaitingModeDelayedInterval: 1 * time.Millisecond,
+		ManifestFetchTimeout:       1 * time.Second,
+		ManifestRefreshInterval:    1 * time.Second,
+		SegmentFetchTimeout:        1 * time.Second,
+		SegmentMaxBytes:            1024,
+		AnalysisInterval:           1 * time.Second,
+		BlackoutThreshold:          30 * time.Second,
+		SilenceThreshold:           30 * time.Second,
+		SilenceDBThreshold:         -50,
+		DelayThreshold:             1 * time.Second,
+		FFmpegPath:                 "ffmpeg",
+		FFprobePath:                "ffprobe",
+		YtDlpPath:                  "yt-dlp",
+		StreamlinkPath:             "streamlink",
+	}
+	worker := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, spyCallback)
+	worker.currentManifestURL = "http://example.com/playlist.m3u8"
+
+	ctx := context.Background()
+
+	// First call: processes segA
+	if err := worker.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	if worker.totalSegments != 1 {
+		t.Fatalf("totalSegments after first call = %d, want 1", worker.totalSegments)
+	}
+
+	// Second call: same sequence, same URL → should be skipped
+	if err := worker.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	if worker.totalSegments != 1 {
 		t.Fatalf("totalSegments after second call = %d, want 1 (should be skipped)", worker.totalSegments)
 	}
 }
+
+// variantManifestParser serves one segment per variant selection, with the
+// selection embedded in the segment URL.
+type variantManifestParser struct{}
+
+func (p *variantManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	height := 360
+	if selection.Mode == manifest.VariantHighest {
+		height = 1080
+	}
+	return []*manifest.Segment{{
+		URL:       "http://example.com/" + string(selection.Mode) + "/seg.ts",
+		Duration:  2.0,
+		Sequence:  1,
+		MediaType: "hls",
+		Height:    height,
+	}}, nil
+}
+
+func (p *variantManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	return nil, manifest.ErrNoPartialSegments
+}
+
+func (p *variantManifestParser) IsEndList(ctx context.Context, manifestURL string) (bool, error) {
+	return false, nil
+}
+
+func (p *variantManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
+	return []byte(segment.URL), nil
+}
+
+// blackVariantAnalyzer reports segments whose path contains blackMarker as
+// fully black. SaveSegment uses the segment data (its URL) as the path.
+type blackVariantAnalyzer struct {
+	stubAnalyzer
+	blackMarker string
+}
+
+func (a *blackVariantAnalyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
+	return string(data), nil
+}
+
+func (a *blackVariantAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
+	return &ffmpeg.AnalysisResult{
+		Black:   &ffmpeg.BlackDetectResult{FullyBlack: strings.Contains(segmentPath, a.blackMarker)},
+		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{},
+	}, nil
+}
+
+func TestAnalyzeLatestSegment_MultipleVariantsKeepSeparateState(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.Variants = []manifest.VariantSelection{
+		{Mode: manifest.VariantLowest},
+		{Mode: manifest.VariantHighest},
+		{Mode: manifest.VariantHighest}, // duplicates are monitored once
+	}
+	sender := &captureWebhookSender{}
+	analyzer := &blackVariantAnalyzer{blackMarker: "/highest/"}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, &variantManifestParser{}, analyzer, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+
+	if len(w.variants) != 2 {
+		t.Fatalf("len(variants) = %d, want 2", len(w.variants))
+	}
+	if err := w.analyzeLatestSegment(context.Background()); err != nil {
+		t.Fatalf("analyzeLatestSegment returned error: %v", err)
+	}
+
+	if w.totalSegments != 2 {
+		t.Fatalf("totalSegments = %d, want 2", w.totalSegments)
+	}
+	if w.variants[0].lastSegmentURL != "http://example.com/lowest/seg.ts" {
+		t.Fatalf("variant 0 lastSegmentURL = %s", w.variants[0].lastSegmentURL)
+	}
+	if w.variants[0].blackoutAlertSent || w.variants[0].consecutiveBlack != 0 {
+		t.Fatalf("expected no blackout state on the lowest variant")
+	}
+	if !w.variants[1].blackoutAlertSent {
+		t.Fatalf("expected blackout alert on the highest variant")
+	}
+
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertBlackout {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertBlackout)
+	}
+	variant, ok := sender.calls[0].Data["variant"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected variant in webhook data, got %v", sender.calls[0].Data)
+	}
+	if variant["index"] != 1 || variant["selection"] != "highest" || variant["height"] != 1080 {
+		t.Fatalf("variant = %v, want index 1, selection highest, height 1080", variant)
+	}
+}
+
+// blackFrozenAnalyzer reports every segment as both black and frozen, as
+// freezedetect does for a black screen.
+type blackFrozenAnalyzer struct {
+	stubAnalyzer
+}
+
+func (a *blackFrozenAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
+	return &ffmpeg.AnalysisResult{
+		Black:   &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0},
+		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{HasFreeze: true, FullyFrozen: true, FreezeRatio: 1.0},
+	}, nil
+}
+
+func TestAnalyzeLatestSegment_BlackSegmentIsNotAlsoFrozen(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.FreezeThreshold = 1 * time.Second
+	sender := &captureWebhookSender{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, &variantManifestParser{}, &blackFrozenAnalyzer{}, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+
+	if err := w.analyzeLatestSegment(context.Background()); err != nil {
+		t.Fatalf("analyzeLatestSegment returned error: %v", err)
+	}
+
+	if len(sender.calls) != 1 || sender.calls[0].EventType != webhook.EventAlertBlackout {
+		var events []webhook.EventType
+		for _, call := range sender.calls {
+			events = append(events, call.EventType)
+		}
+		t.Fatalf("events = %v, want only %v", events, webhook.EventAlertBlackout)
+	}
+	if w.freezeEvents != 0 || w.variants[0].consecutiveFreeze != 0 {
+		t.Fatalf("freeze state = %d events, %.1fs, want none for a black segment", w.freezeEvents, w.variants[0].consecutiveFreeze)
+	}
+}
+
+// sequenceAnalyzer returns results in order, one per analyzed segment.
+type sequenceAnalyzer struct {
+	stubAnalyzer
+	results []*ffmpeg.AnalysisResult
+}
+
+func (a *sequenceAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
+	result := a.results[0]
+	a.results = a.results[1:]
+	return result, nil
+}
+
+func TestAnalyzeMedia_BlackoutEndsFreezeRun(t *testing.T) {
+	frozen := &ffmpeg.AnalysisResult{
+		Black:   &ffmpeg.BlackDetectResult{},
+		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{HasFreeze: true, FullyFrozen: true, FreezeRatio: 1.0},
+	}
+	black := &ffmpeg.AnalysisResult{
+		Black:   &ffmpeg.BlackDetectResult{FullyBlack: true, BlackRatio: 1.0},
+		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{HasFreeze: true, FullyFrozen: true, FreezeRatio: 1.0},
+	}
+	motion := &ffmpeg.AnalysisResult{
+		Black:   &ffmpeg.BlackDetectResult{},
+		Silence: &ffmpeg.SilenceDetectResult{},
+		Freeze:  &ffmpeg.FreezeDetectResult{},
+	}
+	commit := func() error { return nil }
+
+	frozenEvents := func(sender *captureWebhookSender) []webhook.EventType {
+		var events []webhook.EventType
+		for _, call := range sender.calls {
+			if call.EventType == webhook.EventAlertFrozen || call.EventType == webhook.EventAlertFrozenRecovered {
+				events = append(events, call.EventType)
+			}
+		}
+		return events
+	}
+
+	// Two seconds frozen on either side of a blackout stay under a 3s
+	// threshold instead of adding up to an alert.
+	cfg := newTestWorkerConfig()
+	cfg.FreezeThreshold = 3 * time.Second
+	sender := &captureWebhookSender{}
+	analyzer := &sequenceAnalyzer{results: []*ffmpeg.AnalysisResult{frozen, black, frozen, motion}}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, &variantManifestParser{}, analyzer, sender, &spyCallbackClient{})
+	for range 4 {
+		if _, err := w.analyzeMedia(context.Background(), w.variants[0], nil, []byte("segment"), 2.0, commit); err != nil {
+			t.Fatalf("analyzeMedia error: %v", err)
+		}
+	}
+	if events := frozenEvents(sender); len(events) != 0 {
+		t.Fatalf("freeze events = %v, want none", events)
+	}
+	if w.freezeEvents != 0 || w.variants[0].freezeStart != nil {
+		t.Fatalf("freeze state = %d events, start %v, want a cleared run", w.freezeEvents, w.variants[0].freezeStart)
+	}
+
+	// A freeze that already alerted recovers when the blackout starts, and
+	// the motion afterwards does not recover it a second time.
+	cfg.FreezeThreshold = 1 * time.Second
+	sender = &captureWebhookSender{}
+	analyzer = &sequenceAnalyzer{results: []*ffmpeg.AnalysisResult{frozen, black, motion}}
+	w = NewWorkerWithDeps(cfg, &stubYtDlpClient{}, &variantManifestParser{}, analyzer, sender, &spyCallbackClient{})
+	for range 3 {
+		if _, err := w.analyzeMedia(context.Background(), w.variants[0], nil, []byte("segment"), 2.0, commit); err != nil {
+			t.Fatalf("analyzeMedia error: %v", err)
+		}
+	}
+	events := frozenEvents(sender)
+	if len(events) != 2 || events[0] != webhook.EventAlertFrozen || events[1] != webhook.EventAlertFrozenRecovered {
+		t.Fatalf("freeze events = %v, want frozen then one recovery", events)
+	}
+	for _, call := range sender.calls {
+		if call.EventType == webhook.EventAlertFrozenRecovered && call.Data["total_duration_sec"] != 2.0 {
+			t.Fatalf("total_duration_sec = %v, want only the frozen segment's 2s", call.Data["total_duration_sec"])
+		}
+	}
+}
+
+// windowManifestParser models a live playlist whose newest segment is head.
+type windowManifestParser struct {
+	head uint64
+}
+
+func (p *windowManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	first := p.head - uint64(limit) + 1
+	if afterSequence+1 > first && afterSequence < p.head {
+		first = afterSequence + 1
+	}
+	var segments []*manifest.Segment
+	for seq := first; seq <= p.head; seq++ {
+		segments = append(segments, &manifest.Segment{
+			URL:       fmt.Sprintf("http://example.com/seg%d.ts", seq),
+			Duration:  2.0,
+			Sequence:  seq,
+			MediaType: "hls",
+		})
+	}
+	return segments, nil
+}
+
+func (p *windowManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	return nil, manifest.ErrNoPartialSegments
+}
+
+func (p *windowManifestParser) IsEndList(ctx context.Context, manifestURL string) (bool, error) {
+	return false, nil
+}
+
+func (p *windowManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
+	return []byte(segment.URL), nil
+}
+
+// recordingAnalyzer records the segments it analyzes, in order.
+type recordingAnalyzer struct {
+	stubAnalyzer
+	analyzed []string
+}
+
+func (a *recordingAnalyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
+	return string(data), nil
+}
+
+func (a *recordingAnalyzer) AnalyzeSegment(ctx context.Context, segmentPath string) (*ffmpeg.AnalysisResult, error) {
+	a.analyzed = append(a.analyzed, segmentPath)
+	return a.stubAnalyzer.AnalyzeSegment(ctx, segmentPath)
+}
+
+func TestAnalyzeLatestSegment_CatchesUpOnMissedSegments(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CatchupMaxSegments = 3
+	parser := &windowManifestParser{head: 10}
+	analyzer := &recordingAnalyzer{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, &captureWebhookSender{}, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	// The first poll only establishes a baseline at the newest segment.
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	// Two new segments fit in the backlog and are analyzed in order.
+	parser.head = 12
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	// Five new segments exceed the backlog of 3; the oldest two are skipped.
+	parser.head = 17
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("third analyzeLatestSegment error: %v", err)
+	}
+
+	var want []string
+	for _, seq := range []int{10, 11, 12, 15, 16, 17} {
+		want = append(want, fmt.Sprintf("http://example.com/seg%d.ts", seq))
+	}
+	if fmt.Sprint(analyzer.analyzed) != fmt.Sprint(want) {
+		t.Fatalf("analyzed = %v, want %v", analyzer.analyzed, want)
+	}
+	if w.totalSegments != 6 {
+		t.Fatalf("totalSegments = %d, want 6", w.totalSegments)
+	}
+	if w.skippedSegments != 2 {
+		t.Fatalf("skippedSegments = %d, want 2", w.skippedSegments)
+	}
+	if w.variants[0].lastSegmentSequence != 17 {
+		t.Fatalf("lastSegmentSequence = %d, want 17", w.variants[0].lastSegmentSequence)
+	}
+}
+
+// cmafManifestParser serves windowManifestParser's segments with an init
+// segment and counts how often each URL is fetched.
+type cmafManifestParser struct {
+	windowManifestParser
+	initURL string
+	fetches map[string]int
+}
+
+func (p *cmafManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	segments, err := p.windowManifestParser.GetVariantSegmentsSince(ctx, manifestURL, selection, afterSequence, limit)
+	for _, segment := range segments {
+		segment.Init = &manifest.Segment{URL: p.initURL}
+	}
+	return segments, err
+}
+
+func (p *cmafManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
+	p.fetches[segment.URL]++
+	return []byte(segment.URL), nil
+}
+
+// initRecordingAnalyzer records the init segment saved with each segment.
+type initRecordingAnalyzer struct {
+	stubAnalyzer
+	inits []string
+}
+
+func (a *initRecordingAnalyzer) SaveSegment(monitorID string, init, data []byte) (string, error) {
+	a.inits = append(a.inits, string(init))
+	return string(data), nil
+}
+
+func TestAnalyzeLatestSegment_CachesInitSegment(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CatchupMaxSegments = 3
+	parser := &cmafManifestParser{
+		windowManifestParser: windowManifestParser{head: 10},
+		initURL:              "http://example.com/init.mp4",
+		fetches:              map[string]int{},
+	}
+	analyzer := &initRecordingAnalyzer{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, &captureWebhookSender{}, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	parser.head = 12
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	// A new EXT-X-MAP is fetched once more.
+	parser.head = 13
+	parser.initURL = "http://example.com/init2.mp4"
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("third analyzeLatestSegment error: %v", err)
+	}
+
+	if parser.fetches["http://example.com/init.mp4"] != 1 || parser.fetches["http://example.com/init2.mp4"] != 1 {
+		t.Fatalf("init fetches = %v, want one per init segment", parser.fetches)
+	}
+	want := []string{
+		"http://example.com/init.mp4",
+		"http://example.com/init.mp4",
+		"http://example.com/init.mp4",
+		"http://example.com/init2.mp4",
+	}
+	if fmt.Sprint(analyzer.inits) != fmt.Sprint(want) {
+		t.Fatalf("saved init segments = %v, want %v", analyzer.inits, want)
+	}
+}
+
+// encryptedManifestParser serves windowManifestParser's segments as if
+// they were SAMPLE-AES encrypted.
+type encryptedManifestParser struct {
+	windowManifestParser
+}
+
+func (p *encryptedManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
+	return nil, &manifest.UnsupportedEncryptionError{Method: manifest.EncryptionSampleAES}
+}
+
+func TestAnalyzeLatestSegment_UnsupportedEncryption(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CatchupMaxSegments = 3
+	parser := &encryptedManifestParser{windowManifestParser{head: 10}}
+	analyzer := &recordingAnalyzer{}
+	sender := &captureWebhookSender{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	parser.head = 12
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+
+	if len(analyzer.analyzed) != 0 {
+		t.Fatalf("expected no segments to be analyzed, got %v", analyzer.analyzed)
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.EventType != webhook.EventAlertEncryptionUnsupported {
+		t.Fatalf("event_type = %v, want %v", call.EventType, webhook.EventAlertEncryptionUnsupported)
+	}
+	if call.Data["method"] != manifest.EncryptionSampleAES || call.Data["sequence"] != uint64(10) {
+		t.Fatalf("method = %v, sequence = %v, want SAMPLE-AES at 10", call.Data["method"], call.Data["sequence"])
+	}
+	if w.variants[0].lastSegmentSequence != 12 {
+		t.Fatalf("lastSegmentSequence = %d, want 12", w.variants[0].lastSegmentSequence)
+	}
+	if w.totalSegments != 0 {
+		t.Fatalf("totalSegments = %d, want 0", w.totalSegments)
+	}
+}
+
+// encryptedInitManifestParser is a cmafManifestParser whose init segment
+// is encrypted without the IV it needs.
+type encryptedInitManifestParser struct {
+	cmafManifestParser
+}
+
+func (p *encryptedInitManifestParser) FetchSegment(ctx context.Context, segment *manifest.Segment) ([]byte, error) {
+	if segment.URL == p.initURL {
+		return nil, &manifest.UnsupportedEncryptionError{Method: manifest.EncryptionAES128, MissingIV: true}
+	}
+	return p.cmafManifestParser.FetchSegment(ctx, segment)
+}
+
+func TestAnalyzeLatestSegment_UnsupportedInitSectionEncryption(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CatchupMaxSegments = 3
+	parser := &encryptedInitManifestParser{cmafManifestParser{
+		windowManifestParser: windowManifestParser{head: 10},
+		initURL:              "http://example.com/init.mp4",
+		fetches:              map[string]int{},
+	}}
+	analyzer := &recordingAnalyzer{}
+	sender := &captureWebhookSender{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	parser.head = 12
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+
+	if len(analyzer.analyzed) != 0 || len(parser.fetches) != 0 {
+		t.Fatalf("expected no segments to be fetched or analyzed, got fetches %v, analyzed %v", parser.fetches, analyzer.analyzed)
+	}
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.EventType != webhook.EventAlertEncryptionUnsupported || call.Data["init_section_missing_iv"] != true {
+		t.Fatalf("event = %v %v, want alert.encryption_unsupported for the init section", call.EventType, call.Data)
+	}
+	if w.variants[0].lastSegmentSequence != 12 {
+		t.Fatalf("lastSegmentSequence = %d, want 12", w.variants[0].lastSegmentSequence)
+	}
+}
+
+// partsManifestParser is an LL-HLS windowManifestParser whose in-progress
+// segment head+1 has parts parts so far, each lasting one second.
+type partsManifestParser struct {
+	windowManifestParser
+	parts     int
+	positions []string
+}
+
+func (p *partsManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	segments, err := p.windowManifestParser.GetVariantSegmentsSince(ctx, manifestURL, selection, afterSequence, limit)
+	for _, segment := range segments {
+		segment.Playlist = &manifest.PlaylistInfo{TargetDuration: 4, PartTargetDuration: 1, CanBlockReload: true}
+	}
+	return segments, err
+}
+
+func (p *partsManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	p.positions = append(p.positions, fmt.Sprintf("%d.%d", afterSequence, afterPart))
+	sequence := p.head + 1
+	var parts []*manifest.Segment
+	for i := 0; i < p.parts; i++ {
+		if sequence == afterSequence && i <= afterPart {
+			continue
+		}
+		parts = append(parts, &manifest.Segment{
+			URL:       fmt.Sprintf("http://example.com/seg%d.%d.m4s", sequence, i),
+			Duration:  1.0,
+			Sequence:  sequence,
+			MediaType: "hls",
+			Playlist:  &manifest.PlaylistInfo{TargetDuration: 4, PartTargetDuration: 1, CanBlockReload: true},
+			Partial:   true,
+			PartIndex: i,
+		})
+	}
+	if len(parts) > limit {
+		parts = parts[len(parts)-limit:]
+	}
+	return parts, nil
+}
+
+func TestAnalyzeLatestSegment_LowLatencyAnalyzesParts(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.LowLatency = true
+	cfg.CatchupMaxSegments = 3
+	// Parts are gathered until they span two seconds.
+	cfg.SilenceMinDuration = 2
+	parser := &partsManifestParser{windowManifestParser: windowManifestParser{head: 10}}
+	analyzer := &recordingAnalyzer{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, analyzer, &captureWebhookSender{}, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	// The baseline comes from the full segments.
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	if got := w.analysisInterval(); got != time.Second {
+		t.Fatalf("analysisInterval = %v, want the 1s part target", got)
+	}
+	parser.parts = 2
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	parser.parts = 3
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("third analyzeLatestSegment error: %v", err)
+	}
+
+	want := []string{
+		"http://example.com/seg10.ts",
+		"http://example.com/seg11.0.m4shttp://example.com/seg11.1.m4s",
+	}
+	if fmt.Sprint(analyzer.analyzed) != fmt.Sprint(want) {
+		t.Fatalf("analyzed = %v, want %v", analyzer.analyzed, want)
+	}
+	if window := w.variants[0].partWindow; window == nil || len(window.parts) != 1 || window.duration != 1 {
+		t.Fatalf("part window = %+v, want part 11.2 pending", window)
+	}
+	// Parts count once for the segment they belong to.
+	if w.totalSegments != 2 {
+		t.Fatalf("totalSegments = %d, want 2", w.totalSegments)
+	}
+	if fmt.Sprint(parser.positions) != fmt.Sprint([]string{"10.-1", "11.1"}) {
+		t.Fatalf("part positions = %v, want [10.-1 11.1]", parser.positions)
+	}
+	if w.variants[0].lastSegmentSequence != 11 || w.variants[0].lastPartIndex != 2 {
+		t.Fatalf("last position = %d.%d, want 11.2", w.variants[0].lastSegmentSequence, w.variants[0].lastPartIndex)
+	}
+}
+
+func TestAnalyzeLatestSegment_LowLatencyChecksConformance(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.LowLatency = true
+	cfg.CatchupMaxSegments = 3
+	cfg.SilenceMinDuration = 2
+	parser := &conformancePartsManifestParser{partsManifestParser: partsManifestParser{windowManifestParser: windowManifestParser{head: 10}}}
+	sender := &captureWebhookSender{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &recordingAnalyzer{}, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	// Segment 11 completes with a discontinuity while parts of 12 arrive.
+	parser.head = 11
+	parser.parts = 2
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	// The same playlist again: no new part, but still checked.
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("third analyzeLatestSegment error: %v", err)
+	}
+
+	var anomalies []interface{}
+	for _, call := range sender.calls {
+		if call.EventType == webhook.EventAlertPlaylistAnomaly {
+			anomalies = append(anomalies, call.Data["sequence"])
+		}
+	}
+	if fmt.Sprint(anomalies) != "[11]" {
+		t.Fatalf("anomaly sequences = %v, want [11]", anomalies)
+	}
+	if parser.checked != 2 {
+		t.Fatalf("part playlists fetched = %d, want 2", parser.checked)
+	}
+	if w.variants[0].lastLatency == nil {
+		t.Fatal("lastLatency not recorded from the parts")
+	}
+}
+
+// conformancePartsManifestParser is a partsManifestParser whose parts
+// carry the full segments of their playlist, the newest one after a
+// discontinuity, and a ProgramDateTime. It returns the latest part on its
+// own when nothing newer is listed.
+type conformancePartsManifestParser struct {
+	partsManifestParser
+	checked int
+}
+
+func (p *conformancePartsManifestParser) GetVariantPartsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, afterPart int, limit int) ([]*manifest.Segment, error) {
+	p.checked++
+	info := &manifest.PlaylistInfo{TargetDuration: 4, MediaSequence: p.head - 2, PartTargetDuration: 1, CanBlockReload: true}
+	for seq := p.head - 2; seq <= p.head; seq++ {
+		info.Segments = append(info.Segments, &manifest.Segment{
+			URL:           fmt.Sprintf("http://example.com/seg%d.ts", seq),
+			Duration:      2.0,
+			Sequence:      seq,
+			MediaType:     "hls",
+			Discontinuity: seq == p.head,
+			Playlist:      info,
+		})
+	}
+	parts, err := p.partsManifestParser.GetVariantPartsSince(ctx, manifestURL, selection, afterSequence, afterPart, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		parts = []*manifest.Segment{{
+			URL:       fmt.Sprintf("http://example.com/seg%d.%d.m4s", p.head+1, p.parts-1),
+			Duration:  1.0,
+			Sequence:  p.head + 1,
+			MediaType: "hls",
+			Partial:   true,
+			PartIndex: p.parts - 1,
+		}}
+	}
+	for _, part := range parts {
+		part.Playlist = info
+		part.ProgramDateTime = time.Now().Add(-10 * time.Second)
+	}
+	return parts, nil
+}
+
+func TestAnalysisInterval_IgnoresPartsWithoutLowLatency(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	parser := &partsManifestParser{windowManifestParser: windowManifestParser{head: 10}}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &recordingAnalyzer{}, &captureWebhookSender{}, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+
+	if err := w.analyzeLatestSegment(context.Background()); err != nil {
+		t.Fatalf("analyzeLatestSegment error: %v", err)
+	}
+	if got := w.analysisInterval(); got != cfg.AnalysisInterval {
+		t.Fatalf("analysisInterval = %v, want %v", got, cfg.AnalysisInterval)
+	}
+	if len(parser.positions) != 0 {
+		t.Fatalf("parts requested without low-latency mode: %v", parser.positions)
+	}
+}
+
+func TestProcessAVSync_AlertWhenDriftPersists(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.AVDesyncMaxOffsetMs = 100
+	worker.cfg.AVDesyncThreshold = 6 * time.Second
+	ctx := context.Background()
+
+	drifted := &ffmpeg.AVSyncResult{Measured: true, StartOffsetMs: 250, EndOffsetMs: 240, OffsetMs: 250}
+	worker.processAVSync(ctx, worker.variants[0], drifted, 2.0)
+	worker.processAVSync(ctx, worker.variants[0], drifted, 2.0)
+	// An unmeasured segment neither advances nor resets the drift.
+	worker.processAVSync(ctx, worker.variants[0], &ffmpeg.AVSyncResult{}, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the threshold, got %d", len(sender.calls))
+	}
+
+	worker.processAVSync(ctx, worker.variants[0], drifted, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertAVDesync {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertAVDesync)
+	}
+	if sender.calls[0].Data["offset_ms"] != 250.0 {
+		t.Fatalf("offset_ms = %v, want 250", sender.calls[0].Data["offset_ms"])
+	}
+	if worker.avDesyncEvents != 1 {
+		t.Fatalf("avDesyncEvents = %d, want 1", worker.avDesyncEvents)
+	}
+	if health := worker.getAudioHealth(); health != string(model.HealthWarning) {
+		t.Fatalf("audio health = %s, want warning", health)
+	}
+
+	worker.processAVSync(ctx, worker.variants[0], &ffmpeg.AVSyncResult{Measured: true, OffsetMs: -20}, 2.0)
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertAVDesyncRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertAVDesyncRecovered)
+	}
+	if worker.variants[0].avDesyncStart != nil || worker.variants[0].avDesyncDuration != 0 {
+		t.Fatalf("expected A/V sync state to reset after recovery")
+	}
+	if worker.variants[0].lastAVSync.OffsetMs != -20 {
+		t.Fatalf("lastAVSync.OffsetMs = %v, want -20", worker.variants[0].lastAVSync.OffsetMs)
+	}
+}
+
+func TestProcessAVSync_DisabledStillRecordsOffset(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.AVDesyncThreshold = time.Second
+
+	worker.processAVSync(context.Background(), worker.variants[0], &ffmpeg.AVSyncResult{Measured: true, OffsetMs: 900}, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected no webhook calls with detection disabled, got %d", len(sender.calls))
+	}
+	if worker.variants[0].lastAVSync == nil || worker.variants[0].lastAVSync.OffsetMs != 900 {
+		t.Fatalf("expected the offset to be recorded, got %+v", worker.variants[0].lastAVSync)
+	}
+}
+
+func TestProcessSlate_AlertWithMatchedReference(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.SlateThreshold = 4 * time.Second
+	ctx := context.Background()
+
+	bars := &ffmpeg.StillImageResult{Frames: 4, MatchedReference: "smpte-bars", Similarity: 0.97}
+	worker.processSlate(ctx, worker.variants[0], bars, 2.0)
+	// Detection disabled or no video: no state change.
+	worker.processSlate(ctx, worker.variants[0], nil, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the threshold, got %d", len(sender.calls))
+	}
+
+	worker.processSlate(ctx, worker.variants[0], bars, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.EventType != webhook.EventAlertSlate {
+		t.Fatalf("event_type = %v, want %v", call.EventType, webhook.EventAlertSlate)
+	}
+	if call.Data["reference"] != "smpte-bars" || call.Data["reason"] != "reference" {
+		t.Fatalf("reference = %v, reason = %v, want smpte-bars and reference", call.Data["reference"], call.Data["reason"])
+	}
+	if health := worker.getVideoHealth(); health != string(model.HealthWarning) {
+		t.Fatalf("video health = %s, want warning", health)
+	}
+
+	worker.processSlate(ctx, worker.variants[0], &ffmpeg.StillImageResult{Frames: 4, Motion: 0.2}, 2.0)
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertSlateRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertSlateRecovered)
+	}
+	if sender.calls[1].Data["reference"] != "smpte-bars" {
+		t.Fatalf("recovered reference = %v, want smpte-bars", sender.calls[1].Data["reference"])
+	}
+	if worker.variants[0].slateStart != nil || worker.variants[0].slateReference != "" {
+		t.Fatalf("expected slate state to reset after recovery")
+	}
+}
+
+func TestProcessSlate_LowMotionWithoutReference(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.SlateThreshold = 2 * time.Second
+
+	worker.processSlate(context.Background(), worker.variants[0], &ffmpeg.StillImageResult{Frames: 4, Motion: 0.002, LowMotion: true}, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].Data["reason"] != "low_motion" {
+		t.Fatalf("reason = %v, want low_motion", sender.calls[0].Data["reason"])
+	}
+	if _, ok := sender.calls[0].Data["reference"]; ok {
+		t.Fatalf("expected no reference for a low-motion slate")
+	}
+}
+
+func TestProcessToneDetection_AlertAndRecovery(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.SilenceThreshold = 4 * time.Second
+	ctx := context.Background()
+
+	tone := &ffmpeg.ToneDetectResult{Frequency: 1000, HasTone: true, FullyTone: true, ToneRatio: 1, Purity: 0.99}
+	worker.processToneDetection(ctx, worker.variants[0], tone, 2.0)
+	// Detection disabled or no audio: no state change.
+	worker.processToneDetection(ctx, worker.variants[0], nil, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the threshold, got %d", len(sender.calls))
+	}
+	if health := worker.getAudioHealth(); health != string(model.HealthWarning) {
+		t.Fatalf("audio health = %s, want warning", health)
+	}
+
+	worker.processToneDetection(ctx, worker.variants[0], tone, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.EventType != webhook.EventAlertTone {
+		t.Fatalf("event_type = %v, want %v", call.EventType, webhook.EventAlertTone)
+	}
+	if call.Data["frequency_hz"] != 1000.0 || call.Data["duration_sec"] != 4.0 {
+		t.Fatalf("frequency_hz = %v, duration_sec = %v, want 1000 and 4", call.Data["frequency_hz"], call.Data["duration_sec"])
+	}
+
+	worker.processToneDetection(ctx, worker.variants[0], &ffmpeg.ToneDetectResult{Frequency: 1000}, 2.0)
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertToneRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertToneRecovered)
+	}
+	if worker.variants[0].toneStart != nil || worker.variants[0].consecutiveTone != 0 {
+		t.Fatalf("expected tone state to reset after recovery")
+	}
+	if health := worker.getAudioHealth(); health != string(model.HealthOK) {
+		t.Fatalf("audio health = %s, want ok", health)
+	}
+}
+
+func TestProcessToneDetection_PartialToneResets(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.SilenceThreshold = 4 * time.Second
+	ctx := context.Background()
+
+	tone := &ffmpeg.ToneDetectResult{Frequency: 1000, HasTone: true, FullyTone: true, ToneRatio: 1}
+	partial := &ffmpeg.ToneDetectResult{Frequency: 1000, HasTone: true, ToneRatio: 0.5}
+	worker.processToneDetection(ctx, worker.variants[0], tone, 2.0)
+	worker.processToneDetection(ctx, worker.variants[0], partial, 2.0)
+	worker.processToneDetection(ctx, worker.variants[0], tone, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected a partial tone segment to reset the run, got %d webhook calls", len(sender.calls))
+	}
+}
+
+func TestProcessCaptions_AlertAndRecovery(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.CaptionsRequired = true
+	worker.cfg.CaptionsThreshold = 4 * time.Second
+	ctx := context.Background()
+
+	missing := &ffmpeg.CaptionResult{Present: false}
+	worker.processCaptions(ctx, worker.variants[0], missing, 2.0)
+	// Audio-only segments say nothing about captions.
+	worker.processCaptions(ctx, worker.variants[0], nil, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the threshold, got %d", len(sender.calls))
+	}
+
+	worker.processCaptions(ctx, worker.variants[0], missing, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertCaptionsMissing {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertCaptionsMissing)
+	}
+	if sender.calls[0].Data["duration_sec"] != 4.0 {
+		t.Fatalf("duration_sec = %v, want 4", sender.calls[0].Data["duration_sec"])
+	}
+	if health := worker.getVideoHealth(); health != string(model.HealthWarning) {
+		t.Fatalf("video health = %s, want warning", health)
+	}
+
+	worker.processCaptions(ctx, worker.variants[0], &ffmpeg.CaptionResult{Present: true}, 2.0)
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertCaptionsRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertCaptionsRecovered)
+	}
+	if worker.variants[0].captionsMissingStart != nil || worker.variants[0].captionsMissingDuration != 0 {
+		t.Fatalf("expected caption state to reset after recovery")
+	}
+}
+
+func TestProcessCaptions_DisabledByDefault(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.CaptionsThreshold = 2 * time.Second
+
+	worker.processCaptions(context.Background(), worker.variants[0], &ffmpeg.CaptionResult{Present: false}, 4.0)
+	if len(sender.calls) != 0 || worker.variants[0].captionsMissingStart != nil {
+		t.Fatalf("expected missing captions to be ignored when captions are not required")
+	}
+}
+
+func TestProcessLatency_AlertWhenLatencyPersists(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.LatencyMax = 20 * time.Second
+	worker.cfg.LatencyThreshold = 4 * time.Second
+	ctx := context.Background()
+
+	fetchedAt := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)
+	// Ends 30s before the fetch.
+	late := &manifest.Segment{Duration: 2, ProgramDateTime: fetchedAt.Add(-32 * time.Second)}
+	worker.processLatency(ctx, worker.variants[0], late, fetchedAt, 2.0)
+	// Segments without timestamps neither advance nor reset the run.
+	worker.processLatency(ctx, worker.variants[0], &manifest.Segment{Duration: 2}, fetchedAt, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected 0 webhook calls before the threshold, got %d", len(sender.calls))
+	}
+
+	worker.processLatency(ctx, worker.variants[0], late, fetchedAt, 2.0)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	if sender.calls[0].EventType != webhook.EventAlertHighLatency {
+		t.Fatalf("event_type = %v, want %v", sender.calls[0].EventType, webhook.EventAlertHighLatency)
+	}
+	if sender.calls[0].Data["latency_sec"] != 30.0 {
+		t.Fatalf("latency_sec = %v, want 30", sender.calls[0].Data["latency_sec"])
+	}
+
+	live := &manifest.Segment{Duration: 2, ProgramDateTime: fetchedAt.Add(-7 * time.Second)}
+	worker.processLatency(ctx, worker.variants[0], live, fetchedAt, 2.0)
+	if len(sender.calls) != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", len(sender.calls))
+	}
+	if sender.calls[1].EventType != webhook.EventAlertHighLatencyRecovered {
+		t.Fatalf("second event_type = %v, want %v", sender.calls[1].EventType, webhook.EventAlertHighLatencyRecovered)
+	}
+	if got := worker.variants[0].lastLatency; got == nil || *got != 5 {
+		t.Fatalf("lastLatency = %v, want 5", got)
+	}
+	if worker.variants[0].latencyStart != nil || worker.variants[0].latencyDuration != 0 {
+		t.Fatalf("expected latency state to reset after recovery")
+	}
+}
+
+func TestProcessLatency_DisabledStillRecordsLatency(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	worker.cfg.LatencyThreshold = time.Second
+
+	fetchedAt := time.Now()
+	segment := &manifest.Segment{Duration: 2, ProgramDateTime: fetchedAt.Add(-62 * time.Second)}
+	worker.processLatency(context.Background(), worker.variants[0], segment, fetchedAt, 2.0)
+	if len(sender.calls) != 0 {
+		t.Fatalf("expected no webhook calls with detection disabled, got %d", len(sender.calls))
+	}
+	if got := worker.variants[0].lastLatency; got == nil || *got != 60 {
+		t.Fatalf("lastLatency = %v, want 60", got)
+	}
+}
+
+// playlistManifestParser is a windowManifestParser whose playlist lists
+// the five segments up to head, with a discontinuity before discontinuity.
+type playlistManifestParser struct {
+	windowManifestParser
+	discontinuity uint64
+}
+
+func (p *playlistManifestParser) GetVariantSegmentsSince(ctx context.Context, manifestURL string, selection manifest.VariantSelection, afterSequence uint64, limit int) ([]*manifest.Segment, error) {
+	info := &manifest.PlaylistInfo{TargetDuration: 2, MediaSequence: p.head - 4}
+	for seq := p.head - 4; seq <= p.head; seq++ {
+		info.Segments = append(info.Segments, &manifest.Segment{
+			URL:           fmt.Sprintf("http://example.com/seg%d.ts", seq),
+			Duration:      2.0,
+			Sequence:      seq,
+			MediaType:     "hls",
+			Discontinuity: seq == p.discontinuity,
+			Playlist:      info,
+		})
+	}
+	segments, err := p.windowManifestParser.GetVariantSegmentsSince(ctx, manifestURL, selection, afterSequence, limit)
+	for i, segment := range segments {
+		segments[i] = info.Segments[segment.Sequence-info.MediaSequence]
+	}
+	return segments, err
+}
+
+func TestAnalyzeLatestSegment_ConformanceChecksWholePlaylist(t *testing.T) {
+	cfg := newTestWorkerConfig()
+	cfg.CatchupMaxSegments = 1
+	parser := &playlistManifestParser{windowManifestParser: windowManifestParser{head: 10}}
+	sender := &captureWebhookSender{}
+	w := NewWorkerWithDeps(cfg, &stubYtDlpClient{}, parser, &recordingAnalyzer{}, sender, &spyCallbackClient{})
+	w.currentManifestURL = "https://example.com/manifest.m3u8"
+	ctx := context.Background()
+
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("first analyzeLatestSegment error: %v", err)
+	}
+	// Three segments arrive but only the newest is analyzed; the
+	// discontinuity before the oldest of them is still reported.
+	parser.head = 13
+	parser.discontinuity = 11
+	if err := w.analyzeLatestSegment(ctx); err != nil {
+		t.Fatalf("second analyzeLatestSegment error: %v", err)
+	}
+	var anomalies []interface{}
+	for _, call := range sender.calls {
+		if call.EventType == webhook.EventAlertPlaylistAnomaly {
+			anomalies = append(anomalies, call.Data["sequence"])
+		}
+	}
+	if fmt.Sprint(anomalies) != "[11]" {
+		t.Fatalf("anomaly sequences = %v, want [11]", anomalies)
+	}
+}
+
+func TestProcessPlaylistConformance_SendsAnomaly(t *testing.T) {
+	sender := &captureWebhookSender{}
+	worker := newTestWorkerForDetection(sender)
+	ctx := context.Background()
+	now := time.Now()
+
+	info := &manifest.PlaylistInfo{TargetDuration: 2, MediaSequence: 50}
+	worker.processPlaylistConformance(ctx, worker.variants[0], []*manifest.Segment{{Sequence: 51, Duration: 2, Playlist: info}}, now, false)
+	next := []*manifest.Segment{{Sequence: 52, Duration: 2, Discontinuity: true, Playlist: info}}
+	worker.processPlaylistConformance(ctx, worker.variants[0], next, now.Add(2*time.Second), false)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(sender.calls))
+	}
+	call := sender.calls[0]
+	if call.EventType != webhook.EventAlertPlaylistAnomaly {
+		t.Fatalf("event_type = %v, want %v", call.EventType, webhook.EventAlertPlaylistAnomaly)
+	}
+	if call.Data["rule"] != string(manifest.RuleUnexpectedDiscontinuity) || call.Data["sequence"] != uint64(52) {
+		t.Fatalf("rule = %v, sequence = %v, want unexpected_discontinuity at 52", call.Data["rule"], call.Data["sequence"])
+	}
+	if worker.playlistAnomalyEvents != 1 {
+		t.Fatalf("playlistAnomalyEvents = %d, want 1", worker.playlistAnomalyEvents)
+	}
+
+	// A manifest URL change starts a new baseline.
+	older := &manifest.PlaylistInfo{TargetDuration: 2, MediaSequence: 1}
+	worker.processPlaylistConformance(ctx, worker.variants[0], []*manifest.Segment{{Sequence: 1, Duration: 2, Playlist: older}}, now.Add(4*time.Second), true)
+	if len(sender.calls) != 1 {
+		t.Fatalf("expected no anomaly after a rebaseline, got %d webhook calls", len(sender.calls))
+	}
+}
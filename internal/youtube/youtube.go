@@ -0,0 +1,278 @@
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xpadev-net/youtube-stream-tracker/internal/youtubeurl"
+	"github.com/xpadev-net/youtube-stream-tracker/internal/ytdlp"
+)
+
+// DefaultBaseURL is the origin the player endpoint is called on.
+const DefaultBaseURL = "https://www.youtube.com"
+
+// DefaultClientVersion is the iOS app version the player endpoint is called
+// as. YouTube stops serving old app versions, so it can be overridden.
+const DefaultClientVersion = "19.45.4"
+
+// The player endpoint is called as the iOS app, which is given an HLS
+// manifest for live streams.
+const (
+	playerClientName = "IOS"
+	// playerUserAgentFormat takes the client version.
+	playerUserAgentFormat = "com.google.ios.youtube/%s (iPhone16,2; U; CPU iOS 18_1_0 like Mac OS X;)"
+)
+
+// maxPlayerResponseBytes bounds the player response read into memory.
+const maxPlayerResponseBytes = 8 * 1024 * 1024
+
+// PlayabilityError is returned when the player endpoint refuses to play the
+// video, e.g. because it is private, removed or needs a login.
+type PlayabilityError struct {
+	Status string
+	Reason string
+}
+
+func (e *PlayabilityError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("video not playable: %s: %s", e.Status, e.Reason)
+	}
+	return fmt.Sprintf("video not playable: %s", e.Status)
+}
+
+// Unwrap returns the *ytdlp.UnavailableError the reason classifies as, so
+// that callers handle the refusal like the same failure from yt-dlp.
+func (e *PlayabilityError) Unwrap() error {
+	if unavailable := ytdlp.ClassifyMessage(e.Reason); unavailable != nil {
+		return unavailable
+	}
+	return nil
+}
+
+// Client reads live stream status and manifest URLs from YouTube's player
+// endpoint. It implements the same methods as ytdlp.Client without running
+// a subprocess.
+type Client struct {
+	baseURL       string
+	clientVersion string
+	httpClient    *http.Client
+}
+
+// NewClient creates a player endpoint client. baseURL defaults to
+// DefaultBaseURL and clientVersion to DefaultClientVersion. Requests go
+// through httpsProxy for https URLs and httpProxy otherwise; like yt-dlp's
+// --proxy, httpProxy also covers https when httpsProxy is empty.
+func NewClient(baseURL, clientVersion, httpProxy, httpsProxy string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if clientVersion == "" {
+		clientVersion = DefaultClientVersion
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if httpProxy != "" || httpsProxy != "" {
+		transport.Proxy = proxyFunc(httpProxy, httpsProxy)
+	}
+	return &Client{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		clientVersion: clientVersion,
+		httpClient:    &http.Client{Timeout: timeout, Transport: transport},
+	}
+}
+
+// proxyFunc returns an http.Transport proxy function for the configured
+// proxies.
+func proxyFunc(httpProxy, httpsProxy string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := httpProxy
+		if req.URL.Scheme == "https" && httpsProxy != "" {
+			proxy = httpsProxy
+		}
+		if proxy == "" {
+			return nil, nil
+		}
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy URL: %w", err)
+		}
+		return proxyURL, nil
+	}
+}
+
+// playerResponse is the subset of the player endpoint response that is used.
+type playerResponse struct {
+	PlayabilityStatus struct {
+		Status            string `json:"status"`
+		Reason            string `json:"reason"`
+		LiveStreamability *struct {
+			LiveStreamabilityRenderer struct {
+				OfflineSlate *struct {
+					LiveStreamOfflineSlateRenderer struct {
+						ScheduledStartTime string `json:"scheduledStartTime"`
+					} `json:"liveStreamOfflineSlateRenderer"`
+				} `json:"offlineSlate"`
+			} `json:"liveStreamabilityRenderer"`
+		} `json:"liveStreamability"`
+	} `json:"playabilityStatus"`
+	VideoDetails struct {
+		VideoID       string `json:"videoId"`
+		Title         string `json:"title"`
+		ChannelID     string `json:"channelId"`
+		LengthSeconds string `json:"lengthSeconds"`
+		ViewCount     string `json:"viewCount"`
+		IsLive        bool   `json:"isLive"`
+		IsUpcoming    bool   `json:"isUpcoming"`
+		IsLiveContent bool   `json:"isLiveContent"`
+	} `json:"videoDetails"`
+	Microformat struct {
+		PlayerMicroformatRenderer struct {
+			LiveBroadcastDetails *liveBroadcastDetails `json:"liveBroadcastDetails"`
+		} `json:"playerMicroformatRenderer"`
+	} `json:"microformat"`
+	StreamingData struct {
+		HLSManifestURL  string `json:"hlsManifestUrl"`
+		DASHManifestURL string `json:"dashManifestUrl"`
+	} `json:"streamingData"`
+}
+
+type liveBroadcastDetails struct {
+	IsLiveNow      bool      `json:"isLiveNow"`
+	StartTimestamp time.Time `json:"startTimestamp"`
+}
+
+// GetStreamInfo retrieves stream information from the player endpoint.
+// LiveStatus takes the same values as yt-dlp's live_status.
+func (c *Client) GetStreamInfo(ctx context.Context, streamURL string) (*ytdlp.StreamInfo, error) {
+	player, err := c.player(ctx, streamURL)
+	if err != nil {
+		return nil, err
+	}
+	return player.streamInfo(), nil
+}
+
+// IsStreamLive checks if the stream is currently live.
+func (c *Client) IsStreamLive(ctx context.Context, streamURL string) (bool, *ytdlp.StreamInfo, error) {
+	info, err := c.GetStreamInfo(ctx, streamURL)
+	if err != nil {
+		return false, nil, err
+	}
+	return info.IsLive, info, nil
+}
+
+// GetManifestURL retrieves the HLS master playlist URL of a live stream,
+// or its DASH manifest URL when no HLS one is offered.
+func (c *Client) GetManifestURL(ctx context.Context, streamURL string) (string, error) {
+	player, err := c.player(ctx, streamURL)
+	if err != nil {
+		return "", err
+	}
+	if player.StreamingData.HLSManifestURL != "" {
+		return player.StreamingData.HLSManifestURL, nil
+	}
+	if player.StreamingData.DASHManifestURL != "" {
+		return player.StreamingData.DASHManifestURL, nil
+	}
+	return "", fmt.Errorf("no manifest URL in player response")
+}
+
+// player calls the player endpoint for the video at streamURL.
+func (c *Client) player(ctx context.Context, streamURL string) (*playerResponse, error) {
+	videoID, err := youtubeurl.VideoID(streamURL)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"videoId": videoID,
+		"context": map[string]interface{}{
+			"client": map[string]string{
+				"clientName":    playerClientName,
+				"clientVersion": c.clientVersion,
+				"hl":            "en",
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal player request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/youtubei/v1/player?prettyPrint=false", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf(playerUserAgentFormat, c.clientVersion))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call player endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("player endpoint returned status %d", resp.StatusCode)
+	}
+
+	var player playerResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPlayerResponseBytes)).Decode(&player); err != nil {
+		return nil, fmt.Errorf("decode player response: %w", err)
+	}
+
+	switch player.PlayabilityStatus.Status {
+	case "OK", "LIVE_STREAM_OFFLINE":
+		// LIVE_STREAM_OFFLINE is an upcoming stream that has not started.
+	default:
+		return nil, &PlayabilityError{Status: player.PlayabilityStatus.Status, Reason: player.PlayabilityStatus.Reason}
+	}
+	return &player, nil
+}
+
+// streamInfo converts the player response to yt-dlp's stream information.
+func (p *playerResponse) streamInfo() *ytdlp.StreamInfo {
+	details := p.VideoDetails
+	broadcast := p.Microformat.PlayerMicroformatRenderer.LiveBroadcastDetails
+
+	info := &ytdlp.StreamInfo{
+		Title:       details.Title,
+		ChannelID:   details.ChannelID,
+		ManifestURL: p.StreamingData.HLSManifestURL,
+	}
+	if views, err := strconv.Atoi(details.ViewCount); err == nil {
+		info.ViewCount = &views
+	}
+
+	switch {
+	case details.IsLive || (broadcast != nil && broadcast.IsLiveNow):
+		info.LiveStatus = "is_live"
+	case details.IsUpcoming || p.PlayabilityStatus.Status == "LIVE_STREAM_OFFLINE":
+		info.LiveStatus = "is_upcoming"
+	case details.IsLiveContent || broadcast != nil:
+		info.LiveStatus = "was_live"
+	default:
+		info.LiveStatus = "not_live"
+	}
+	info.IsLive = info.LiveStatus == "is_live"
+
+	if info.LiveStatus != "is_live" {
+		if seconds, err := strconv.ParseFloat(details.LengthSeconds, 64); err == nil && seconds > 0 {
+			info.Duration = &seconds
+		}
+	}
+
+	if broadcast != nil && !broadcast.StartTimestamp.IsZero() {
+		info.ReleaseTime = &ytdlp.UnixTime{Time: broadcast.StartTimestamp.UTC()}
+	} else if slate := p.PlayabilityStatus.LiveStreamability; slate != nil && slate.LiveStreamabilityRenderer.OfflineSlate != nil {
+		scheduled := slate.LiveStreamabilityRenderer.OfflineSlate.LiveStreamOfflineSlateRenderer.ScheduledStartTime
+		if ts, err := strconv.ParseInt(scheduled, 10, 64); err == nil {
+			info.ReleaseTime = &ytdlp.UnixTime{Time: time.Unix(ts, 0).UTC()}
+		}
+	}
+	return info
+}
@@ -0,0 +1,178 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xpadev-net/youtube-stream-tracker/internal/ytdlp"
+)
+
+// fakePlayerServer serves responses keyed by video ID from a local player
+// endpoint.
+func fakePlayerServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/youtubei/v1/player" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			VideoID string `json:"videoId"`
+			Context struct {
+				Client struct {
+					ClientName string `json:"clientName"`
+				} `json:"client"`
+			} `json:"context"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Context.Client.ClientName == "" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		response, ok := responses[req.VideoID]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientLiveStream(t *testing.T) {
+	server := fakePlayerServer(t, map[string]string{
+		"live0000001": `{
+			"playabilityStatus": {"status": "OK"},
+			"videoDetails": {"videoId": "live0000001", "title": "Live now", "channelId": "UC123", "isLive": true, "isLiveContent": true, "viewCount": "42"},
+			"microformat": {"playerMicroformatRenderer": {"liveBroadcastDetails": {"isLiveNow": true, "startTimestamp": "2026-10-16T09:00:00+00:00"}}},
+			"streamingData": {"hlsManifestUrl": "https://manifest.googlevideo.com/api/manifest/hls_variant/id/live.m3u8"}
+		}`,
+	})
+	client := NewClient(server.URL, "", "", "", 5*time.Second)
+	ctx := context.Background()
+
+	isLive, info, err := client.IsStreamLive(ctx, "https://www.youtube.com/watch?v=live0000001")
+	if err != nil {
+		t.Fatalf("IsStreamLive: %v", err)
+	}
+	if !isLive || info.LiveStatus != "is_live" {
+		t.Fatalf("isLive = %v, live_status = %q, want live", isLive, info.LiveStatus)
+	}
+	if info.Title != "Live now" || info.ChannelID != "UC123" || info.ViewCount == nil || *info.ViewCount != 42 {
+		t.Fatalf("unexpected stream info: %+v", info)
+	}
+	if info.ReleaseTime == nil || !info.ReleaseTime.Equal(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("release time = %v, want broadcast start", info.ReleaseTime)
+	}
+
+	manifestURL, err := client.GetManifestURL(ctx, "https://youtu.be/live0000001")
+	if err != nil {
+		t.Fatalf("GetManifestURL: %v", err)
+	}
+	if manifestURL != "https://manifest.googlevideo.com/api/manifest/hls_variant/id/live.m3u8" {
+		t.Fatalf("manifest URL = %q", manifestURL)
+	}
+}
+
+func TestClientLiveStatus(t *testing.T) {
+	server := fakePlayerServer(t, map[string]string{
+		"upcoming001": `{
+			"playabilityStatus": {"status": "LIVE_STREAM_OFFLINE", "reason": "This live event will begin in a few moments.",
+				"liveStreamability": {"liveStreamabilityRenderer": {"offlineSlate": {"liveStreamOfflineSlateRenderer": {"scheduledStartTime": "1792141200"}}}}},
+			"videoDetails": {"videoId": "upcoming001", "isUpcoming": true, "isLiveContent": true}
+		}`,
+		"archived001": `{
+			"playabilityStatus": {"status": "OK"},
+			"videoDetails": {"videoId": "archived001", "isLiveContent": true, "lengthSeconds": "3600"},
+			"microformat": {"playerMicroformatRenderer": {"liveBroadcastDetails": {"isLiveNow": false, "startTimestamp": "2026-10-15T09:00:00+00:00", "endTimestamp": "2026-10-15T10:00:00+00:00"}}}
+		}`,
+		"uploaded001": `{
+			"playabilityStatus": {"status": "OK"},
+			"videoDetails": {"videoId": "uploaded001", "lengthSeconds": "120"}
+		}`,
+	})
+	client := NewClient(server.URL, "", "", "", 5*time.Second)
+
+	tests := []struct {
+		videoID string
+		want    string
+	}{
+		{"upcoming001", "is_upcoming"},
+		{"archived001", "was_live"},
+		{"uploaded001", "not_live"},
+	}
+	for _, tt := range tests {
+		isLive, info, err := client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v="+tt.videoID)
+		if err != nil {
+			t.Fatalf("%s: IsStreamLive: %v", tt.videoID, err)
+		}
+		if isLive || info.LiveStatus != tt.want {
+			t.Fatalf("%s: isLive = %v, live_status = %q, want %q", tt.videoID, isLive, info.LiveStatus, tt.want)
+		}
+	}
+
+	_, info, _ := client.IsStreamLive(context.Background(), "https://www.youtube.com/live/upcoming001")
+	if info.ReleaseTime == nil || info.ReleaseTime.Unix() != 1792141200 {
+		t.Fatalf("release time = %v, want scheduled start", info.ReleaseTime)
+	}
+}
+
+func TestClientUnplayable(t *testing.T) {
+	server := fakePlayerServer(t, map[string]string{
+		"private0001": `{"playabilityStatus": {"status": "LOGIN_REQUIRED", "reason": "This video is private"}}`,
+	})
+	client := NewClient(server.URL, "", "", "", 5*time.Second)
+
+	_, _, err := client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v=private0001")
+	var playability *PlayabilityError
+	if !errors.As(err, &playability) {
+		t.Fatalf("expected PlayabilityError, got %v", err)
+	}
+	if playability.Status != "LOGIN_REQUIRED" || playability.Reason != "This video is private" {
+		t.Fatalf("unexpected error: %+v", playability)
+	}
+	var unavailable *ytdlp.UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.Reason != ytdlp.ReasonPrivate {
+		t.Fatalf("expected the private reason to classify as %s, got %v", ytdlp.ReasonPrivate, err)
+	}
+
+	if _, err := client.GetManifestURL(context.Background(), "https://www.youtube.com/watch?v=missing0001"); err == nil {
+		t.Fatal("expected an error for a failed player request")
+	}
+}
+
+func TestClientUsesProxyAndClientVersion(t *testing.T) {
+	var host, userAgent, clientVersion string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Context struct {
+				Client struct {
+					ClientVersion string `json:"clientVersion"`
+				} `json:"client"`
+			} `json:"context"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		host, userAgent, clientVersion = r.Host, r.UserAgent(), req.Context.Client.ClientVersion
+		_, _ = w.Write([]byte(`{"playabilityStatus": {"status": "OK"}, "videoDetails": {"isLive": true}}`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	// The base URL is never dialed directly; the proxy answers for it.
+	client := NewClient("http://player.invalid", "20.10.3", proxy.URL, "", 5*time.Second)
+	if _, _, err := client.IsStreamLive(context.Background(), "https://www.youtube.com/watch?v=live0000001"); err != nil {
+		t.Fatalf("IsStreamLive: %v", err)
+	}
+	if host != "player.invalid" {
+		t.Fatalf("proxied host = %q, want player.invalid", host)
+	}
+	if clientVersion != "20.10.3" || !strings.Contains(userAgent, "/20.10.3 ") {
+		t.Fatalf("client version = %q, User-Agent = %q, want 20.10.3 in both", clientVersion, userAgent)
+	}
+}
@@ -0,0 +1,98 @@
+// Package youtubeurl recognizes the URL forms a YouTube video or live stream
+// can be shared in and reduces them to the video ID and a canonical watch
+// URL, so that two links to the same video identify the same stream.
+package youtubeurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotYouTube is returned for URLs that are not a YouTube video link.
+var ErrNotYouTube = errors.New("not a YouTube video URL")
+
+// videoHosts are the youtube.com hosts that serve /watch and the path forms
+// below. youtu.be is handled separately.
+var videoHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// pathPrefixes are the first path segments that are followed by the video
+// ID, e.g. /live/ID.
+var pathPrefixes = map[string]bool{
+	"live":   true,
+	"shorts": true,
+	"embed":  true,
+	"v":      true,
+}
+
+// VideoID extracts the 11-character video ID from a YouTube URL. It accepts
+// watch, youtu.be, /live/, /shorts/, /embed/ and /v/ links on the www, m and
+// music hosts, with or without a scheme.
+func VideoID(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotYouTube, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", ErrNotYouTube
+	}
+
+	host := strings.ToLower(u.Hostname())
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	var id string
+	switch {
+	case host == "youtu.be" || host == "www.youtu.be":
+		if len(segments) == 1 {
+			id = segments[0]
+		}
+	case videoHosts[host]:
+		if len(segments) == 1 && segments[0] == "watch" {
+			id = u.Query().Get("v")
+		} else if len(segments) == 2 && pathPrefixes[segments[0]] {
+			id = segments[1]
+		}
+	}
+	if !IsVideoID(id) {
+		return "", ErrNotYouTube
+	}
+	return id, nil
+}
+
+// CanonicalURL returns the watch URL for videoID.
+func CanonicalURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+// Normalize returns the canonical watch URL and the video ID of rawURL.
+func Normalize(rawURL string) (canonical, videoID string, err error) {
+	videoID, err = VideoID(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	return CanonicalURL(videoID), videoID, nil
+}
+
+// IsVideoID reports whether id looks like an 11-character video ID.
+func IsVideoID(id string) bool {
+	if len(id) != 11 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package youtubeurl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	valid := []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://youtube.com/watch?v=dQw4w9WgXcQ",
+		"http://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=10s",
+		"https://www.youtube.com/watch?feature=share&v=dQw4w9WgXcQ",
+		"https://m.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=RDdQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ?si=abc",
+		"https://www.youtube.com/live/dQw4w9WgXcQ?feature=shared",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ",
+		"https://www.youtube.com/embed/dQw4w9WgXcQ",
+		"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+		"https://WWW.YouTube.com/watch?v=dQw4w9WgXcQ",
+		"youtu.be/dQw4w9WgXcQ",
+		"  www.youtube.com/watch?v=dQw4w9WgXcQ  ",
+	}
+	for _, rawURL := range valid {
+		canonical, videoID, err := Normalize(rawURL)
+		if err != nil {
+			t.Fatalf("Normalize(%q): %v", rawURL, err)
+		}
+		if videoID != "dQw4w9WgXcQ" || canonical != "https://www.youtube.com/watch?v=dQw4w9WgXcQ" {
+			t.Fatalf("Normalize(%q) = %q, %q", rawURL, canonical, videoID)
+		}
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	invalid := []string{
+		"",
+		"https://example.com/watch?v=dQw4w9WgXcQ",
+		"https://youtube.com.evil.example/watch?v=dQw4w9WgXcQ",
+		"ftp://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://www.youtube.com/watch",
+		"https://www.youtube.com/watch?v=short",
+		"https://www.youtube.com/watch?v=dQw4w9WgXc!",
+		"https://www.youtube.com/channel/UCuAXFkgsw1L7xaCfnd5JJOw",
+		"https://www.youtube.com/@channel/live",
+		"https://www.youtube.com/live/dQw4w9WgXcQ/extra",
+		"https://youtu.be/",
+	}
+	for _, rawURL := range invalid {
+		if _, _, err := Normalize(rawURL); !errors.Is(err, ErrNotYouTube) {
+			t.Fatalf("Normalize(%q) error = %v, want ErrNotYouTube", rawURL, err)
+		}
+	}
+}
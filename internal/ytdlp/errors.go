@@ -0,0 +1,107 @@
+package ytdlp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnavailableReason classifies why yt-dlp could not read a stream.
+type UnavailableReason string
+
+const (
+	ReasonPrivate       UnavailableReason = "private"
+	ReasonRemoved       UnavailableReason = "removed"
+	ReasonMembersOnly   UnavailableReason = "members_only"
+	ReasonAgeRestricted UnavailableReason = "age_restricted"
+	ReasonGeoBlocked    UnavailableReason = "geo_blocked"
+	ReasonRateLimited   UnavailableReason = "rate_limited"
+	ReasonBotCheck      UnavailableReason = "bot_check"
+)
+
+// Permanent reports whether retrying cannot help without changing the
+// video or the monitor's credentials. Rate limits and bot checks pass.
+func (r UnavailableReason) Permanent() bool {
+	switch r {
+	case ReasonRateLimited, ReasonBotCheck:
+		return false
+	default:
+		return true
+	}
+}
+
+// NeedsSession reports whether YouTube asks for a logged-in session that can
+// get past r: a channel membership, an age check or a bot check.
+func (r UnavailableReason) NeedsSession() bool {
+	switch r {
+	case ReasonMembersOnly, ReasonAgeRestricted, ReasonBotCheck:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnavailableError is returned when yt-dlp reports that the stream cannot be
+// read for a known reason.
+type UnavailableError struct {
+	Reason UnavailableReason
+	// Message is yt-dlp's error line.
+	Message string
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("stream unavailable (%s): %s", e.Reason, e.Message)
+}
+
+// unavailablePatterns maps yt-dlp error messages to reasons. They are
+// checked in order, as messages such as "Video unavailable" prefix more
+// specific ones.
+var unavailablePatterns = []struct {
+	reason    UnavailableReason
+	fragments []string
+}{
+	{ReasonBotCheck, []string{"not a bot"}},
+	{ReasonRateLimited, []string{"http error 429", "too many requests"}},
+	{ReasonMembersOnly, []string{"members-only", "members only", "channel's members"}},
+	{ReasonAgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"}},
+	{ReasonGeoBlocked, []string{"in your country", "geo restrict", "geo-restrict"}},
+	{ReasonPrivate, []string{"private video", "video is private"}},
+	{ReasonRemoved, []string{"has been removed", "no longer available", "account associated with this video has been terminated", "video unavailable", "does not exist"}},
+}
+
+// ClassifyMessage classifies an error message worded like yt-dlp's, such as
+// the player endpoint's playability reason, and returns nil when it names no
+// known reason.
+func ClassifyMessage(message string) *UnavailableError {
+	return classifyError(message)
+}
+
+// classifyError returns an *UnavailableError when yt-dlp's stderr names a
+// known reason, and nil otherwise.
+func classifyError(stderr string) *UnavailableError {
+	message := errorLine(stderr)
+	lower := strings.ToLower(stderr)
+	for _, pattern := range unavailablePatterns {
+		for _, fragment := range pattern.fragments {
+			if strings.Contains(lower, fragment) {
+				return &UnavailableError{Reason: pattern.reason, Message: message}
+			}
+		}
+	}
+	return nil
+}
+
+// errorLine returns yt-dlp's first "ERROR:" line, or the last non-empty line.
+func errorLine(stderr string) string {
+	var last string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "ERROR:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "ERROR:"))
+		}
+		last = line
+	}
+	return last
+}
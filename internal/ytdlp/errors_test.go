@@ -0,0 +1,44 @@
+package ytdlp
+
+import "testing"
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   UnavailableReason
+	}{
+		{"ERROR: [youtube] dQw4w9WgXcQ: Private video. Sign in if you've been granted access to this video", ReasonPrivate},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Video unavailable. This video has been removed by the uploader", ReasonRemoved},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Join this channel to get access to members-only content like this video, and other exclusive perks.", ReasonMembersOnly},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Sign in to confirm your age. This video may be inappropriate for some users.", ReasonAgeRestricted},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Video unavailable. The uploader has not made this video available in your country", ReasonGeoBlocked},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Unable to download API page: HTTP Error 429: Too Many Requests", ReasonRateLimited},
+		{"ERROR: [youtube] dQw4w9WgXcQ: Sign in to confirm you're not a bot. Use --cookies-from-browser or --cookies for the authentication.", ReasonBotCheck},
+	}
+	for _, tt := range tests {
+		got := classifyError("WARNING: something minor\n" + tt.stderr + "\n")
+		if got == nil || got.Reason != tt.want {
+			t.Fatalf("classifyError(%q) = %v, want %s", tt.stderr, got, tt.want)
+		}
+		if got.Message != tt.stderr[len("ERROR: "):] {
+			t.Fatalf("message = %q, want the ERROR line", got.Message)
+		}
+	}
+
+	if got := classifyError("ERROR: [youtube] dQw4w9WgXcQ: Unable to extract player response; please report this issue"); got != nil {
+		t.Fatalf("expected unknown failure to stay unclassified, got %v", got)
+	}
+}
+
+func TestUnavailableReasonPermanent(t *testing.T) {
+	for _, reason := range []UnavailableReason{ReasonPrivate, ReasonRemoved, ReasonMembersOnly, ReasonAgeRestricted, ReasonGeoBlocked} {
+		if !reason.Permanent() {
+			t.Fatalf("%s should be permanent", reason)
+		}
+	}
+	for _, reason := range []UnavailableReason{ReasonRateLimited, ReasonBotCheck} {
+		if reason.Permanent() {
+			t.Fatalf("%s should be transient", reason)
+		}
+	}
+}
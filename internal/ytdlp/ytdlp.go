@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -68,6 +70,11 @@ type Client struct {
 	streamlinkPath string
 	httpProxy      string
 	httpsProxy     string
+	// cookiesFile is a Netscape cookie file passed with --cookies; it is
+	// copied into cookiesTempDir for each run because yt-dlp writes the
+	// session back to it.
+	cookiesFile    string
+	cookiesTempDir string
 }
 
 // NewClient creates a new yt-dlp client.
@@ -86,7 +93,45 @@ func NewClient(ytdlpPath, streamlinkPath, httpProxy, httpsProxy string) *Client
 	}
 }
 
-// GetStreamInfo retrieves stream information using yt-dlp.
+// SetCookiesFile makes yt-dlp run with the logged-in session in the Netscape
+// cookie file at path. Each run gets a private copy in tempDir, which must be
+// writable; the copy is removed when the run ends.
+// The streamlink fallback of GetManifestURL is disabled, as it would run
+// without the session.
+func (c *Client) SetCookiesFile(path, tempDir string) {
+	c.cookiesFile = path
+	c.cookiesTempDir = tempDir
+}
+
+// cookiesArgs returns the --cookies arguments for one run and a cleanup
+// function that removes the copied cookie file.
+func (c *Client) cookiesArgs() ([]string, func(), error) {
+	if c.cookiesFile == "" {
+		return nil, func() {}, nil
+	}
+	src, err := os.Open(c.cookiesFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open cookies file: %w", err)
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp(c.cookiesTempDir, "cookies-*.txt")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create cookies copy: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(dst.Name()) }
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("copy cookies file: %w", err)
+	}
+	return []string{"--cookies", dst.Name()}, cleanup, nil
+}
+
+// GetStreamInfo retrieves stream information using yt-dlp. Failures with a
+// recognized cause wrap an *UnavailableError.
 func (c *Client) GetStreamInfo(ctx context.Context, streamURL string) (*StreamInfo, error) {
 	args := []string{
 		"--dump-json",
@@ -94,6 +139,13 @@ func (c *Client) GetStreamInfo(ctx context.Context, streamURL string) (*StreamIn
 		"--no-warnings",
 	}
 
+	cookieArgs, cleanup, err := c.cookiesArgs()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	args = append(args, cookieArgs...)
+
 	if c.httpProxy != "" {
 		args = append(args, "--proxy", c.httpProxy)
 	}
@@ -105,8 +157,11 @@ func (c *Client) GetStreamInfo(ctx context.Context, streamURL string) (*StreamIn
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
+		if unavailable := classifyError(stderr.String()); unavailable != nil {
+			return nil, fmt.Errorf("yt-dlp failed: %w", unavailable)
+		}
 		return nil, fmt.Errorf("yt-dlp failed: %w (stderr: %s)", err, stderr.String())
 	}
 
@@ -123,7 +178,10 @@ func (c *Client) GetStreamInfo(ctx context.Context, streamURL string) (*StreamIn
 	return &info, nil
 }
 
-// GetManifestURL retrieves the manifest URL for a live stream.
+// GetManifestURL retrieves the manifest URL for a live stream, falling back
+// to streamlink when yt-dlp fails for an unrecognized reason. Failures with
+// a recognized cause wrap an *UnavailableError. streamlink cannot use the
+// session, so there is no fallback when a cookies file is set.
 func (c *Client) GetManifestURL(ctx context.Context, streamURL string) (string, error) {
 	args := []string{
 		"--get-url",
@@ -133,6 +191,13 @@ func (c *Client) GetManifestURL(ctx context.Context, streamURL string) (string,
 		"--quiet",
 	}
 
+	cookieArgs, cleanup, err := c.cookiesArgs()
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	args = append(args, cookieArgs...)
+
 	if c.httpProxy != "" {
 		args = append(args, "--proxy", c.httpProxy)
 	}
@@ -144,14 +209,24 @@ func (c *Client) GetManifestURL(ctx context.Context, streamURL string) (string,
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
+		if unavailable := classifyError(stderr.String()); unavailable != nil {
+			// streamlink would be turned away for the same reason.
+			return "", fmt.Errorf("yt-dlp failed: %w", unavailable)
+		}
+		if c.cookiesFile != "" {
+			return "", fmt.Errorf("yt-dlp failed: %w (stderr: %s)", err, stderr.String())
+		}
 		// Try streamlink as fallback
 		return c.getManifestURLWithStreamlink(ctx, streamURL)
 	}
 
 	url := strings.TrimSpace(stdout.String())
 	if url == "" {
+		if c.cookiesFile != "" {
+			return "", fmt.Errorf("no manifest URL returned")
+		}
 		return c.getManifestURLWithStreamlink(ctx, streamURL)
 	}
 
@@ -0,0 +1,89 @@
+package ytdlp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCookiesArgsCopiesFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "cookies.txt")
+	if err := os.WriteFile(source, []byte(".youtube.com\tTRUE\t/\tTRUE\t0\tSID\tvalue\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := filepath.Join(dir, "tmp")
+	if err := os.Mkdir(tempDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("", "", "", "")
+	client.SetCookiesFile(source, tempDir)
+
+	args, cleanup, err := client.cookiesArgs()
+	if err != nil {
+		t.Fatalf("cookiesArgs: %v", err)
+	}
+	if len(args) != 2 || args[0] != "--cookies" || filepath.Dir(args[1]) != tempDir {
+		t.Fatalf("args = %v, want --cookies with a copy in the temp dir", args)
+	}
+	info, err := os.Stat(args[1])
+	if err != nil {
+		t.Fatalf("stat copy: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("copy mode = %v, want 0600", info.Mode().Perm())
+	}
+	cleanup()
+	if _, err := os.Stat(args[1]); !os.IsNotExist(err) {
+		t.Fatalf("copy not removed: %v", err)
+	}
+
+	args, _, err = NewClient("", "", "", "").cookiesArgs()
+	if err != nil || args != nil {
+		t.Fatalf("cookiesArgs without cookies = %v, %v, want none", args, err)
+	}
+}
+
+// writeScript writes an executable shell script named name into dir.
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGetManifestURLFallback(t *testing.T) {
+	dir := t.TempDir()
+	streamlink := writeScript(t, dir, "streamlink", "echo https://example.com/streamlink.m3u8\n")
+	failing := writeScript(t, dir, "yt-dlp-failing", "echo 'ERROR: unable to download webpage' >&2\nexit 1\n")
+	membersOnly := writeScript(t, dir, "yt-dlp-members", "echo 'ERROR: [youtube] abc: Join this channel to get access to members-only content' >&2\nexit 1\n")
+	cookiesFile := filepath.Join(dir, "cookies.txt")
+	if err := os.WriteFile(cookiesFile, []byte(".youtube.com\tTRUE\t/\tTRUE\t0\tSID\tvalue\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// An unrecognized failure falls back to streamlink.
+	url, err := NewClient(failing, streamlink, "", "").GetManifestURL(ctx, "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	if err != nil || url != "https://example.com/streamlink.m3u8" {
+		t.Fatalf("GetManifestURL = %q, %v, want the streamlink URL", url, err)
+	}
+
+	// A recognized cause is returned rather than hidden by the fallback.
+	_, err = NewClient(membersOnly, streamlink, "", "").GetManifestURL(ctx, "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || unavailable.Reason != ReasonMembersOnly {
+		t.Fatalf("GetManifestURL error = %v, want members_only", err)
+	}
+
+	// streamlink cannot use the session, so it is not tried with cookies.
+	client := NewClient(failing, streamlink, "", "")
+	client.SetCookiesFile(cookiesFile, dir)
+	if url, err := client.GetManifestURL(ctx, "https://www.youtube.com/watch?v=dQw4w9WgXcQ"); err == nil {
+		t.Fatalf("GetManifestURL with cookies = %q, want the yt-dlp error", url)
+	}
+}